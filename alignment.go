@@ -0,0 +1,270 @@
+package semanticmatcher
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Alignment scores selectable with WithTokenAlignment
+const (
+	AlignmentPrecision = "precision" // Keyword tokens matched to their most similar paragraph token
+	AlignmentRecall    = "recall"    // Paragraph tokens matched to their most similar keyword token
+	AlignmentF1        = "f1"        // Harmonic mean of precision and recall
+)
+
+// AlignmentScoring configures token alignment scoring (BERTScore-style over static vectors,
+// Zhang et al., "BERTScore: Evaluating Text Generation with BERT")
+type AlignmentScoring struct {
+	// Score selects the keyword score: AlignmentPrecision (default), AlignmentRecall or AlignmentF1,
+	// in any case; other scores fall back to AlignmentPrecision with a warning
+	Score string
+
+	// Weighted weighs each token by the call's token weighting, e.g. IDF with "tfidf" weighting;
+	// otherwise all tokens weigh the same
+	Weighted bool
+}
+
+// TokenAlignment explains the alignment score of a keyword
+// Similarities are cosine similarities of word vectors; OOV tokens are not aligned.
+type TokenAlignment struct {
+	Precision float64        `json:"precision"` // Weighted mean similarity of keyword tokens to their best paragraph token
+	Recall    float64        `json:"recall"`    // Weighted mean similarity of paragraph tokens to their best keyword token
+	F1        float64        `json:"f1"`        // 2PR / (P + R), 0 if P + R <= 0
+	Tokens    []AlignedToken `json:"tokens"`    // Each keyword token with its best paragraph token
+}
+
+// AlignedToken is a keyword token matched to its most similar paragraph token
+type AlignedToken struct {
+	Token      string  `json:"token"`
+	Match      string  `json:"match"`      // Most similar paragraph token; empty if the token is OOV
+	Similarity float64 `json:"similarity"` // Cosine similarity of Token and Match
+	Weight     float64 `json:"weight"`     // Token weight in the aggregation; 0 if the token is OOV
+}
+
+// WithTokenAlignment scores keywords in FindTopKeywordsWithOptions by matching each token to
+// its most similar token of the other text instead of comparing pooled text vectors, so a
+// short keyword matching one sentence of a long paragraph is not averaged out
+// Matches carry the TokenAlignment for explanation. Other methods ignore this option.
+func WithTokenAlignment(scoring AlignmentScoring) MatchOption {
+	scoring.Score = strings.ToLower(strings.TrimSpace(scoring.Score))
+	return func(o *matchOptions) {
+		o.alignment = &scoring
+	}
+}
+
+// validAlignmentScore reports whether score is an alignment score or empty
+func validAlignmentScore(score string) bool {
+	switch score {
+	case "", AlignmentPrecision, AlignmentRecall, AlignmentF1:
+		return true
+	default:
+		return false
+	}
+}
+
+// alignedText is a text as the unit vectors and weights of its in-vocabulary tokens
+type alignedText struct {
+	tokens  []string
+	vectors [][]float32
+	weights []float64
+	oov     int
+}
+
+// alignedText looks up the tokens of a text and weighs them with weighting if weighted
+func (sm *semanticMatcher) alignedText(tokens []string, weighting TokenWeighting, weighted bool) alignedText {
+	text := alignedText{
+		tokens:  make([]string, 0, len(tokens)),
+		vectors: make([][]float32, 0, len(tokens)),
+		weights: make([]float64, 0, len(tokens)),
+	}
+
+	rankedWords := 0
+	if ranked, ok := sm.model.(layerModel); ok && weighted && weighting != nil {
+		rankedWords = ranked.rankedWords()
+	}
+
+	for _, token := range tokens {
+		vector, exists := sm.model.GetVector(token)
+		norm := VectorNorm(vector)
+		if !exists || norm == 0 {
+			text.oov++
+			continue
+		}
+
+		weight := 1.0
+		if weighted && weighting != nil {
+			rank, _ := sm.model.WordRank(token)
+			weight = weighting.Weight(token, rank, rankedWords)
+		}
+
+		unit := make([]float32, len(vector))
+		for i, val := range vector {
+			unit[i] = float32(float64(val) / norm)
+		}
+		text.tokens = append(text.tokens, token)
+		text.vectors = append(text.vectors, unit)
+		text.weights = append(text.weights, weight)
+	}
+	return text
+}
+
+// alignTokens matches the tokens of keyword and paragraph to their most similar tokens in the
+// other text; keywordTokens lists all keyword tokens, including OOV ones, for the explanation
+func alignTokens(keyword, paragraph alignedText, keywordTokens []string) TokenAlignment {
+	alignment := TokenAlignment{Tokens: make([]AlignedToken, 0, len(keywordTokens))}
+
+	// Best paragraph similarity per keyword token, and best keyword similarity per paragraph token
+	paragraphBest := make([]float64, len(paragraph.tokens))
+	for j := range paragraphBest {
+		paragraphBest[j] = math.Inf(-1)
+	}
+
+	aligned := make(map[string]AlignedToken, len(keyword.tokens))
+	var precision, precisionWeight float64
+	for i, vector := range keyword.vectors {
+		best, bestIdx := math.Inf(-1), -1
+		for j, candidate := range paragraph.vectors {
+			if len(candidate) != len(vector) {
+				continue
+			}
+			similarity := math.Max(-1, math.Min(1, float64(dotFloat32(vector, candidate))))
+			if similarity > best {
+				best, bestIdx = similarity, j
+			}
+			paragraphBest[j] = math.Max(paragraphBest[j], similarity)
+		}
+		if bestIdx < 0 {
+			continue
+		}
+
+		precision += keyword.weights[i] * best
+		precisionWeight += keyword.weights[i]
+		aligned[keyword.tokens[i]] = AlignedToken{
+			Token:      keyword.tokens[i],
+			Match:      paragraph.tokens[bestIdx],
+			Similarity: best,
+			Weight:     keyword.weights[i],
+		}
+	}
+
+	var recall, recallWeight float64
+	for j, best := range paragraphBest {
+		if math.IsInf(best, -1) {
+			continue
+		}
+		recall += paragraph.weights[j] * best
+		recallWeight += paragraph.weights[j]
+	}
+
+	if precisionWeight > 0 {
+		alignment.Precision = precision / precisionWeight
+	}
+	if recallWeight > 0 {
+		alignment.Recall = recall / recallWeight
+	}
+	if sum := alignment.Precision + alignment.Recall; sum > 0 {
+		alignment.F1 = 2 * alignment.Precision * alignment.Recall / sum
+	}
+
+	for _, token := range keywordTokens {
+		if match, exists := aligned[token]; exists {
+			alignment.Tokens = append(alignment.Tokens, match)
+		} else {
+			alignment.Tokens = append(alignment.Tokens, AlignedToken{Token: token})
+		}
+	}
+	return alignment
+}
+
+// score returns the alignment score selected by scoring
+func (a TokenAlignment) score(scoring AlignmentScoring) float64 {
+	switch scoring.Score {
+	case AlignmentRecall:
+		return a.Recall
+	case AlignmentF1:
+		return a.F1
+	default:
+		return a.Precision
+	}
+}
+
+// findTopKeywordsByAlignment ranks keywords by token alignment with the paragraph
+// Once ctx is done, it ranks only the keywords aligned so far and returns ctx.Err().
+//
+//nolint:funlen
+func (sm *semanticMatcher) findTopKeywordsByAlignment(
+	ctx context.Context,
+	paragraphTokens []string,
+	keywords []string,
+	k int,
+	options matchOptions,
+	startTime time.Time,
+) ([]KeywordMatch, error) {
+	scoring := *options.alignment
+	paragraph := sm.alignedText(paragraphTokens, options.weighting, scoring.Weighted)
+	if len(paragraph.tokens) == 0 {
+		sm.updateStats(time.Since(startTime), len(paragraphTokens), paragraph.oov)
+		sm.logger.Warnf("All paragraph words are OOV, token_count: %d", len(paragraphTokens))
+		return make([]KeywordMatch, 0), nil
+	}
+
+	// Alignment scores average token cosines, on another scale than calibrated scores
+	mapping := options.scoreMapping(CosineMetric{}).withoutCalibration()
+	aligned := make([]KeywordMatch, len(keywords))
+	processed := make([]bool, len(keywords))
+	canceled := sm.pool.runContext(ctx, len(keywords), keywordBlockSize, func(start, end int) {
+		for i := start; i < end; i++ {
+			tokens := sm.processor.Preprocess(keywords[i])
+			keyword := sm.alignedText(tokens, options.weighting, scoring.Weighted)
+			alignment := alignTokens(keyword, paragraph, tokens)
+			aligned[i] = KeywordMatch{
+				Keyword:   keywords[i],
+				Score:     mapping.score(alignment.score(scoring), "", ""),
+				WordCount: len(tokens),
+				OOVCount:  keyword.oov,
+				Alignment: &alignment,
+			}
+			processed[i] = true
+		}
+	})
+
+	matches := make([]KeywordMatch, 0, len(keywords))
+	for i, match := range aligned {
+		if processed[i] {
+			matches = append(matches, match)
+		}
+	}
+
+	totalTokens, totalOOV := len(paragraphTokens), paragraph.oov
+	for _, match := range matches {
+		totalTokens += match.WordCount
+		totalOOV += match.OOVCount
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if k > 0 && k < len(matches) {
+		matches = matches[:k]
+	}
+
+	totalDuration := time.Since(startTime)
+	if canceled != nil {
+		sm.updateCanceledStats(totalDuration)
+		sm.logger.Warnf("FindTopKeywords canceled, scoring: token_alignment, total_duration_ms: %d, "+
+			"keywords_total: %d, results_returned: %d, error: %v",
+			totalDuration.Milliseconds(), len(keywords), len(matches), canceled)
+		return matches, canceled
+	}
+
+	sm.updateStats(totalDuration, totalTokens, totalOOV)
+	sm.logger.Infof("FindTopKeywords completed, scoring: token_alignment, score: %s, weighted: %v, "+
+		"total_duration_ms: %d, keywords_processed: %d, results_returned: %d, total_tokens: %d, total_oov: %d",
+		scoring.Score, scoring.Weighted, totalDuration.Milliseconds(), len(keywords), len(matches),
+		totalTokens, totalOOV)
+
+	return matches, nil
+}
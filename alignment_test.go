@@ -0,0 +1,109 @@
+package semanticmatcher
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAlignmentTestMatcher() SemanticMatcher {
+	model := NewVectorModel(3).(*vectorModel) //nolint:errcheck,forcetypeassert
+	model.AddVector("market", []float32{1, 0, 0})
+	model.AddVector("report", []float32{0, 1, 0})
+	model.AddVector("weather", []float32{0, 0, 1})
+	model.AddVector("stocks", []float32{0.9, 0.1, 0})
+	model.AddVector("rain", []float32{0, 0.1, 0.9})
+	return NewSemanticMatcher(NewTextProcessor(), model, NewSimilarityCalculator())
+}
+
+func TestWithTokenAlignment_Scores(t *testing.T) {
+	matcher := newAlignmentTestMatcher()
+
+	for _, tc := range []struct {
+		score    string
+		expected float64
+	}{
+		// "market" matches "market" exactly; of "market report", only "market" is matched by the keyword
+		{AlignmentPrecision, 1.0},
+		{AlignmentRecall, 0.5},
+		{AlignmentF1, 2.0 / 3.0},
+		{"", 1.0},
+		{" F1", 2.0 / 3.0},
+		{"Recall", 0.5},
+	} {
+		matches := matcher.FindTopKeywordsWithOptions("market report", []string{"market"}, 0,
+			WithTokenAlignment(AlignmentScoring{Score: tc.score}))
+		require.Len(t, matches, 1, tc.score)
+		assert.InDelta(t, tc.expected, matches[0].Score, 1e-6, tc.score)
+
+		alignment := matches[0].Alignment
+		require.NotNil(t, alignment, tc.score)
+		assert.InDelta(t, 1.0, alignment.Precision, 1e-6)
+		assert.InDelta(t, 0.5, alignment.Recall, 1e-6)
+		assert.InDelta(t, 2.0/3.0, alignment.F1, 1e-6)
+	}
+}
+
+func TestWithTokenAlignment_UnknownScore(t *testing.T) {
+	model := newAlignmentTestMatcher().(*semanticMatcher).model //nolint:forcetypeassert
+	logger := &mockLogger{messages: make([]string, 0)}
+	matcher := NewSemanticMatcherWithLogger(NewTextProcessor(), model, NewSimilarityCalculator(), logger, 0.5)
+
+	// Unknown scores fall back to precision and are logged
+	matches := matcher.FindTopKeywordsWithOptions("market report", []string{"market"}, 0,
+		WithTokenAlignment(AlignmentScoring{Score: "f-measure"}))
+	require.Len(t, matches, 1)
+	assert.InDelta(t, 1.0, matches[0].Score, 1e-6)
+	assert.True(t, slices.ContainsFunc(logger.messages, func(message string) bool {
+		return strings.Contains(message, "Unknown alignment score")
+	}))
+}
+
+func TestWithTokenAlignment_LongParagraph(t *testing.T) {
+	matcher := newAlignmentTestMatcher()
+	paragraph := "weather report weather report rain report weather market"
+	keywords := []string{"stocks", "weather"}
+
+	// Mean pooling averages the single "market" out of the paragraph
+	pooled := matcher.FindTopKeywords(paragraph, keywords, 0)
+	require.Len(t, pooled, 2)
+	assert.Equal(t, "weather", pooled[0].Keyword)
+	assert.Nil(t, pooled[0].Alignment)
+
+	aligned := matcher.FindTopKeywordsWithOptions(paragraph, []string{"stocks qqq"}, 0,
+		WithTokenAlignment(AlignmentScoring{}))
+	require.Len(t, aligned, 1)
+	assert.Greater(t, aligned[0].Score, pooled[1].Score)
+	assert.Equal(t, 1, aligned[0].OOVCount)
+
+	require.NotNil(t, aligned[0].Alignment)
+	assert.Equal(t, []AlignedToken{
+		{Token: "stocks", Match: "market", Similarity: aligned[0].Score, Weight: 1},
+		{Token: "qqq"},
+	}, aligned[0].Alignment.Tokens)
+}
+
+func TestWithTokenAlignment_Weighted(t *testing.T) {
+	matcher := newAlignmentTestMatcher()
+	idf := FitIDFModel(NewTextProcessor(), slices.Values(idfTestCorpus))
+
+	plain := matcher.FindTopKeywordsWithOptions("weather report", []string{"weather market"}, 0,
+		WithTokenAlignment(AlignmentScoring{}))
+	weighted := matcher.FindTopKeywordsWithOptions("weather report", []string{"weather market"}, 0,
+		WithTokenWeighting(idf), WithTokenAlignment(AlignmentScoring{Weighted: true}))
+	require.Len(t, plain, 1)
+	require.Len(t, weighted, 1)
+
+	// Only the rare "weather" is matched, so weighting by IDF raises the precision
+	assert.InDelta(t, 0.5, plain[0].Score, 1e-6)
+	assert.Greater(t, weighted[0].Score, plain[0].Score)
+	assert.InDelta(t, idf.IDF("weather"), weighted[0].Alignment.Tokens[0].Weight, 1e-9)
+
+	// Weighting is only applied when requested
+	unweighted := matcher.FindTopKeywordsWithOptions("weather report", []string{"weather market"}, 0,
+		WithTokenWeighting(idf), WithTokenAlignment(AlignmentScoring{}))
+	assert.InDelta(t, plain[0].Score, unweighted[0].Score, 1e-9)
+}
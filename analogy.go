@@ -0,0 +1,188 @@
+package semanticmatcher
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+)
+
+// AnalogyMethod selects the scoring function used for word analogy queries
+type AnalogyMethod int
+
+const (
+	// Analogy3CosAdd scores candidates by cos(x, b - a + c) (Mikolov et al.)
+	Analogy3CosAdd AnalogyMethod = iota
+
+	// Analogy3CosMul scores candidates by cos(x, b) * cos(x, c) / (cos(x, a) + ε)
+	// with cosines shifted to [0, 1] (Levy & Goldberg)
+	Analogy3CosMul
+)
+
+// analogyEpsilon prevents division by zero in 3CosMul
+const analogyEpsilon = 0.001
+
+// String returns the human-readable name of the analogy method
+func (m AnalogyMethod) String() string {
+	switch m {
+	case Analogy3CosAdd:
+		return "3CosAdd"
+	case Analogy3CosMul:
+		return "3CosMul"
+	default:
+		return fmt.Sprintf("AnalogyMethod(%d)", int(m))
+	}
+}
+
+// WordScore represents a vocabulary word with its score for a query
+type WordScore struct {
+	Word  string  `json:"word"`
+	Score float64 `json:"score"`
+}
+
+// Analogy answers "a is to b as c is to ?" by searching the vocabulary for the k words
+// closest to b - a + c. The input words are excluded from the results.
+// Only exact vocabulary entries are used; OOV inputs return ErrWordNotFound.
+// Lookups made here are not counted in the lookup statistics.
+func (vm *vectorModel) Analogy(a, b, c string, k int, method AnalogyMethod) ([]WordScore, error) {
+	if err := validateAnalogyQuery(a, b, c, k, method); err != nil {
+		return nil, err
+	}
+
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+
+	a, b, c = vm.normalizer.Normalize(a), vm.normalizer.Normalize(b), vm.normalizer.Normalize(c)
+	return scanAnalogy(a, b, c, k, method, vm.lookupExact, vm.rangeVectorsLocked)
+}
+
+// validateAnalogyQuery checks the arguments of an Analogy call
+func validateAnalogyQuery(a, b, c string, k int, method AnalogyMethod) error {
+	if a == "" || b == "" || c == "" || k <= 0 {
+		return ErrEmptyInput
+	}
+	if method != Analogy3CosAdd && method != Analogy3CosMul {
+		return fmt.Errorf("%w: unknown analogy method %d", ErrInvalidConfiguration, int(method))
+	}
+	return nil
+}
+
+// scanAnalogy scores every vocabulary vector visited by rangeVectors against the normalized
+// input words a, b and c, whose vectors come from lookup
+func scanAnalogy(
+	a, b, c string,
+	k int,
+	method AnalogyMethod,
+	lookup VocabularyLookup,
+	rangeVectors func(yield func(word string, vector []float32) bool),
+) ([]WordScore, error) {
+	inputs := make([][]float32, 3)
+	for i, word := range []string{a, b, c} {
+		vector, exists := lookup(word)
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrWordNotFound, word)
+		}
+		inputs[i] = NormalizeVector(vector)
+	}
+	va, vb, vc := inputs[0], inputs[1], inputs[2]
+
+	// 3CosAdd compares against a single target vector, so compute it once
+	var target []float32
+	if method == Analogy3CosAdd {
+		target = NormalizeVector(AddVectors(SubtractVectors(vb, va), vc))
+	}
+
+	collector := newTopKCollector(k)
+	rangeVectors(func(word string, vector []float32) bool {
+		if word == a || word == b || word == c {
+			return true
+		}
+
+		norm := VectorNorm(vector)
+		if norm == 0.0 {
+			return true
+		}
+
+		var score float64
+		if method == Analogy3CosAdd {
+			score = DotProduct(vector, target) / norm
+		} else {
+			cosA := (DotProduct(vector, va)/norm + 1) / 2
+			cosB := (DotProduct(vector, vb)/norm + 1) / 2
+			cosC := (DotProduct(vector, vc)/norm + 1) / 2
+			score = cosB * cosC / (cosA + analogyEpsilon)
+		}
+
+		collector.push(word, score)
+		return true
+	})
+
+	return collector.results(), nil
+}
+
+// topKCollector keeps the k highest-scoring words seen so far using a min-heap
+type topKCollector struct {
+	k     int
+	items wordScoreHeap
+}
+
+// newTopKCollector creates a collector that retains at most k results
+func newTopKCollector(k int) *topKCollector {
+	return &topKCollector{
+		k:     k,
+		items: make(wordScoreHeap, 0, k),
+	}
+}
+
+// push offers a candidate to the collector
+func (c *topKCollector) push(word string, score float64) {
+	if len(c.items) < c.k {
+		heap.Push(&c.items, WordScore{Word: word, Score: score})
+		return
+	}
+	candidate := WordScore{Word: word, Score: score}
+	if c.items.less(c.items[0], candidate) {
+		c.items[0] = candidate
+		heap.Fix(&c.items, 0)
+	}
+}
+
+// results returns the collected words sorted by score in descending order
+// Ties are broken by word to keep the output deterministic
+func (c *topKCollector) results() []WordScore {
+	results := make([]WordScore, len(c.items))
+	copy(results, c.items)
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Word < results[j].Word
+	})
+	return results
+}
+
+// wordScoreHeap is a min-heap of WordScore ordered by score, then by reverse word order
+type wordScoreHeap []WordScore
+
+func (h wordScoreHeap) Len() int { return len(h) }
+
+func (h wordScoreHeap) Less(i, j int) bool { return h.less(h[i], h[j]) }
+
+// less reports whether x ranks below y
+func (wordScoreHeap) less(x, y WordScore) bool {
+	if x.Score != y.Score {
+		return x.Score < y.Score
+	}
+	return x.Word > y.Word
+}
+
+func (h wordScoreHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *wordScoreHeap) Push(x any) { *h = append(*h, x.(WordScore)) } //nolint:errcheck,forcetypeassert
+
+func (h *wordScoreHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
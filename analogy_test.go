@@ -0,0 +1,77 @@
+package semanticmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAnalogyTestModel builds a small model where the royal/gender directions are separable:
+// dimension 0 encodes royalty, dimension 1 encodes gender, dimension 2 is noise
+func newAnalogyTestModel() *vectorModel {
+	vm := NewVectorModel(3).(*vectorModel)
+	vm.AddVector("男人", []float32{0.1, 1.0, 0.1})
+	vm.AddVector("woman", []float32{0.1, -1.0, 0.1})
+	vm.AddVector("国王", []float32{1.0, 1.0, 0.1})
+	vm.AddVector("queen", []float32{1.0, -1.0, 0.1})
+	vm.AddVector("apple", []float32{-0.2, 0.0, 1.0})
+	vm.AddVector("prince", []float32{0.8, 0.9, 0.3})
+	return vm
+}
+
+func TestVectorModel_Analogy(t *testing.T) {
+	vm := newAnalogyTestModel()
+
+	for _, method := range []AnalogyMethod{Analogy3CosAdd, Analogy3CosMul} {
+		t.Run(method.String(), func(t *testing.T) {
+			// 男人 is to 国王 as woman is to ?
+			results, err := vm.Analogy("男人", "国王", "woman", 2, method)
+			require.NoError(t, err)
+			require.Len(t, results, 2)
+
+			assert.Equal(t, "queen", results[0].Word)
+			assert.GreaterOrEqual(t, results[0].Score, results[1].Score)
+
+			// Input words are never returned
+			for _, result := range results {
+				assert.NotContains(t, []string{"男人", "国王", "woman"}, result.Word)
+			}
+		})
+	}
+}
+
+func TestVectorModel_AnalogyLimitsResults(t *testing.T) {
+	vm := newAnalogyTestModel()
+
+	results, err := vm.Analogy("男人", "国王", "woman", 100, Analogy3CosAdd)
+	require.NoError(t, err)
+	assert.Len(t, results, 3) // vocabulary minus the three inputs
+}
+
+func TestVectorModel_AnalogyErrors(t *testing.T) {
+	vm := newAnalogyTestModel()
+
+	_, err := vm.Analogy("男人", "国王", "missing", 1, Analogy3CosAdd)
+	require.ErrorIs(t, err, ErrWordNotFound)
+
+	_, err = vm.Analogy("", "国王", "woman", 1, Analogy3CosAdd)
+	require.ErrorIs(t, err, ErrEmptyInput)
+
+	_, err = vm.Analogy("男人", "国王", "woman", 0, Analogy3CosAdd)
+	require.ErrorIs(t, err, ErrEmptyInput)
+
+	_, err = vm.Analogy("男人", "国王", "woman", 1, AnalogyMethod(42))
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+}
+
+func TestVectorModel_AnalogyDoesNotAffectStats(t *testing.T) {
+	vm := newAnalogyTestModel()
+
+	_, err := vm.Analogy("男人", "国王", "woman", 1, Analogy3CosMul)
+	require.NoError(t, err)
+
+	totalLookups, oovLookups, _, _, _, _ := vm.GetLookupStats()
+	assert.Equal(t, int64(0), totalLookups)
+	assert.Equal(t, int64(0), oovLookups)
+}
@@ -1,7 +1,9 @@
 package semanticmatcher
 
 import (
+	"context"
 	"io"
+	"iter"
 	"time"
 )
 
@@ -13,6 +15,14 @@ type TextProcessor interface {
 
 	// PreprocessBatch processes multiple texts efficiently
 	PreprocessBatch(texts []string) [][]string
+
+	// SetNormalizer sets the Unicode normalization applied to text before tokenization
+	// Use the same normalizer as the vector model so tokens match vocabulary keys
+	SetNormalizer(normalizer *Normalizer)
+
+	// SetChineseConverter sets the Traditional/Simplified conversion applied to text after
+	// normalization; nil disables conversion
+	SetChineseConverter(converter *ChineseConverter)
 }
 
 // VectorModel provides in-memory storage and retrieval of word vectors
@@ -23,6 +33,26 @@ type VectorModel interface {
 	// GetAverageVector computes mean pooling for multiple words
 	GetAverageVector(words []string) ([]float32, bool)
 
+	// GetPooledVector combines the vectors of multiple words with the given pooling strategy
+	GetPooledVector(words []string, pooling Pooling) ([]float32, bool)
+
+	// GetWeightedPooledVector is GetPooledVector with each word's vector scaled by its weight,
+	// e.g. SIFWeighting; a nil weighting weighs all words the same
+	GetWeightedPooledVector(words []string, pooling Pooling, weighting TokenWeighting) ([]float32, bool)
+
+	// WordRank returns the 1-based frequency rank of a word, i.e. its position in the vector
+	// file it was loaded from; false if the word has no recorded rank
+	WordRank(word string) (int, bool)
+
+	// Words returns an iterator over the vocabulary, ranked words first in rank order
+	Words() iter.Seq[string]
+
+	// All returns an iterator over word-vector pairs in the same order as Words; vectors are copies
+	All() iter.Seq2[string, []float32]
+
+	// WriteVec writes the model in .vec text format, readable by EmbeddingLoader.LoadFromReader
+	WriteVec(w io.Writer) (int64, error)
+
 	// Dimension returns the vector dimension
 	Dimension() int
 
@@ -41,11 +71,32 @@ type VectorModel interface {
 	// GetLookupStats returns detailed lookup statistics (total, oov, hit, fallback attempts, successes, failures)
 	GetLookupStats() (totalLookups, oovLookups, hitLookups, fallbackAttempts, fallbackSuccesses, fallbackFailures int64)
 
-	// GetFallbackSuccessRate returns the success rate of character-level fallback operations (0.0 to 1.0)
+	// GetFallbackSuccessRate returns the success rate of fallback operations (0.0 to 1.0)
 	GetFallbackSuccessRate() float64
 
+	// GetFallbackStrategyStats returns attempt and success counters per fallback strategy name
+	GetFallbackStrategyStats() map[string]FallbackStrategyStats
+
+	// GetFallbackCacheStats returns the size and hit/miss counters of the fallback result cache
+	GetFallbackCacheStats() FallbackCacheStats
+
+	// SetFallbackChain sets the ordered fallback strategies for a language ("zh", "en");
+	// an empty language sets the default chain and an empty chain disables fallback
+	SetFallbackChain(language string, chain ...FallbackStrategy)
+
+	// SetNormalizer sets the Unicode normalization applied to vocabulary keys and lookups,
+	// re-normalizing existing keys; nil disables normalization
+	SetNormalizer(normalizer *Normalizer)
+
 	// ResetStats resets all statistics counters
 	ResetStats()
+
+	// TopOOVWords returns the n most frequently looked-up OOV words (all tracked words if n <= 0)
+	TopOOVWords(n int) []OOVWordStat
+
+	// Analogy answers "a is to b as c is to ?" and returns the k best vocabulary words,
+	// excluding the input words
+	Analogy(a, b, c string, k int, method AnalogyMethod) ([]WordScore, error)
 }
 
 // SimilarityCalculator computes similarity scores between vectors
@@ -54,9 +105,21 @@ type SimilarityCalculator interface {
 	// Returns 0.0 for invalid inputs (empty, nil, mismatched dimensions, or zero vectors)
 	CosineSimilarity(v1, v2 []float32) float64
 
-	// BatchSimilarity computes similarities between one vector and many
+	// Similarity computes the similarity of two vectors with the calculator's metric
+	// Returns 0.0 for invalid inputs
+	Similarity(v1, v2 []float32) float64
+
+	// BatchSimilarity computes similarities between one vector and many with the calculator's metric
 	// Returns empty slice for invalid query, and 0.0 for invalid candidates
 	BatchSimilarity(query []float32, candidates [][]float32) []float64
+
+	// SimilarityMatrix computes the similarity of every row vector to every column vector with
+	// the calculator's metric, in parallel; 0.0 for invalid vectors
+	SimilarityMatrix(rows, columns [][]float32) [][]float64
+
+	// Metric returns the similarity metric, CosineMetric unless created with
+	// NewSimilarityCalculatorWithMetric
+	Metric() SimilarityMetric
 }
 
 // SemanticMatcher orchestrates the complete semantic matching pipeline
@@ -64,9 +127,50 @@ type SemanticMatcher interface {
 	// FindTopKeywords finds most similar keywords to paragraph
 	FindTopKeywords(paragraph string, keywords []string, k int) []KeywordMatch
 
+	// FindTopKeywordsWithOptions is FindTopKeywords with per-call overrides such as WithPooling
+	FindTopKeywordsWithOptions(paragraph string, keywords []string, k int, opts ...MatchOption) []KeywordMatch
+
+	// FindTopKeywordsContext is FindTopKeywordsWithOptions that stops between keyword batches once
+	// ctx is done, returning the best of the keywords scored so far with ctx.Err()
+	FindTopKeywordsContext(
+		ctx context.Context, paragraph string, keywords []string, k int, opts ...MatchOption,
+	) ([]KeywordMatch, error)
+
 	// ComputeSimilarity computes similarity between two texts
 	ComputeSimilarity(text1, text2 string) float64
 
+	// ComputeSimilarityWithOptions is ComputeSimilarity with per-call overrides such as WithPooling
+	ComputeSimilarityWithOptions(text1, text2 string, opts ...MatchOption) float64
+
+	// ComputeSimilarityContext is ComputeSimilarityWithOptions that returns ctx.Err() if ctx is done
+	ComputeSimilarityContext(ctx context.Context, text1, text2 string, opts ...MatchOption) (float64, error)
+
+	// VectorizeText preprocesses a text and returns its pooled text vector,
+	// e.g. for adding texts to an HNSWIndex
+	VectorizeText(text string) ([]float32, bool)
+
+	// VectorizeTextWithOptions is VectorizeText with per-call overrides such as WithPooling
+	VectorizeTextWithOptions(text string, opts ...MatchOption) ([]float32, bool)
+
+	// SimilarityMatrix computes the similarity of every text in a to every text in b, e.g. all
+	// FAQ questions against all user queries, vectorizing each text only once
+	SimilarityMatrix(a, b []string) SimilarityMatrix
+
+	// SimilarityMatrixWithOptions is SimilarityMatrix with per-call overrides such as WithPooling
+	SimilarityMatrixWithOptions(a, b []string, opts ...MatchOption) SimilarityMatrix
+
+	// SimilarityMatrixContext is SimilarityMatrixWithOptions that returns an empty matrix and
+	// ctx.Err() once ctx is done
+	SimilarityMatrixContext(ctx context.Context, a, b []string, opts ...MatchOption) (SimilarityMatrix, error)
+
+	// FitCommonComponent fits the first principal component of the sample texts' vectors and
+	// removes it from text vectors computed with the same pooling and weighting (SIF)
+	FitCommonComponent(sampleTexts []string, opts ...MatchOption) error
+
+	// ReloadVectorLayer reloads the vector files of a configured layer (see Config.VectorLayers)
+	// and swaps them in without reloading the other layers
+	ReloadVectorLayer(name string) error
+
 	// GetStats returns performance and usage statistics
 	GetStats() MatcherStats
 }
@@ -77,16 +181,45 @@ type KeywordMatch struct {
 	Score     float64 `json:"score"`
 	WordCount int     `json:"word_count"` // Number of words in keyword
 	OOVCount  int     `json:"oov_count"`  // Number of OOV words
+
+	// Alignment explains the score with WithTokenAlignment; nil otherwise
+	Alignment *TokenAlignment `json:"alignment,omitempty"`
+
+	// Components holds the semantic and lexical parts of a hybrid score; nil otherwise
+	Components *ScoreComponents `json:"components,omitempty"`
+}
+
+// SimilarityMatrix holds the similarity of every row text to every column text
+type SimilarityMatrix struct {
+	Rows    []TextInfo  `json:"rows"`
+	Columns []TextInfo  `json:"columns"`
+	Scores  [][]float64 `json:"scores"` // Scores[i][j] is the similarity of Rows[i] and Columns[j]
+}
+
+// TextInfo describes a text of a SimilarityMatrix
+type TextInfo struct {
+	Text      string `json:"text"`
+	WordCount int    `json:"word_count"` // Number of words after preprocessing
+	OOVCount  int    `json:"oov_count"`  // Number of OOV words
+
+	// Scored is false if the text has no words, or all are OOV with a vector metric; its scores are 0
+	Scored bool `json:"scored"`
 }
 
 // MatcherStats provides performance and usage statistics
 type MatcherStats struct {
-	TotalRequests  int64         `json:"total_requests"`
-	AverageLatency time.Duration `json:"average_latency"`
-	OOVRate        float64       `json:"oov_rate"`
-	VectorHitRate  float64       `json:"vector_hit_rate"`
-	MemoryUsage    int64         `json:"memory_usage_bytes"`
-	LastUpdated    time.Time     `json:"last_updated"`
+	TotalRequests     int64 `json:"total_requests"`     // Completed and canceled requests
+	CompletedRequests int64 `json:"completed_requests"` // Requests that ran to completion
+	CanceledRequests  int64 `json:"canceled_requests"`  // Requests stopped by their context
+
+	AverageLatency time.Duration      `json:"average_latency"` // Of completed requests
+	OOVRate        float64            `json:"oov_rate"`
+	VectorHitRate  float64            `json:"vector_hit_rate"`
+	MemoryUsage    int64              `json:"memory_usage_bytes"`
+	LastUpdated    time.Time          `json:"last_updated"`
+	TopOOVWords    []OOVWordStat      `json:"top_oov_words,omitempty"` // Most frequent OOV words
+	Layers         []LayerStats       `json:"layers,omitempty"`        // Per-layer counters of a LayeredVectorModel
+	FallbackCache  FallbackCacheStats `json:"fallback_cache"`          // Fallback result cache usage
 }
 
 // EmbeddingLoader handles loading and parsing of pre-trained word vector files
@@ -104,6 +237,15 @@ type EmbeddingLoader interface {
 
 	// SetProgressCallback sets a callback for progress reporting during loading
 	SetProgressCallback(callback ProgressCallback)
+
+	// SetNormalizer sets the Unicode normalization applied to words as they are loaded
+	SetNormalizer(normalizer *Normalizer)
+
+	// SetProjection sets the dimensionality reduction applied to vectors as they are loaded
+	SetProjection(projection *Projection)
+
+	// SetVectorMapping sets the orthogonal mapping applied to the vectors of one file
+	SetVectorMapping(path string, mapping *OrthogonalMapping)
 }
 
 // ProgressCallback is called during vector loading to report progress
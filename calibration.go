@@ -0,0 +1,508 @@
+package semanticmatcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Calibration methods of CalibrationOptions
+const (
+	CalibrationIsotonic = "isotonic" // Monotone step function fitted by pool adjacent violators
+	CalibrationPlatt    = "platt"    // Logistic curve 1 / (1 + exp(A * score + B))
+)
+
+// DefaultMinCalibrationPairs is the number of labeled pairs a language pair needs for its own curve
+const DefaultMinCalibrationPairs = 50
+
+// CalibrationPair is one labeled example, a line of a calibration JSONL file:
+// {"text1": "...", "text2": "...", "relevant": true}
+type CalibrationPair struct {
+	Text1    string `json:"text1"`
+	Text2    string `json:"text2"`
+	Relevant bool   `json:"relevant"`
+}
+
+// CalibrationOptions controls FitCalibrator
+type CalibrationOptions struct {
+	// Method is CalibrationIsotonic or CalibrationPlatt; empty uses CalibrationIsotonic
+	Method string
+
+	// PerLanguagePair fits a separate curve for each language pair (see LanguagePair) with at
+	// least MinPairs examples of both classes; other pairs use the curve fitted on all examples
+	PerLanguagePair bool
+
+	// MinPairs is the number of examples a language pair needs; 0 uses DefaultMinCalibrationPairs
+	MinPairs int
+}
+
+// Calibrator maps raw similarity scores of one metric to probabilities of relevance, so a
+// threshold means the same for every model and language pair. It is immutable after fitting
+// or loading and safe for concurrent use.
+type Calibrator struct {
+	Method        string                       `json:"method"`
+	Metric        string                       `json:"metric,omitempty"` // Metric the scores were computed with
+	Global        *CalibrationCurve            `json:"global"`
+	LanguagePairs map[string]*CalibrationCurve `json:"language_pairs,omitempty"`
+}
+
+// CalibrationCurve is the fitted mapping of one calibrator, for all or one language pair
+type CalibrationCurve struct {
+	Pairs int `json:"pairs"` // Number of labeled pairs fitted on
+
+	// Platt scaling parameters
+	A float64 `json:"a,omitempty"`
+	B float64 `json:"b,omitempty"`
+
+	// Isotonic regression: probabilities at increasing scores, interpolated linearly in between
+	// and constant beyond the ends
+	Scores        []float64 `json:"scores,omitempty"`
+	Probabilities []float64 `json:"probabilities,omitempty"`
+}
+
+// WithCalibrator maps scores to probabilities with calibrator (ScoreModeCalibrated) instead of
+// the matcher's configured calibrator; nil disables calibration, so the default score mode clamps.
+// A calibrator fitted with another metric than the matcher's is ignored with a warning.
+// Token alignment scores are never calibrated.
+func WithCalibrator(calibrator *Calibrator) MatchOption {
+	return func(o *matchOptions) {
+		if calibrator != nil && calibrator.Metric != "" && calibrator.Metric != o.metric {
+			o.ignoredCalibrator = calibrator
+			return
+		}
+		o.calibrator = calibrator
+		if calibrator != nil {
+			o.scoreMode = ScoreModeCalibrated
+		}
+	}
+}
+
+// LanguagePair returns the calibration key of two texts, their languages in sorted order such
+// as "en-zh"; a text containing CJK characters counts as Chinese, any other as English
+func LanguagePair(text1, text2 string) string {
+	language1, language2 := textLanguage(text1), textLanguage(text2)
+	if language1 > language2 {
+		language1, language2 = language2, language1
+	}
+	return language1 + "-" + language2
+}
+
+// textLanguage returns the language used to select a calibration curve for text
+func textLanguage(text string) string {
+	if containsCJK(text) {
+		return LanguageChinese
+	}
+	return LanguageEnglish
+}
+
+// FitCalibrator scores the labeled pairs with matcher.ComputeSimilarityWithOptions in
+// ScoreModeClamped and fits a calibrator on the scores
+// Fit with the same metric, pooling and weighting the calibrator will be applied with.
+func FitCalibrator(matcher SemanticMatcher, pairs []CalibrationPair, options CalibrationOptions) (*Calibrator, error) {
+	scores := make([]float64, len(pairs))
+	for i, pair := range pairs {
+		scores[i] = matcher.ComputeSimilarityWithOptions(pair.Text1, pair.Text2,
+			WithCalibrator(nil), WithScoreMode(ScoreModeClamped))
+	}
+
+	calibrator, err := FitCalibratorFromScores(pairs, scores, options)
+	if err != nil {
+		return nil, err
+	}
+	if named, ok := matcher.(interface{ similarityMetricName() string }); ok {
+		calibrator.Metric = named.similarityMetricName()
+	}
+	return calibrator, nil
+}
+
+// FitCalibratorFromScores fits a calibrator on precomputed raw scores of the labeled pairs
+// Both relevant and irrelevant pairs are needed.
+func FitCalibratorFromScores(
+	pairs []CalibrationPair,
+	scores []float64,
+	options CalibrationOptions,
+) (*Calibrator, error) {
+	if len(pairs) != len(scores) {
+		return nil, fmt.Errorf("%w: %d pairs but %d scores", ErrInvalidCalibrationData, len(pairs), len(scores))
+	}
+
+	method := options.Method
+	if method == "" {
+		method = CalibrationIsotonic
+	}
+	if method != CalibrationIsotonic && method != CalibrationPlatt {
+		return nil, fmt.Errorf("%w: unknown calibration method %q", ErrInvalidConfiguration, method)
+	}
+	minPairs := options.MinPairs
+	if minPairs <= 0 {
+		minPairs = DefaultMinCalibrationPairs
+	}
+
+	labels := make([]bool, len(pairs))
+	for i, pair := range pairs {
+		labels[i] = pair.Relevant
+	}
+	if !hasBothClasses(labels) {
+		return nil, fmt.Errorf("%w: need both relevant and irrelevant pairs", ErrInvalidCalibrationData)
+	}
+
+	calibrator := &Calibrator{
+		Method: method,
+		Global: fitCalibrationCurve(method, scores, labels),
+	}
+
+	if options.PerLanguagePair {
+		groups := make(map[string][]int)
+		for i, pair := range pairs {
+			key := LanguagePair(pair.Text1, pair.Text2)
+			groups[key] = append(groups[key], i)
+		}
+
+		for key, indexes := range groups {
+			groupScores, groupLabels := make([]float64, len(indexes)), make([]bool, len(indexes))
+			for j, idx := range indexes {
+				groupScores[j], groupLabels[j] = scores[idx], labels[idx]
+			}
+			if len(indexes) < minPairs || !hasBothClasses(groupLabels) {
+				continue
+			}
+
+			if calibrator.LanguagePairs == nil {
+				calibrator.LanguagePairs = make(map[string]*CalibrationCurve)
+			}
+			calibrator.LanguagePairs[key] = fitCalibrationCurve(method, groupScores, groupLabels)
+		}
+	}
+
+	return calibrator, nil
+}
+
+// hasBothClasses reports whether labels contain both true and false
+func hasBothClasses(labels []bool) bool {
+	return slices.Contains(labels, true) && slices.Contains(labels, false)
+}
+
+// fitCalibrationCurve fits one curve with the given method
+func fitCalibrationCurve(method string, scores []float64, labels []bool) *CalibrationCurve {
+	if method == CalibrationPlatt {
+		a, b := fitPlatt(scores, labels)
+		return &CalibrationCurve{Pairs: len(scores), A: a, B: b}
+	}
+
+	points, probabilities := fitIsotonic(scores, labels)
+	return &CalibrationCurve{Pairs: len(scores), Scores: points, Probabilities: probabilities}
+}
+
+// Calibrate returns the probability of relevance of a raw score, using the curve of the
+// language pair if one was fitted and the global curve otherwise
+func (c *Calibrator) Calibrate(score float64, languagePair string) float64 {
+	curve := c.Global
+	if pairCurve, exists := c.LanguagePairs[languagePair]; exists {
+		curve = pairCurve
+	}
+	return curve.probability(c.Method, score)
+}
+
+// CalibrateTexts is Calibrate with the language pair of two texts
+func (c *Calibrator) CalibrateTexts(score float64, text1, text2 string) float64 {
+	return c.Calibrate(score, LanguagePair(text1, text2))
+}
+
+// languagePairOf is LanguagePair with the language of the first text already known
+func languagePairOf(language, text string) string {
+	other := textLanguage(text)
+	if language > other {
+		language, other = other, language
+	}
+	return language + "-" + other
+}
+
+// probability evaluates the curve at score
+func (curve *CalibrationCurve) probability(method string, score float64) float64 {
+	if method == CalibrationPlatt {
+		return 1 / (1 + math.Exp(curve.A*score+curve.B))
+	}
+
+	points := curve.Scores
+	idx, found := slices.BinarySearch(points, score)
+	switch {
+	case found:
+		return curve.Probabilities[idx]
+	case idx == 0:
+		return curve.Probabilities[0]
+	case idx == len(points):
+		return curve.Probabilities[len(points)-1]
+	}
+
+	t := (score - points[idx-1]) / (points[idx] - points[idx-1])
+	return curve.Probabilities[idx-1] + t*(curve.Probabilities[idx]-curve.Probabilities[idx-1])
+}
+
+// fitIsotonic fits a non-decreasing step function of the labels over the scores with the pool
+// adjacent violators algorithm, returning each block's lowest and highest score with its mean
+func fitIsotonic(scores []float64, labels []bool) ([]float64, []float64) {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		switch {
+		case scores[a] < scores[b]:
+			return -1
+		case scores[a] > scores[b]:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	type block struct {
+		low, high  float64
+		sum, count float64
+	}
+	blocks := make([]block, 0, len(order))
+	for _, idx := range order {
+		label := 0.0
+		if labels[idx] {
+			label = 1.0
+		}
+
+		// Equal scores must map to one probability
+		if n := len(blocks); n > 0 && blocks[n-1].high == scores[idx] {
+			blocks[n-1].sum += label
+			blocks[n-1].count++
+		} else {
+			blocks = append(blocks, block{low: scores[idx], high: scores[idx], sum: label, count: 1})
+		}
+
+		// Pool with preceding blocks while their means violate the order
+		for n := len(blocks); n > 1 && blocks[n-2].sum/blocks[n-2].count >= blocks[n-1].sum/blocks[n-1].count; n-- {
+			blocks[n-2].high = blocks[n-1].high
+			blocks[n-2].sum += blocks[n-1].sum
+			blocks[n-2].count += blocks[n-1].count
+			blocks = blocks[:n-1]
+		}
+	}
+
+	points := make([]float64, 0, 2*len(blocks))
+	probabilities := make([]float64, 0, 2*len(blocks))
+	for _, b := range blocks {
+		mean := b.sum / b.count
+		points = append(points, b.low)
+		probabilities = append(probabilities, mean)
+		if b.high > b.low {
+			points = append(points, b.high)
+			probabilities = append(probabilities, mean)
+		}
+	}
+	return points, probabilities
+}
+
+// fitPlatt fits P(relevant | score) = 1 / (1 + exp(A * score + B)) by Newton's method with
+// backtracking on Platt's smoothed targets, following Lin, Lin and Weng (2007)
+//
+//nolint:funlen
+func fitPlatt(scores []float64, labels []bool) (float64, float64) {
+	const (
+		maxIterations = 100
+		minStep       = 1e-10
+		sigma         = 1e-12 // Keeps the Hessian positive definite
+		epsilon       = 1e-5
+	)
+
+	positives, negatives := 0.0, 0.0
+	for _, label := range labels {
+		if label {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+	high, low := (positives+1)/(positives+2), 1/(negatives+2)
+	targets := make([]float64, len(labels))
+	for i, label := range labels {
+		targets[i] = low
+		if label {
+			targets[i] = high
+		}
+	}
+
+	objective := func(a, b float64) float64 {
+		value := 0.0
+		for i, score := range scores {
+			fApB := score*a + b
+			if fApB >= 0 {
+				value += targets[i]*fApB + math.Log1p(math.Exp(-fApB))
+			} else {
+				value += (targets[i]-1)*fApB + math.Log1p(math.Exp(fApB))
+			}
+		}
+		return value
+	}
+
+	a, b := 0.0, math.Log((negatives+1)/(positives+1))
+	value := objective(a, b)
+
+	for range maxIterations {
+		h11, h22, h21, g1, g2 := sigma, sigma, 0.0, 0.0, 0.0
+		for i, score := range scores {
+			fApB := score*a + b
+			var p, q float64
+			if fApB >= 0 {
+				p = math.Exp(-fApB) / (1 + math.Exp(-fApB))
+				q = 1 / (1 + math.Exp(-fApB))
+			} else {
+				p = 1 / (1 + math.Exp(fApB))
+				q = math.Exp(fApB) / (1 + math.Exp(fApB))
+			}
+			d2 := p * q
+			h11 += score * score * d2
+			h22 += d2
+			h21 += score * d2
+			d1 := targets[i] - p
+			g1 += score * d1
+			g2 += d1
+		}
+		if math.Abs(g1) < epsilon && math.Abs(g2) < epsilon {
+			break
+		}
+
+		det := h11*h22 - h21*h21
+		dA := -(h22*g1 - h21*g2) / det
+		dB := -(-h21*g1 + h11*g2) / det
+		gd := g1*dA + g2*dB
+
+		step := 1.0
+		for ; step >= minStep; step /= 2 {
+			newA, newB := a+step*dA, b+step*dB
+			if newValue := objective(newA, newB); newValue < value+1e-4*step*gd {
+				a, b, value = newA, newB, newValue
+				break
+			}
+		}
+		if step < minStep {
+			break
+		}
+	}
+
+	return a, b
+}
+
+// ReadCalibrationPairs reads labeled pairs from JSONL, one CalibrationPair per line
+// Empty lines are skipped.
+func ReadCalibrationPairs(reader io.Reader) ([]CalibrationPair, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var pairs []CalibrationPair
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var pair CalibrationPair
+		if err := json.Unmarshal([]byte(line), &pair); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidCalibrationData, lineNumber, err)
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+// LoadCalibrationPairs reads labeled pairs from a JSONL file
+func LoadCalibrationPairs(path string) ([]CalibrationPair, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calibration pairs file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadCalibrationPairs(file)
+}
+
+// WriteTo writes the calibrator as indented JSON
+func (c *Calibrator) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// SaveCalibrator writes calibrator to path
+func SaveCalibrator(calibrator *Calibrator, path string) error {
+	file, err := os.Create(path) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to create calibrator file: %w", err)
+	}
+
+	if _, err := calibrator.WriteTo(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadCalibrator reads a calibrator written by SaveCalibrator
+func LoadCalibrator(path string) (*Calibrator, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calibrator file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadCalibrator(file)
+}
+
+// ReadCalibrator reads a calibrator in the format written by Calibrator.WriteTo
+func ReadCalibrator(reader io.Reader) (*Calibrator, error) {
+	var calibrator Calibrator
+	if err := json.NewDecoder(reader).Decode(&calibrator); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCalibratorFormat, err)
+	}
+
+	if calibrator.Method != CalibrationIsotonic && calibrator.Method != CalibrationPlatt {
+		return nil, fmt.Errorf("%w: unknown method %q", ErrInvalidCalibratorFormat, calibrator.Method)
+	}
+	if calibrator.Global == nil {
+		return nil, fmt.Errorf("%w: missing global curve", ErrInvalidCalibratorFormat)
+	}
+	for key, curve := range calibrator.LanguagePairs {
+		if curve == nil {
+			return nil, fmt.Errorf("%w: missing curve of language pair %q", ErrInvalidCalibratorFormat, key)
+		}
+	}
+
+	if calibrator.Method == CalibrationIsotonic {
+		for key, curve := range calibrator.LanguagePairs {
+			if !curve.validIsotonic() {
+				return nil, fmt.Errorf("%w: invalid curve of language pair %q", ErrInvalidCalibratorFormat, key)
+			}
+		}
+		if !calibrator.Global.validIsotonic() {
+			return nil, fmt.Errorf("%w: invalid global curve", ErrInvalidCalibratorFormat)
+		}
+	}
+
+	return &calibrator, nil
+}
+
+// validIsotonic reports whether the curve has matching, non-empty points in increasing order
+func (curve *CalibrationCurve) validIsotonic() bool {
+	return len(curve.Scores) > 0 && len(curve.Scores) == len(curve.Probabilities) &&
+		slices.IsSorted(curve.Scores)
+}
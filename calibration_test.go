@@ -0,0 +1,234 @@
+package semanticmatcher
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// calibrationTestPairs returns pairs whose relevance grows with their score
+func calibrationTestPairs() ([]CalibrationPair, []float64) {
+	var pairs []CalibrationPair
+	var scores []float64
+	for i := range 20 {
+		score := float64(i) / 20
+		pairs = append(pairs, CalibrationPair{Text1: "refund", Text2: "return", Relevant: i >= 12 || i == 8})
+		scores = append(scores, score)
+	}
+	return pairs, scores
+}
+
+func TestFitCalibratorFromScores_Isotonic(t *testing.T) {
+	pairs, scores := calibrationTestPairs()
+	calibrator, err := FitCalibratorFromScores(pairs, scores, CalibrationOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, CalibrationIsotonic, calibrator.Method)
+	assert.Equal(t, 20, calibrator.Global.Pairs)
+
+	// Monotone, and the violator at 0.4 is pooled with its neighbors
+	previous := -1.0
+	for score := 0.0; score <= 1.0; score += 0.05 {
+		probability := calibrator.Calibrate(score, "en-en")
+		assert.GreaterOrEqual(t, probability, previous)
+		previous = probability
+	}
+	assert.Zero(t, calibrator.Calibrate(0.1, "en-en"))
+	assert.InDelta(t, 1.0, calibrator.Calibrate(0.9, "en-en"), 1e-9)
+	assert.InDelta(t, 0.25, calibrator.Calibrate(0.45, "en-en"), 1e-9)
+
+	// Constant beyond the fitted scores
+	assert.Zero(t, calibrator.Calibrate(-1, "en-en"))
+	assert.InDelta(t, 1.0, calibrator.Calibrate(2, "en-en"), 1e-9)
+}
+
+func TestFitCalibratorFromScores_Platt(t *testing.T) {
+	pairs, scores := calibrationTestPairs()
+	calibrator, err := FitCalibratorFromScores(pairs, scores, CalibrationOptions{Method: CalibrationPlatt})
+	require.NoError(t, err)
+
+	assert.Less(t, calibrator.Global.A, 0.0)
+	low, middle, high := calibrator.Calibrate(0.1, ""), calibrator.Calibrate(0.55, ""), calibrator.Calibrate(0.9, "")
+	assert.Less(t, low, 0.2)
+	assert.InDelta(t, 0.5, middle, 0.15)
+	assert.Greater(t, high, 0.8)
+}
+
+func TestFitCalibratorFromScores_PerLanguagePair(t *testing.T) {
+	var pairs []CalibrationPair
+	var scores []float64
+	for i := range 10 {
+		score := float64(i) / 10
+		// zh-zh pairs are relevant from 0.7, zh-en pairs already from 0.4
+		pairs = append(pairs,
+			CalibrationPair{Text1: "退款", Text2: "退货", Relevant: score >= 0.7},
+			CalibrationPair{Text1: "退款", Text2: "refund", Relevant: score >= 0.4})
+		scores = append(scores, score, score)
+	}
+
+	calibrator, err := FitCalibratorFromScores(pairs, scores, CalibrationOptions{PerLanguagePair: true, MinPairs: 10})
+	require.NoError(t, err)
+	require.Len(t, calibrator.LanguagePairs, 2)
+	assert.Contains(t, calibrator.LanguagePairs, "en-zh")
+	assert.Contains(t, calibrator.LanguagePairs, "zh-zh")
+
+	assert.Zero(t, calibrator.CalibrateTexts(0.5, "退款", "退货"))
+	assert.InDelta(t, 1.0, calibrator.CalibrateTexts(0.5, "refund", "退款"), 1e-9)
+
+	// Pairs without their own curve use the global one
+	assert.InDelta(t, calibrator.Global.probability(CalibrationIsotonic, 0.5),
+		calibrator.CalibrateTexts(0.5, "refund", "return"), 1e-9)
+
+	// Too few examples for a pair curve
+	calibrator, err = FitCalibratorFromScores(pairs, scores, CalibrationOptions{PerLanguagePair: true})
+	require.NoError(t, err)
+	assert.Empty(t, calibrator.LanguagePairs)
+}
+
+func TestFitCalibratorFromScores_Errors(t *testing.T) {
+	pairs := []CalibrationPair{{Text1: "a", Text2: "b", Relevant: true}}
+	_, err := FitCalibratorFromScores(pairs, []float64{0.5}, CalibrationOptions{})
+	assert.ErrorIs(t, err, ErrInvalidCalibrationData)
+
+	_, err = FitCalibratorFromScores(pairs, nil, CalibrationOptions{})
+	assert.ErrorIs(t, err, ErrInvalidCalibrationData)
+
+	pairs, scores := calibrationTestPairs()
+	_, err = FitCalibratorFromScores(pairs, scores, CalibrationOptions{Method: "beta"})
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+}
+
+func TestLanguagePair(t *testing.T) {
+	assert.Equal(t, "en-zh", LanguagePair("退款", "refund"))
+	assert.Equal(t, "en-zh", LanguagePair("refund", "退款"))
+	assert.Equal(t, "zh-zh", LanguagePair("退款", "退货"))
+	assert.Equal(t, "en-en", LanguagePair("refund", ""))
+}
+
+func TestReadCalibrationPairs(t *testing.T) {
+	input := `{"text1": "退款", "text2": "refund", "relevant": true}
+
+{"text1": "退款", "text2": "weather", "relevant": false}
+`
+	pairs, err := ReadCalibrationPairs(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []CalibrationPair{
+		{Text1: "退款", Text2: "refund", Relevant: true},
+		{Text1: "退款", Text2: "weather"},
+	}, pairs)
+
+	_, err = ReadCalibrationPairs(strings.NewReader("{\"text1\": \"a\"}\nnot json\n"))
+	assert.ErrorIs(t, err, ErrInvalidCalibrationData)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestSaveLoadCalibrator(t *testing.T) {
+	pairs, scores := calibrationTestPairs()
+	for _, method := range []string{CalibrationIsotonic, CalibrationPlatt} {
+		calibrator, err := FitCalibratorFromScores(pairs, scores, CalibrationOptions{Method: method})
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "calibrator.json")
+		require.NoError(t, SaveCalibrator(calibrator, path))
+		loaded, err := LoadCalibrator(path)
+		require.NoError(t, err)
+		assert.Equal(t, calibrator, loaded)
+	}
+
+	for _, input := range []string{
+		"not json",
+		`{"method": "beta", "global": {}}`,
+		`{"method": "platt"}`,
+		`{"method": "isotonic", "global": {"scores": [0.5, 0.1], "probabilities": [0, 1]}}`,
+		`{"method": "isotonic", "global": {"scores": [0.1], "probabilities": [0]}, "language_pairs": {"en-zh": {}}}`,
+	} {
+		_, err := ReadCalibrator(bytes.NewBufferString(input))
+		assert.ErrorIs(t, err, ErrInvalidCalibratorFormat, input)
+	}
+}
+
+func TestSemanticMatcher_Calibration(t *testing.T) {
+	matcher := newHybridTestMatcher()
+
+	pairs := []CalibrationPair{
+		{Text1: "docker", Text2: "cluster", Relevant: true},
+		{Text1: "docker", Text2: "docker", Relevant: true},
+		{Text1: "docker", Text2: "report", Relevant: false},
+		{Text1: "kubernetes", Text2: "report", Relevant: false},
+	}
+	calibrator, err := FitCalibrator(matcher, pairs, CalibrationOptions{Method: CalibrationPlatt})
+	require.NoError(t, err)
+	assert.Equal(t, MetricCosine, calibrator.Metric)
+
+	raw := matcher.ComputeSimilarity("docker", "cluster")
+	calibrated := matcher.ComputeSimilarityWithOptions("docker", "cluster", WithCalibrator(calibrator))
+	assert.InDelta(t, calibrator.Calibrate(raw, "en-en"), calibrated, 1e-9)
+
+	matches := matcher.FindTopKeywordsWithOptions("docker", []string{"report", "cluster", "helm"}, 0,
+		WithCalibrator(calibrator))
+	require.Len(t, matches, 3)
+	assert.Equal(t, "cluster", matches[0].Keyword)
+	assert.InDelta(t, calibrated, matches[0].Score, 1e-6)
+	assert.Greater(t, matches[1].Score, 0.0)
+
+	// Keywords without vectors have no score to calibrate
+	assert.Equal(t, "helm", matches[2].Keyword)
+	assert.Zero(t, matches[2].Score)
+
+	// The configured calibrator applies by default and nil returns raw scores
+	matcher.(*semanticMatcher).calibrator = calibrator //nolint:forcetypeassert
+	assert.InDelta(t, calibrated, matcher.ComputeSimilarity("docker", "cluster"), 1e-9)
+	assert.InDelta(t, raw, matcher.ComputeSimilarityWithOptions("docker", "cluster", WithCalibrator(nil)), 1e-9)
+}
+
+func TestWithCalibrator_MetricMismatch(t *testing.T) {
+	pairs, scores := calibrationTestPairs()
+	calibrator, err := FitCalibratorFromScores(pairs, scores, CalibrationOptions{})
+	require.NoError(t, err)
+	calibrator.Metric = MetricCosine
+
+	model := newHybridTestMatcher().(*semanticMatcher).model //nolint:forcetypeassert
+	logger := &mockLogger{messages: make([]string, 0)}
+	matcher := NewSemanticMatcherWithLogger(NewTextProcessor(), model,
+		NewSimilarityCalculatorWithMetric(EuclideanMetric{}), logger, 0.5)
+
+	// A calibrator fitted with cosine scores is ignored for euclidean scores
+	clamped := matcher.ComputeSimilarityWithOptions("docker", "cluster", WithScoreMode(ScoreModeClamped))
+	assert.InDelta(t, clamped, matcher.ComputeSimilarityWithOptions("docker", "cluster",
+		WithCalibrator(calibrator)), 1e-9)
+	assert.True(t, slices.ContainsFunc(logger.messages, func(message string) bool {
+		return strings.Contains(message, "Ignoring calibrator")
+	}))
+
+	// A calibrator without metric is trusted
+	calibrator.Metric = ""
+	raw := matcher.ComputeSimilarityWithOptions("docker", "cluster", WithScoreMode(ScoreModeRaw))
+	assert.InDelta(t, calibrator.Calibrate(raw, "en-en"), matcher.ComputeSimilarityWithOptions("docker", "cluster",
+		WithCalibrator(calibrator)), 1e-9)
+}
+
+func TestLoadCalibratorFromConfig(t *testing.T) {
+	pairs, scores := calibrationTestPairs()
+	calibrator, err := FitCalibratorFromScores(pairs, scores, CalibrationOptions{})
+	require.NoError(t, err)
+	calibrator.Metric = MetricCosine
+
+	path := filepath.Join(t.TempDir(), "calibrator.json")
+	require.NoError(t, SaveCalibrator(calibrator, path))
+	logger := &mockLogger{messages: make([]string, 0)}
+
+	loaded, err := loadCalibratorFromConfig(&Config{CalibratorPath: path}, CosineMetric{}, logger)
+	require.NoError(t, err)
+	assert.Equal(t, calibrator, loaded)
+
+	_, err = loadCalibratorFromConfig(&Config{CalibratorPath: path}, EuclideanMetric{}, logger)
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	loaded, err = loadCalibratorFromConfig(&Config{}, CosineMetric{}, logger)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+}
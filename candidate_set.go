@@ -0,0 +1,63 @@
+package semanticmatcher
+
+// CandidateSet holds candidate vectors with their squared norms precomputed, for scoring many
+// queries against the same candidates, e.g. a fixed keyword list. Only the dot product is
+// computed per query and candidate. A CandidateSet is not safe for concurrent modification,
+// but Similarities can be called concurrently.
+type CandidateSet struct {
+	vectors [][]float32
+	norms   []float32 // Squared norm of each vector
+}
+
+// NewCandidateSet creates a candidate set of vectors
+// The vectors are referenced, not copied, and must not be modified afterwards.
+func NewCandidateSet(vectors [][]float32) *CandidateSet {
+	set := &CandidateSet{
+		vectors: make([][]float32, 0, len(vectors)),
+		norms:   make([]float32, 0, len(vectors)),
+	}
+	for _, vector := range vectors {
+		set.Add(vector)
+	}
+	return set
+}
+
+// Add appends a candidate vector and returns its index
+func (s *CandidateSet) Add(vector []float32) int {
+	s.vectors = append(s.vectors, vector)
+	s.norms = append(s.norms, squaredNormFloat32(vector))
+	return len(s.vectors) - 1
+}
+
+// Len returns the number of candidates
+func (s *CandidateSet) Len() int {
+	return len(s.vectors)
+}
+
+// Similarities returns the cosine similarity of query to each candidate, like BatchSimilarity
+// Returns empty slice for an empty query, and 0.0 for invalid candidates or a zero query
+func (s *CandidateSet) Similarities(query []float32) []float64 {
+	if len(query) == 0 || len(s.vectors) == 0 {
+		return []float64{}
+	}
+
+	results := make([]float64, len(s.vectors))
+	s.similaritiesInto(query, float64(squaredNormFloat32(query)), 0, len(s.vectors), results)
+	return results
+}
+
+// similaritiesInto stores the cosine similarity of query, whose squared norm is queryNorm, to
+// the candidates [start, end) in results
+func (s *CandidateSet) similaritiesInto(query []float32, queryNorm float64, start, end int, results []float64) {
+	if len(query) == 0 || queryNorm == 0.0 {
+		return
+	}
+
+	for idx := start; idx < end; idx++ {
+		candidate := s.vectors[idx]
+		if len(candidate) != len(query) {
+			continue
+		}
+		results[idx] = cosineFromParts(float64(dotFloat32(query, candidate)), queryNorm, float64(s.norms[idx]))
+	}
+}
@@ -0,0 +1,218 @@
+package semanticmatcher
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// LanguageSimplifiedChinese in Config.SupportedLanguages accepts Simplified Chinese
+	// input against a Traditional Chinese vocabulary
+	LanguageSimplifiedChinese = "zh-Hans"
+
+	// LanguageTraditionalChinese in Config.SupportedLanguages accepts Traditional Chinese
+	// input against a Simplified Chinese vocabulary
+	LanguageTraditionalChinese = "zh-Hant"
+)
+
+// FallbackChineseScript is the Config.FallbackChains name of ChineseScriptFallback
+const FallbackChineseScript = "zh_script"
+
+// ChineseConversion is the direction of a Chinese script conversion
+type ChineseConversion int
+
+const (
+	// TraditionalToSimplified converts Traditional Chinese to Simplified Chinese
+	TraditionalToSimplified ChineseConversion = iota
+
+	// SimplifiedToTraditional converts Simplified Chinese to Traditional Chinese
+	SimplifiedToTraditional
+)
+
+// String returns the target language code of the conversion
+func (c ChineseConversion) String() string {
+	if c == SimplifiedToTraditional {
+		return LanguageTraditionalChinese
+	}
+	return LanguageSimplifiedChinese
+}
+
+// ChineseConverter converts between Traditional and Simplified Chinese using the built-in
+// character and phrase tables. Phrases take precedence over single characters and the
+// longest phrase wins. Non-Han text is left unchanged. It is safe for concurrent use.
+type ChineseConverter struct {
+	direction    ChineseConversion
+	chars        map[rune]rune
+	phrases      map[string]string
+	maxPhraseLen int // Longest phrase in runes
+}
+
+var (
+	chineseConvertersOnce sync.Once
+	t2sConverter          *ChineseConverter
+	s2tConverter          *ChineseConverter
+)
+
+// NewChineseConverter returns the converter for direction
+// Converters are built once and shared
+func NewChineseConverter(direction ChineseConversion) *ChineseConverter {
+	chineseConvertersOnce.Do(buildChineseConverters)
+	if direction == SimplifiedToTraditional {
+		return s2tConverter
+	}
+	return t2sConverter
+}
+
+// buildChineseConverters parses the built-in tables into both converters
+func buildChineseConverters() {
+	t2s := make(map[rune]rune)
+	s2t := make(map[rune]rune)
+
+	for _, pair := range strings.Fields(t2sCharPairs) {
+		traditional, simplified := splitCharPair(pair)
+		t2s[traditional] = simplified
+		if _, exists := s2t[simplified]; !exists {
+			s2t[simplified] = traditional
+		}
+	}
+	for _, pair := range strings.Fields(t2sOnlyCharPairs) {
+		traditional, simplified := splitCharPair(pair)
+		t2s[traditional] = simplified
+	}
+
+	t2sConverter = newChineseConverter(TraditionalToSimplified, t2s, t2sPhrases)
+	s2tConverter = newChineseConverter(SimplifiedToTraditional, s2t, s2tPhrases)
+}
+
+// splitCharPair splits a two-character table entry
+func splitCharPair(pair string) (rune, rune) {
+	first, size := utf8.DecodeRuneInString(pair)
+	second, _ := utf8.DecodeRuneInString(pair[size:])
+	return first, second
+}
+
+// newChineseConverter creates a converter from character and phrase tables
+func newChineseConverter(
+	direction ChineseConversion,
+	chars map[rune]rune,
+	phrases map[string]string,
+) *ChineseConverter {
+	maxPhraseLen := 0
+	for phrase := range phrases {
+		maxPhraseLen = max(maxPhraseLen, utf8.RuneCountInString(phrase))
+	}
+	return &ChineseConverter{
+		direction:    direction,
+		chars:        chars,
+		phrases:      phrases,
+		maxPhraseLen: maxPhraseLen,
+	}
+}
+
+// Direction returns the conversion direction
+func (c *ChineseConverter) Direction() ChineseConversion {
+	return c.direction
+}
+
+// Convert returns s converted to the target script
+// A nil converter returns s unchanged.
+func (c *ChineseConverter) Convert(s string) string {
+	if c == nil || !containsHan(s) {
+		return s
+	}
+
+	runes := []rune(s)
+	var builder strings.Builder
+	builder.Grow(len(s))
+
+	for i := 0; i < len(runes); {
+		if matched, length := c.matchPhrase(runes[i:]); length > 0 {
+			builder.WriteString(matched)
+			i += length
+			continue
+		}
+
+		if converted, exists := c.chars[runes[i]]; exists {
+			builder.WriteRune(converted)
+		} else {
+			builder.WriteRune(runes[i])
+		}
+		i++
+	}
+
+	return builder.String()
+}
+
+// matchPhrase returns the conversion of the longest phrase at the start of runes
+// and its length in runes, or a zero length if no phrase matches
+func (c *ChineseConverter) matchPhrase(runes []rune) (string, int) {
+	for length := min(c.maxPhraseLen, len(runes)); length >= 2; length-- {
+		if converted, exists := c.phrases[string(runes[:length])]; exists {
+			return converted, length
+		}
+	}
+	return "", 0
+}
+
+// ChineseScriptFallback looks up the Simplified and Traditional forms of a Chinese word
+type ChineseScriptFallback struct{}
+
+// Name returns the strategy name
+func (ChineseScriptFallback) Name() string {
+	return FallbackChineseScript
+}
+
+// Fallback returns the vector of the first converted form found in the vocabulary
+func (ChineseScriptFallback) Fallback(word string, lookup VocabularyLookup) ([]float32, bool) {
+	if !containsHan(word) {
+		return nil, false
+	}
+
+	for _, direction := range []ChineseConversion{TraditionalToSimplified, SimplifiedToTraditional} {
+		converted := NewChineseConverter(direction).Convert(word)
+		if converted == word {
+			continue
+		}
+		if vector, exists := lookup(converted); exists {
+			return copyVector(vector), true
+		}
+	}
+	return nil, false
+}
+
+// chineseConversionForLanguages returns the preprocessing conversion implied by the
+// supported languages: Traditional input is converted to Simplified when only "zh-Hant"
+// is listed and the other way round when only "zh-Hans" is listed.
+// Returns false if no conversion applies.
+func chineseConversionForLanguages(languages []string) (ChineseConversion, bool) {
+	hasSimplified, hasTraditional := false, false
+	for _, language := range languages {
+		switch language {
+		case LanguageSimplifiedChinese:
+			hasSimplified = true
+		case LanguageTraditionalChinese:
+			hasTraditional = true
+		}
+	}
+
+	switch {
+	case hasTraditional && !hasSimplified:
+		return TraditionalToSimplified, true
+	case hasSimplified && !hasTraditional:
+		return SimplifiedToTraditional, true
+	default:
+		return TraditionalToSimplified, false
+	}
+}
+
+// containsHan reports whether s contains at least one Han character
+func containsHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package semanticmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChineseConverter_Convert(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction ChineseConversion
+		input     string
+		expected  string
+	}{
+		{"T2S characters", TraditionalToSimplified, "電腦網絡", "电脑网络"},
+		{"T2S many to one", TraditionalToSimplified, "頭髮發展", "头发发展"},
+		{"T2S Taiwan", TraditionalToSimplified, "臺灣", "台湾"},
+		{"T2S phrase kept", TraditionalToSimplified, "乾隆", "乾隆"},
+		{"T2S mixed text", TraditionalToSimplified, "AI 軟體", "AI 软体"},
+		{"T2S simplified unchanged", TraditionalToSimplified, "人工智能", "人工智能"},
+		{"S2T characters", SimplifiedToTraditional, "电脑网络", "電腦網絡"},
+		{"S2T default character", SimplifiedToTraditional, "发展", "發展"},
+		{"S2T phrase", SimplifiedToTraditional, "头发", "頭髮"},
+		{"S2T phrase within text", SimplifiedToTraditional, "这个问题很复杂", "這個問題很複雜"},
+		{"S2T default vs phrase", SimplifiedToTraditional, "恢复回复", "恢復回覆"},
+		{"S2T valid traditional kept", SimplifiedToTraditional, "以后的皇后", "以後的皇后"},
+		{"S2T place name", SimplifiedToTraditional, "台湾", "臺灣"},
+		{"no Han characters", SimplifiedToTraditional, "hello", "hello"},
+		{"empty", TraditionalToSimplified, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := NewChineseConverter(tt.direction)
+			assert.Equal(t, tt.direction, converter.Direction())
+			assert.Equal(t, tt.expected, converter.Convert(tt.input))
+		})
+	}
+}
+
+func TestChineseConverter_Nil(t *testing.T) {
+	var converter *ChineseConverter
+	assert.Equal(t, "電腦", converter.Convert("電腦"))
+}
+
+func TestChineseScriptFallback(t *testing.T) {
+	vm := NewVectorModel(2).(*vectorModel)
+	vm.AddVector("电脑", []float32{1, 0})
+	vm.AddVector("頭髮", []float32{0, 1})
+
+	tests := []struct {
+		name     string
+		word     string
+		expected []float32
+		ok       bool
+	}{
+		{"traditional query, simplified vocabulary", "電腦", []float32{1, 0}, true},
+		{"simplified query, traditional vocabulary", "头发", []float32{0, 1}, true},
+		{"unknown word", "软件", nil, false},
+		{"non-Chinese word", "computer", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vector, ok := ChineseScriptFallback{}.Fallback(tt.word, vm.lookupExact)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, vector)
+		})
+	}
+}
+
+func TestChineseConversionForLanguages(t *testing.T) {
+	tests := []struct {
+		languages []string
+		direction ChineseConversion
+		ok        bool
+	}{
+		{[]string{"zh", "en"}, TraditionalToSimplified, false},
+		{[]string{"zh", "zh-Hant"}, TraditionalToSimplified, true},
+		{[]string{"zh-Hans"}, SimplifiedToTraditional, true},
+		{[]string{"zh-Hans", "zh-Hant"}, TraditionalToSimplified, false},
+	}
+
+	for _, tt := range tests {
+		direction, ok := chineseConversionForLanguages(tt.languages)
+		assert.Equal(t, tt.ok, ok, tt.languages)
+		if tt.ok {
+			assert.Equal(t, tt.direction, direction, tt.languages)
+		}
+	}
+}
+
+func TestConfigureChineseConversion(t *testing.T) {
+	vm := NewVectorModel(2).(*vectorModel)
+	vm.AddVector("电脑", []float32{1, 0})
+	vm.AddVector("软件", []float32{0, 1})
+
+	processor := NewTextProcessor()
+	config := DefaultConfig()
+	config.SupportedLanguages = []string{LanguageChinese, LanguageTraditionalChinese, LanguageEnglish}
+
+	configureChineseConversion(processor, vm, config, DiscardLogger{})
+
+	// Preprocessing converts Traditional input to Simplified
+	tokens := processor.Preprocess("電腦")
+	assert.Equal(t, []string{"电脑"}, tokens)
+
+	// Lookups of Traditional words fall back to their Simplified form
+	vector, ok := vm.GetVector("軟件")
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 1}, vector)
+	assert.Equal(t, int64(1), vm.GetFallbackStrategyStats()[FallbackChineseScript].Successes)
+}
+
+func TestConfigureChineseConversion_MixedScript(t *testing.T) {
+	vm := NewVectorModel(2).(*vectorModel)
+	vm.AddVector("手", []float32{1, 0})
+	vm.AddVector("机", []float32{0, 1})
+	vm.AddVector("i", []float32{100, 100})
+	vm.AddVector("P", []float32{100, 100})
+
+	config := DefaultConfig()
+	config.SupportedLanguages = []string{LanguageChinese, LanguageTraditionalChinese, LanguageEnglish}
+	configureChineseConversion(NewTextProcessor(), vm, config, DiscardLogger{})
+
+	// Only the CJK characters of a mixed token are averaged
+	vector, ok := vm.GetVector("iPhone手机")
+	require.True(t, ok)
+	assert.Equal(t, []float32{0.5, 0.5}, vector)
+	assert.Equal(t, int64(1), vm.GetFallbackStrategyStats()[FallbackCharAverageCJK].Successes)
+	assert.Zero(t, vm.GetFallbackStrategyStats()[FallbackCharAverage].Attempts)
+}
+
+func TestValidateConfig_ChineseScriptLanguages(t *testing.T) {
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{"testdata.vec"}
+	config.SupportedLanguages = []string{LanguageChinese, LanguageTraditionalChinese}
+
+	assert.NotErrorIs(t, validateConfig(config), ErrUnsupportedLanguage)
+
+	config.SupportedLanguages = []string{"zh-Hant-TW"}
+	assert.ErrorIs(t, validateConfig(config), ErrUnsupportedLanguage)
+}
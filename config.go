@@ -2,6 +2,7 @@ package semanticmatcher
 
 import (
 	"os"
+	"slices"
 
 	"github.com/spf13/viper"
 )
@@ -28,10 +29,108 @@ type Config struct {
 	EnglishStopWords   string   `mapstructure:"english_stop_words_path"`
 	EnableStats        bool     `mapstructure:"enable_stats"`
 	MemoryLimit        int64    `mapstructure:"memory_limit_bytes"`
-	SupportedLanguages []string `mapstructure:"supported_languages"` // ["zh", "en"]
+	SupportedLanguages []string `mapstructure:"supported_languages"` // ["zh", "en"], plus "zh-Hant" or "zh-Hans"
 	DictPaths          []string `mapstructure:"dict_paths"`
+
+	// VectorLayers stacks further vector files over VectorFilePaths, highest priority first,
+	// e.g. domain-trained vectors over generic wiki vectors. A word is taken from the first
+	// layer that has it, and VectorFilePaths form the last layer, named "base". Layers are
+	// loaded with the same normalization and projection, and can be reloaded at runtime with
+	// SemanticMatcher.ReloadVectorLayer.
+	VectorLayers []VectorLayerConfig `mapstructure:"vector_layers"`
+
+	// VectorMappings rotate the vectors of single files into the space of the others at load
+	// time, before any projection, e.g. to merge unaligned cc.zh.300.vec and cc.en.300.vec.
+	// Mappings are learned with LearnOrthogonalMapping (see tools/align_vec).
+	VectorMappings []VectorMappingConfig `mapstructure:"vector_mappings"`
+
+	// OOVTrackerCapacity is the number of distinct OOV words tracked for TopOOVWords.
+	// Zero uses DefaultOOVTrackerCapacity.
+	OOVTrackerCapacity int `mapstructure:"oov_tracker_capacity"`
+
+	// FallbackCacheSize is the number of OOV words whose fallback result is cached, so
+	// repeated OOV words run the fallback chain only once. Zero disables the cache.
+	FallbackCacheSize int `mapstructure:"fallback_cache_size"`
+
+	// Workers is the maximum number of goroutines a single request uses to process keywords
+	// and compute similarities. Zero uses GOMAXPROCS; 1 processes everything serially.
+	Workers int `mapstructure:"workers"`
+
+	// ParallelThreshold is the number of keywords, candidate vectors or matrix cells below
+	// which a request stays serial. Zero uses DefaultParallelThreshold.
+	ParallelThreshold int `mapstructure:"parallel_threshold"`
+
+	// FallbackChains selects the OOV fallback strategies per language, in order.
+	// Keys are language codes ("zh", "en"); the key "default" applies to all other words.
+	// Strategies: "char_average", "char_average_cjk", "case_fold", "en_morphology", "zh_script",
+	// "synonym", "none".
+	// Languages without an entry keep the default chain (English morphology, then
+	// character-level averaging of CJK words).
+	FallbackChains map[string][]string `mapstructure:"fallback_chains"`
+
+	// SynonymMapPath is a file of "word synonym1 synonym2 ..." lines used by the
+	// "synonym" fallback strategy
+	SynonymMapPath string `mapstructure:"synonym_map_path"`
+
+	// Normalization selects the Unicode normalization applied to both vocabulary keys
+	// at load time and query text, so the two always match. All steps are off by default.
+	Normalization NormalizationConfig `mapstructure:"normalization"`
+
+	// Pooling selects how token vectors are combined into a text vector:
+	// "mean" (default), "max", "min_max", "normalized_sum" or "position_weighted".
+	// It can be overridden per call with WithPooling.
+	Pooling string `mapstructure:"pooling"`
+
+	// SimilarityMetric selects how texts are compared: "cosine" (default), "dot", "euclidean",
+	// "manhattan" or "angular" on text vectors, "jaccard" or "overlap" on token sets, or "wmd"
+	// (Word Mover's Distance) or "rwmd" (relaxed WMD) on word vectors.
+	// Distances are converted to similarities in (0, 1].
+	SimilarityMetric string `mapstructure:"similarity_metric"`
+
+	// CalibratorPath is a calibrator written by SaveCalibrator, fitted with the same metric; if set,
+	// scores are probabilities of relevance unless ScoreMode selects another mode
+	CalibratorPath string `mapstructure:"calibrator_path"`
+
+	// ScoreMode maps raw scores of ComputeSimilarity, FindTopKeywords and SimilarityMatrix:
+	// "raw", "clamped", "affine" or "calibrated" (needs CalibratorPath). Empty calibrates with
+	// a calibrator and clamps otherwise. It can be overridden per call with WithScoreMode.
+	ScoreMode string `mapstructure:"score_mode"`
+
+	// Hybrid fuses a lexical BM25 score of the keyword tokens in the paragraph with the semantic
+	// score in FindTopKeywords, by weighted sum or reciprocal rank fusion. Disabled by default.
+	// It can be overridden per call with WithHybridScoring. Fused scores follow ScoreMode but are
+	// not probabilities, so it cannot be combined with calibration.
+	Hybrid HybridConfig `mapstructure:"hybrid"`
+
+	// TokenWeighting selects how token vectors are weighted before pooling:
+	// "none" (default), "sif" (smooth inverse frequency, estimated from the word order of
+	// the vector files) or "tfidf" (IDF model loaded from IDFModelPath).
+	// It can be overridden per call with WithTokenWeighting.
+	TokenWeighting string `mapstructure:"token_weighting"`
+
+	// SIFParameter is the a in the SIF weight a / (a + p(w)); zero uses DefaultSIFParameter
+	SIFParameter float64 `mapstructure:"sif_parameter"`
+
+	// IDFModelPath is an IDF model written by SaveIDFModel, required by "tfidf" weighting
+	IDFModelPath string `mapstructure:"idf_model_path"`
+
+	// IDFDefault is the IDF of tokens unseen in the IDF corpus; zero uses the smoothed IDF
+	// of a token with document frequency 0, i.e. the largest IDF
+	IDFDefault float64 `mapstructure:"idf_default"`
+
+	// Projection reduces the dimension of all vector files at load time, e.g. PCA from
+	// 300 to 100 dimensions. Disabled by default.
+	Projection ProjectionConfig `mapstructure:"projection"`
+
+	// CommonComponentSamplePath is a file of sample texts, one per line. If set, the common
+	// component of the sample's text vectors is fitted at startup and removed from all text
+	// vectors (see SemanticMatcher.FitCommonComponent).
+	CommonComponentSamplePath string `mapstructure:"common_component_sample_path"`
 }
 
+// DefaultFallbackChainKey is the FallbackChains key for the chain used by all other languages
+const DefaultFallbackChainKey = "default"
+
 // DefaultConfig returns a configuration with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
@@ -43,6 +142,11 @@ func DefaultConfig() *Config {
 		MemoryLimit:        DefaultMemoryLimit,
 		SupportedLanguages: DefaultSupportedLanguages,
 		DictPaths:          []string{},
+		OOVTrackerCapacity: DefaultOOVTrackerCapacity,
+		FallbackCacheSize:  DefaultFallbackCacheSize,
+		Pooling:            PoolingMean,
+		SimilarityMetric:   MetricCosine,
+		TokenWeighting:     WeightingNone,
 	}
 }
 
@@ -105,6 +209,81 @@ func Validate(config *Config) error {
 		return ErrInvalidConfiguration
 	}
 
+	if config.OOVTrackerCapacity < 0 || config.FallbackCacheSize < 0 {
+		return ErrInvalidConfiguration
+	}
+
+	if config.Workers < 0 || config.ParallelThreshold < 0 {
+		return ErrInvalidConfiguration
+	}
+
+	if err := validateHybrid(config.Hybrid); err != nil {
+		return err
+	}
+
+	if err := validateScoreMode(config); err != nil {
+		return err
+	}
+
+	if err := validateFallbackChains(config); err != nil {
+		return err
+	}
+
+	if _, err := NewPooling(config.Pooling); err != nil {
+		return ErrInvalidConfiguration
+	}
+
+	if _, err := NewSimilarityMetric(config.SimilarityMetric); err != nil {
+		return ErrInvalidConfiguration
+	}
+
+	if err := validateTokenWeighting(config); err != nil {
+		return err
+	}
+
+	if err := validateProjection(config.Projection); err != nil {
+		return err
+	}
+
+	if err := validateVectorLayers(config.VectorLayers); err != nil {
+		return err
+	}
+	if err := validateVectorMappings(config); err != nil {
+		return err
+	}
+	for _, mapping := range config.VectorMappings {
+		if _, err := os.Stat(mapping.Path); err != nil {
+			if os.IsNotExist(err) {
+				return ErrInvalidConfiguration
+			}
+			return err
+		}
+	}
+	for _, layer := range config.VectorLayers {
+		for _, path := range layer.VectorFilePaths {
+			if _, err := os.Stat(path); err != nil {
+				if os.IsNotExist(err) {
+					return ErrInvalidConfiguration
+				}
+				return err
+			}
+		}
+	}
+
+	for _, path := range []string{
+		config.SynonymMapPath, config.CommonComponentSamplePath, config.IDFModelPath, config.CalibratorPath,
+	} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				return ErrInvalidConfiguration
+			}
+			return err
+		}
+	}
+
 	// Verify all dict files exist if specified
 	for _, path := range config.DictPaths {
 		if path == "" {
@@ -120,3 +299,90 @@ func Validate(config *Config) error {
 
 	return nil
 }
+
+// validateFallbackChains checks that every configured fallback strategy is known
+// and that the synonym strategy has a synonym map
+func validateFallbackChains(config *Config) error {
+	for _, names := range config.FallbackChains {
+		for _, name := range names {
+			switch name {
+			case FallbackCharAverage, FallbackCharAverageCJK, FallbackCaseFold, FallbackEnglishMorphology,
+				FallbackChineseScript, FallbackNone:
+			case FallbackSynonym:
+				if config.SynonymMapPath == "" {
+					return ErrInvalidConfiguration
+				}
+			default:
+				return ErrInvalidConfiguration
+			}
+		}
+	}
+	return nil
+}
+
+// validateTokenWeighting checks the token weighting name and its parameters
+func validateTokenWeighting(config *Config) error {
+	if config.SIFParameter < 0 || config.IDFDefault < 0 {
+		return ErrInvalidConfiguration
+	}
+
+	if config.TokenWeighting == WeightingTFIDF {
+		if config.IDFModelPath == "" {
+			return ErrInvalidConfiguration
+		}
+		return nil
+	}
+
+	if _, err := NewTokenWeighting(config.TokenWeighting, config.SIFParameter); err != nil {
+		return ErrInvalidConfiguration
+	}
+	return nil
+}
+
+// validateProjection checks the projection method and its parameters
+// A projection without a method only loads a saved projection, so its file must exist.
+func validateProjection(projection ProjectionConfig) error {
+	if projection.SampleSize < 0 {
+		return ErrInvalidConfiguration
+	}
+
+	switch projection.Method {
+	case "":
+		if projection.Path == "" {
+			return nil
+		}
+		if _, err := os.Stat(projection.Path); err != nil {
+			return ErrInvalidConfiguration
+		}
+		return nil
+	case ProjectionPCA:
+		// Later models can only reuse a fitted PCA if it is saved
+		if projection.Path == "" {
+			return ErrInvalidConfiguration
+		}
+	case ProjectionRandom:
+	default:
+		return ErrInvalidConfiguration
+	}
+
+	if projection.Dimension <= 0 {
+		return ErrInvalidConfiguration
+	}
+	return nil
+}
+
+// validateVectorLayers checks that every layer has a distinct name and vector files
+func validateVectorLayers(layers []VectorLayerConfig) error {
+	names := map[string]bool{BaseLayerName: true}
+	for _, layer := range layers {
+		if layer.Name == "" || names[layer.Name] {
+			return ErrInvalidConfiguration
+		}
+		names[layer.Name] = true
+
+		if len(layer.VectorFilePaths) == 0 || slices.Contains(layer.VectorFilePaths, "") {
+			return ErrInvalidConfiguration
+		}
+	}
+	return nil
+}
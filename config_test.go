@@ -193,3 +193,432 @@ func TestValidate_ValidConfig(t *testing.T) {
 		t.Errorf("Expected no error for valid config, got %v", err)
 	}
 }
+
+func TestValidate_NegativeOOVTrackerCapacity(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+	config.OOVTrackerCapacity = -1
+
+	err := Validate(config)
+	if err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for negative OOVTrackerCapacity, got %v", err)
+	}
+}
+
+func TestValidate_FallbackChains(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+	config.FallbackChains = map[string][]string{"en": {"case_fold", "none"}}
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected no error for valid fallback chains, got %v", err)
+	}
+
+	config.FallbackChains = map[string][]string{"en": {"unknown"}}
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for unknown strategy, got %v", err)
+	}
+
+	config.FallbackChains = map[string][]string{"zh": {"synonym"}}
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for synonym strategy without map, got %v", err)
+	}
+
+	config.SynonymMapPath = filepath.Join(tmpDir, "missing.txt")
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for missing synonym map, got %v", err)
+	}
+}
+
+func TestValidate_Pooling(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+
+	config.Pooling = PoolingMinMax
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected min_max pooling to be valid, got %v", err)
+	}
+
+	config.Pooling = "median"
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for unknown pooling, got %v", err)
+	}
+}
+
+func TestValidate_TokenWeighting(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+
+	config.TokenWeighting = WeightingSIF
+	config.SIFParameter = 1e-4
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected sif weighting to be valid, got %v", err)
+	}
+
+	config.SIFParameter = -1
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for negative SIF parameter, got %v", err)
+	}
+
+	config.SIFParameter = 0
+	config.TokenWeighting = "tfidf"
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for unknown token weighting, got %v", err)
+	}
+
+	config.TokenWeighting = WeightingNone
+	config.CommonComponentSamplePath = filepath.Join(tmpDir, "missing.txt")
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for missing common component sample, got %v", err)
+	}
+}
+
+func TestValidate_TFIDFWeighting(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	idfFile := filepath.Join(tmpDir, "corpus.idf")
+	for _, path := range []string{testFile, idfFile} {
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+	config.TokenWeighting = WeightingTFIDF
+
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for tfidf without IDF model, got %v", err)
+	}
+
+	config.IDFModelPath = idfFile
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected tfidf with IDF model to be valid, got %v", err)
+	}
+
+	config.IDFDefault = -1
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for negative IDF default, got %v", err)
+	}
+
+	config.IDFDefault = 0
+	config.IDFModelPath = filepath.Join(tmpDir, "missing.idf")
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for missing IDF model, got %v", err)
+	}
+}
+
+func TestValidate_Projection(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		projection ProjectionConfig
+		valid      bool
+	}{
+		{"disabled", ProjectionConfig{}, true},
+		{"random without file", ProjectionConfig{Method: ProjectionRandom, Dimension: 100}, true},
+		{"pca to be saved", ProjectionConfig{Method: ProjectionPCA, Dimension: 100, Path: filepath.Join(tmpDir, "new.proj")}, true},
+		{"saved projection", ProjectionConfig{Path: testFile}, true},
+		{"pca without file", ProjectionConfig{Method: ProjectionPCA, Dimension: 100}, false},
+		{"missing dimension", ProjectionConfig{Method: ProjectionRandom}, false},
+		{"unknown method", ProjectionConfig{Method: "svd", Dimension: 100}, false},
+		{"missing saved projection", ProjectionConfig{Path: filepath.Join(tmpDir, "missing.proj")}, false},
+	}
+
+	for _, tt := range tests {
+		config := DefaultConfig()
+		config.VectorFilePaths = []string{testFile}
+		config.Projection = tt.projection
+
+		err := Validate(config)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid projection, got %v", tt.name, err)
+		}
+		if !tt.valid && err != ErrInvalidConfiguration {
+			t.Errorf("%s: expected ErrInvalidConfiguration, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestValidate_VectorLayers(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		layers []VectorLayerConfig
+		valid  bool
+	}{
+		{"no layers", nil, true},
+		{"domain layer", []VectorLayerConfig{{Name: "domain", VectorFilePaths: []string{testFile}}}, true},
+		{"missing name", []VectorLayerConfig{{VectorFilePaths: []string{testFile}}}, false},
+		{"base name", []VectorLayerConfig{{Name: BaseLayerName, VectorFilePaths: []string{testFile}}}, false},
+		{"duplicate name", []VectorLayerConfig{
+			{Name: "domain", VectorFilePaths: []string{testFile}},
+			{Name: "domain", VectorFilePaths: []string{testFile}},
+		}, false},
+		{"no files", []VectorLayerConfig{{Name: "domain"}}, false},
+		{"missing file", []VectorLayerConfig{{Name: "domain", VectorFilePaths: []string{filepath.Join(tmpDir, "missing.vec")}}}, false},
+	}
+
+	for _, tt := range tests {
+		config := DefaultConfig()
+		config.VectorFilePaths = []string{testFile}
+		config.VectorLayers = tt.layers
+
+		err := Validate(config)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid layers, got %v", tt.name, err)
+		}
+		if !tt.valid && err != ErrInvalidConfiguration {
+			t.Errorf("%s: expected ErrInvalidConfiguration, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestValidate_VectorMappings(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	layerFile := filepath.Join(tmpDir, "layer.vec")
+	mappingFile := filepath.Join(tmpDir, "mapping.bin")
+	for _, path := range []string{testFile, layerFile, mappingFile} {
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		mappings []VectorMappingConfig
+		valid    bool
+	}{
+		{"no mappings", nil, true},
+		{"vector file", []VectorMappingConfig{{VectorFile: testFile, Path: mappingFile}}, true},
+		{"layer file", []VectorMappingConfig{{VectorFile: layerFile, Path: mappingFile}}, true},
+		{"uncleaned path", []VectorMappingConfig{{VectorFile: tmpDir + "/./test.vec", Path: mappingFile}}, true},
+		{"missing vector file", []VectorMappingConfig{{Path: mappingFile}}, false},
+		{"missing path", []VectorMappingConfig{{VectorFile: testFile}}, false},
+		{"unknown vector file", []VectorMappingConfig{{VectorFile: mappingFile, Path: mappingFile}}, false},
+		{"missing mapping file", []VectorMappingConfig{{VectorFile: testFile, Path: filepath.Join(tmpDir, "missing.bin")}}, false},
+		{"duplicate vector file", []VectorMappingConfig{
+			{VectorFile: testFile, Path: mappingFile},
+			{VectorFile: testFile, Path: mappingFile},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		config := DefaultConfig()
+		config.VectorFilePaths = []string{testFile}
+		config.VectorLayers = []VectorLayerConfig{{Name: "domain", VectorFilePaths: []string{layerFile}}}
+		config.VectorMappings = tt.mappings
+
+		err := Validate(config)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid mappings, got %v", tt.name, err)
+		}
+		if !tt.valid && err != ErrInvalidConfiguration {
+			t.Errorf("%s: expected ErrInvalidConfiguration, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestValidate_FallbackCacheSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+	if config.FallbackCacheSize != DefaultFallbackCacheSize {
+		t.Errorf("Expected default fallback cache size %d, got %d", DefaultFallbackCacheSize, config.FallbackCacheSize)
+	}
+
+	config.FallbackCacheSize = 0
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected disabled fallback cache to be valid, got %v", err)
+	}
+
+	config.FallbackCacheSize = -1
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for negative fallback cache size, got %v", err)
+	}
+}
+
+func TestValidate_SimilarityMetric(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+
+	for _, metric := range []string{"", MetricCosine, MetricEuclidean, MetricJaccard, MetricWMD} {
+		config.SimilarityMetric = metric
+		if err := Validate(config); err != nil {
+			t.Errorf("Expected similarity metric %q to be valid, got %v", metric, err)
+		}
+	}
+
+	config.SimilarityMetric = "hamming"
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for unknown similarity metric, got %v", err)
+	}
+}
+
+func TestValidate_Parallelism(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+	config.Workers = 1
+	config.ParallelThreshold = 100
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected serial workers with a threshold to be valid, got %v", err)
+	}
+
+	config.Workers = -1
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for negative workers, got %v", err)
+	}
+
+	config.Workers = 0
+	config.ParallelThreshold = -1
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for negative parallel threshold, got %v", err)
+	}
+}
+
+func TestValidate_Hybrid(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.vec")
+	if err := os.WriteFile(testFile, []byte("test content"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+	config.Hybrid = HybridConfig{Fusion: FusionRRF, LexicalWeight: 0.3, SemanticWeight: 0.7}
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected rrf hybrid scoring to be valid, got %v", err)
+	}
+
+	config.Hybrid.Fusion = "max"
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for unknown fusion, got %v", err)
+	}
+
+	config.Hybrid = HybridConfig{Fusion: FusionWeightedSum, SemanticWeight: -1}
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for negative weight, got %v", err)
+	}
+}
+
+func TestValidate_CalibratorPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	calibratorFile := filepath.Join(tmpDir, "calibrator.json")
+	for _, path := range []string{testFile, calibratorFile} {
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+	config.CalibratorPath = calibratorFile
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected existing calibrator to be valid, got %v", err)
+	}
+
+	config.CalibratorPath = filepath.Join(tmpDir, "missing.json")
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for missing calibrator, got %v", err)
+	}
+}
+
+func TestValidate_ScoreMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.vec")
+	calibratorFile := filepath.Join(tmpDir, "calibrator.json")
+	for _, path := range []string{testFile, calibratorFile} {
+		if err := os.WriteFile(path, []byte("test content"), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{testFile}
+	for _, mode := range []string{"", ScoreModeRaw, ScoreModeClamped, ScoreModeAffine} {
+		config.ScoreMode = mode
+		if err := Validate(config); err != nil {
+			t.Errorf("Expected score mode %q to be valid, got %v", mode, err)
+		}
+	}
+
+	config.ScoreMode = "sigmoid"
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for unknown score mode, got %v", err)
+	}
+
+	config.ScoreMode = ScoreModeCalibrated
+	if err := Validate(config); err != ErrInvalidConfiguration {
+		t.Errorf("Expected ErrInvalidConfiguration for calibrated mode without calibrator, got %v", err)
+	}
+
+	config.CalibratorPath = calibratorFile
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected calibrated mode with calibrator to be valid, got %v", err)
+	}
+
+	// Fused hybrid scores are not probabilities
+	config.Hybrid = HybridConfig{Fusion: FusionWeightedSum}
+	for _, mode := range []string{"", ScoreModeCalibrated} {
+		config.ScoreMode = mode
+		if err := Validate(config); err != ErrInvalidConfiguration {
+			t.Errorf("Expected ErrInvalidConfiguration for hybrid scoring with calibration, mode %q, got %v", mode, err)
+		}
+	}
+	config.ScoreMode = ScoreModeClamped
+	if err := Validate(config); err != nil {
+		t.Errorf("Expected hybrid scoring with clamped mode to be valid, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cast"
@@ -14,6 +15,9 @@ import (
 type embeddingLoader struct {
 	logger           Logger
 	progressCallback ProgressCallback
+	normalizer       *Normalizer
+	projection       *Projection                   // Applied to every vector as it is loaded; nil keeps vectors as is
+	mappings         map[string]*OrthogonalMapping // Applied before the projection, by cleaned file path
 }
 
 // NewEmbeddingLoader creates a new EmbeddingLoader instance
@@ -29,6 +33,50 @@ func (el *embeddingLoader) SetProgressCallback(callback ProgressCallback) {
 	el.progressCallback = callback
 }
 
+// SetNormalizer sets the Unicode normalization applied to words as they are loaded
+// Models created by the loader keep the normalizer for their lookups
+func (el *embeddingLoader) SetNormalizer(normalizer *Normalizer) {
+	el.normalizer = normalizer
+}
+
+// SetProjection sets the dimensionality reduction applied to every vector as it is loaded
+// Files must have the projection's input dimension; models get its output dimension.
+func (el *embeddingLoader) SetProjection(projection *Projection) {
+	el.projection = projection
+}
+
+// SetVectorMapping sets the orthogonal mapping applied to the vectors of the file at path
+// before any projection; a nil mapping removes it. Only files loaded by path are mapped.
+func (el *embeddingLoader) SetVectorMapping(path string, mapping *OrthogonalMapping) {
+	path = filepath.Clean(path)
+	if mapping == nil {
+		delete(el.mappings, path)
+		return
+	}
+	if el.mappings == nil {
+		el.mappings = make(map[string]*OrthogonalMapping)
+	}
+	el.mappings[path] = mapping
+}
+
+// checkMapping verifies that a mapping fits vectors of the file dimension
+func checkMapping(mapping *OrthogonalMapping, dimension int) error {
+	if mapping != nil && mapping.Dimension() != dimension {
+		return fmt.Errorf("%w: mapping expects dimension %d, got %d",
+			ErrDimensionMismatch, mapping.Dimension(), dimension)
+	}
+	return nil
+}
+
+// fileDimension returns the dimension vector files must have to load into a model of
+// the given dimension
+func (el *embeddingLoader) fileDimension(modelDimension int) int {
+	if el.projection != nil && el.projection.OutputDimension() == modelDimension {
+		return el.projection.InputDimension()
+	}
+	return modelDimension
+}
+
 // LoadFromFile loads vectors from .vec text format file
 func (el *embeddingLoader) LoadFromFile(path string) (VectorModel, error) {
 	el.logger.Infof("Loading vector file, path: %s", path)
@@ -45,7 +93,7 @@ func (el *embeddingLoader) LoadFromFile(path string) (VectorModel, error) {
 	}
 	defer file.Close()
 
-	return el.LoadFromReader(file)
+	return el.loadFromReader(file, el.mappings[filepath.Clean(path)])
 }
 
 // LoadMultipleFiles loads vectors from multiple .vec files and merges them into a single model
@@ -78,7 +126,7 @@ func (el *embeddingLoader) LoadMultipleFiles(paths []string) (VectorModel, error
 
 		// Load first file to create the model
 		if i == 0 {
-			loadedModel, err := el.LoadFromReader(file)
+			loadedModel, err := el.loadFromReader(file, el.mappings[filepath.Clean(path)])
 			file.Close() //nolint:gosec
 			if err != nil {
 				return nil, fmt.Errorf("failed to load first file %s: %w", path, err)
@@ -92,7 +140,7 @@ func (el *embeddingLoader) LoadMultipleFiles(paths []string) (VectorModel, error
 				expectedDimension, model.VocabularySize(), float64(model.MemoryUsage())/(1024*1024))
 		} else {
 			// Merge subsequent files into the existing model
-			err := el.LoadAndMergeIntoModel(model, file)
+			err := el.loadAndMergeIntoModel(model, file, el.mappings[filepath.Clean(path)])
 			file.Close() //nolint:gosec
 			if err != nil {
 				return nil, fmt.Errorf("failed to merge file %s: %w", path, err)
@@ -113,9 +161,18 @@ func (el *embeddingLoader) LoadMultipleFiles(paths []string) (VectorModel, error
 
 // LoadAndMergeIntoModel loads vectors from a reader and merges them into an existing model
 // Returns ErrDimensionMismatch if the vector dimensions don't match
+func (el *embeddingLoader) LoadAndMergeIntoModel(model *vectorModel, reader io.Reader) error {
+	return el.loadAndMergeIntoModel(model, reader, nil)
+}
+
+// loadAndMergeIntoModel is LoadAndMergeIntoModel that maps vectors before projecting them
 //
 //nolint:cyclop,funlen
-func (el *embeddingLoader) LoadAndMergeIntoModel(model *vectorModel, reader io.Reader) error {
+func (el *embeddingLoader) loadAndMergeIntoModel(
+	model *vectorModel,
+	reader io.Reader,
+	mapping *OrthogonalMapping,
+) error {
 	scanner := bufio.NewScanner(reader)
 	// Increase buffer size for better performance with large lines
 	buf := make([]byte, 0, 64*1024)
@@ -148,10 +205,13 @@ func (el *embeddingLoader) LoadAndMergeIntoModel(model *vectorModel, reader io.R
 		return fmt.Errorf("%w: invalid dimension in first line", ErrInvalidVectorFormat)
 	}
 
-	// Verify dimension matches the model
-	if dimension != model.Dimension() {
+	// Verify dimension matches the model (before projection)
+	if expected := el.fileDimension(model.Dimension()); dimension != expected {
 		return fmt.Errorf("%w: expected dimension %d, got %d",
-			ErrDimensionMismatch, model.Dimension(), dimension)
+			ErrDimensionMismatch, expected, dimension)
+	}
+	if err := checkMapping(mapping, dimension); err != nil {
+		return err
 	}
 
 	el.logger.Infof("Merging vector file, word_count: %d, dimension: %d", wordCount, dimension)
@@ -177,6 +237,7 @@ func (el *embeddingLoader) LoadAndMergeIntoModel(model *vectorModel, reader io.R
 	batchSize := 5000 // Add vectors in batches to reduce lock contention
 	wordsBatch := make([]string, 0, batchSize)
 	vectorsBatch := make([][]float32, 0, batchSize)
+	ranksBatch := make([]int, 0, batchSize) // Position in the file, i.e. frequency rank
 
 	// Track overwrites (need to check before batch add)
 	checkOverwrites := func() {
@@ -227,19 +288,28 @@ func (el *embeddingLoader) LoadAndMergeIntoModel(model *vectorModel, reader io.R
 			continue
 		}
 
+		if mapping != nil {
+			vector = mapping.Apply(vector)
+		}
+		if el.projection != nil {
+			vector = el.projection.Apply(vector)
+		}
+
 		// Add to batch
 		wordsBatch = append(wordsBatch, word)
 		vectorsBatch = append(vectorsBatch, vector)
+		ranksBatch = append(ranksBatch, lineNumber-1)
 
 		// Flush batch when it reaches the batch size
 		if len(wordsBatch) >= batchSize {
 			checkOverwrites()
-			added := model.AddVectorsBatch(wordsBatch, vectorsBatch)
+			added := model.addRankedVectorsBatch(wordsBatch, vectorsBatch, ranksBatch)
 			loadedVectors += added
 
 			// Clear batches for reuse
 			wordsBatch = wordsBatch[:0]
 			vectorsBatch = vectorsBatch[:0]
+			ranksBatch = ranksBatch[:0]
 
 			// Report progress at intervals
 			if loadedVectors%progressInterval == 0 {
@@ -262,7 +332,7 @@ func (el *embeddingLoader) LoadAndMergeIntoModel(model *vectorModel, reader io.R
 	// Flush remaining batch
 	if len(wordsBatch) > 0 {
 		checkOverwrites()
-		added := model.AddVectorsBatch(wordsBatch, vectorsBatch)
+		added := model.addRankedVectorsBatch(wordsBatch, vectorsBatch, ranksBatch)
 		loadedVectors += added
 	}
 
@@ -301,9 +371,14 @@ func (el *embeddingLoader) LoadAndMergeIntoModel(model *vectorModel, reader io.R
 }
 
 // LoadFromReader loads vectors from any io.Reader
+func (el *embeddingLoader) LoadFromReader(reader io.Reader) (VectorModel, error) {
+	return el.loadFromReader(reader, nil)
+}
+
+// loadFromReader is LoadFromReader that maps vectors before projecting them
 //
 //nolint:cyclop
-func (el *embeddingLoader) LoadFromReader(reader io.Reader) (VectorModel, error) {
+func (el *embeddingLoader) loadFromReader(reader io.Reader, mapping *OrthogonalMapping) (VectorModel, error) {
 	scanner := bufio.NewScanner(reader)
 	// Increase buffer size for better performance with large lines
 	buf := make([]byte, 0, 64*1024)
@@ -340,13 +415,29 @@ func (el *embeddingLoader) LoadFromReader(reader io.Reader) (VectorModel, error)
 
 	el.logger.Infof("Vector file header parsed, word_count: %d, dimension: %d",
 		wordCount, dimension)
+	if err := checkMapping(mapping, dimension); err != nil {
+		return nil, err
+	}
+
+	// Create vector model, with the projected dimension if vectors are reduced
+	modelDimension := dimension
+	if el.projection != nil {
+		if dimension != el.projection.InputDimension() {
+			return nil, fmt.Errorf("%w: projection expects dimension %d, got %d",
+				ErrDimensionMismatch, el.projection.InputDimension(), dimension)
+		}
+		modelDimension = el.projection.OutputDimension()
+		el.logger.Infof("Projecting vectors, method: %s, from_dimension: %d, to_dimension: %d",
+			el.projection.Method(), dimension, modelDimension)
+	}
 
-	// Create vector model
-	model, ok := NewVectorModel(dimension).(*vectorModel)
+	model, ok := NewVectorModel(modelDimension).(*vectorModel)
 	if !ok {
 		return nil, fmt.Errorf("%w: failed to create vector model", ErrInvalidVectorFormat)
 	}
 
+	model.normalizer = el.normalizer
+
 	// Preallocate capacity to avoid map rehashing
 	model.PreallocateCapacity(wordCount)
 
@@ -366,6 +457,7 @@ func (el *embeddingLoader) LoadFromReader(reader io.Reader) (VectorModel, error)
 	batchSize := 1000 // Add vectors in batches to reduce lock contention
 	wordsBatch := make([]string, 0, batchSize)
 	vectorsBatch := make([][]float32, 0, batchSize)
+	ranksBatch := make([]int, 0, batchSize) // Position in the file, i.e. frequency rank
 
 	// Process each line
 	for scanner.Scan() {
@@ -407,18 +499,27 @@ func (el *embeddingLoader) LoadFromReader(reader io.Reader) (VectorModel, error)
 			continue
 		}
 
+		if mapping != nil {
+			vector = mapping.Apply(vector)
+		}
+		if el.projection != nil {
+			vector = el.projection.Apply(vector)
+		}
+
 		// Add to batch
 		wordsBatch = append(wordsBatch, word)
 		vectorsBatch = append(vectorsBatch, vector)
+		ranksBatch = append(ranksBatch, lineNumber-1)
 
 		// Flush batch when it reaches the batch size
 		if len(wordsBatch) >= batchSize {
-			added := model.AddVectorsBatch(wordsBatch, vectorsBatch)
+			added := model.addRankedVectorsBatch(wordsBatch, vectorsBatch, ranksBatch)
 			loadedVectors += added
 
 			// Clear batches for reuse
 			wordsBatch = wordsBatch[:0]
 			vectorsBatch = vectorsBatch[:0]
+			ranksBatch = ranksBatch[:0]
 
 			// Report progress at intervals
 			if loadedVectors%progressInterval == 0 {
@@ -443,7 +544,7 @@ func (el *embeddingLoader) LoadFromReader(reader io.Reader) (VectorModel, error)
 
 	// Flush remaining batch
 	if len(wordsBatch) > 0 {
-		added := model.AddVectorsBatch(wordsBatch, vectorsBatch)
+		added := model.addRankedVectorsBatch(wordsBatch, vectorsBatch, ranksBatch)
 		loadedVectors += added
 	}
 
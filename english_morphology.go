@@ -0,0 +1,216 @@
+package semanticmatcher
+
+import (
+	"strings"
+)
+
+// FallbackEnglishMorphology is the Config.FallbackChains name of EnglishMorphologyFallback
+const FallbackEnglishMorphology = "en_morphology"
+
+// minMorphologyStemLen is the shortest stem tried after removing an affix
+const minMorphologyStemLen = 3
+
+// irregularLemmas maps common irregular English inflections to their lemma
+var irregularLemmas = map[string]string{
+	"children": "child", "men": "man", "women": "woman", "people": "person",
+	"mice": "mouse", "feet": "foot", "teeth": "tooth", "geese": "goose",
+	"data": "datum", "indices": "index", "matrices": "matrix", "analyses": "analysis",
+	"went": "go", "gone": "go", "was": "be", "were": "be", "been": "be",
+	"did": "do", "done": "do", "had": "have", "made": "make", "ran": "run",
+	"saw": "see", "seen": "see", "took": "take", "taken": "take", "came": "come",
+	"got": "get", "gotten": "get", "gave": "give", "given": "give", "knew": "know",
+	"known": "know", "wrote": "write", "written": "write", "built": "build",
+	"bought": "buy", "brought": "bring", "thought": "think", "taught": "teach",
+	"sold": "sell", "told": "tell", "found": "find", "left": "leave", "paid": "pay",
+	"sent": "send", "spent": "spend", "better": "good", "best": "good",
+	"worse": "bad", "worst": "bad",
+}
+
+// englishPrefixes are stripped from OOV words, longest first. Negating prefixes such as
+// "un", "non" and "dis" are left out, they would map words to their opposites.
+var englishPrefixes = []string{
+	"inter", "micro", "multi", "super", "under", "auto",
+	"over", "post", "semi", "pre", "sub", "co", "re",
+}
+
+// englishSuffixes are derivational suffixes stripped from OOV words, longest first.
+// Negating suffixes such as "less" are left out for the same reason.
+var englishSuffixes = []string{
+	"ization", "ation", "ness", "ment", "able", "ible", "ship",
+	"ful", "ism", "ist", "ity", "ize", "ise", "ly", "al", "er",
+}
+
+// EnglishMorphologyFallback maps English OOV words to related vocabulary words.
+// It tries, in order: the lemma and Porter stem, common prefix and suffix removals,
+// and finally splitting on hyphens and underscores, averaging the parts that are found.
+// Words containing CJK characters are not handled.
+type EnglishMorphologyFallback struct{}
+
+// Name returns the strategy name
+func (EnglishMorphologyFallback) Name() string {
+	return FallbackEnglishMorphology
+}
+
+// Fallback returns the vector of the first morphological variant found in the vocabulary
+func (EnglishMorphologyFallback) Fallback(word string, lookup VocabularyLookup) ([]float32, bool) {
+	if word == "" || containsCJK(word) {
+		return nil, false
+	}
+
+	for _, variant := range englishMorphologicalVariants(word) {
+		if vector, exists := lookup(variant); exists {
+			return copyVector(vector), true
+		}
+	}
+
+	return splitCompoundFallback(word, lookup)
+}
+
+// englishMorphologicalVariants lists lookup candidates for word in priority order:
+// lemma candidates, the Porter stem, then prefix and suffix removals
+func englishMorphologicalVariants(word string) []string {
+	lower := strings.ToLower(word)
+
+	seen := map[string]struct{}{word: {}}
+	variants := make([]string, 0, 16)
+	add := func(candidates ...string) {
+		for _, candidate := range candidates {
+			if len(candidate) < minMorphologyStemLen {
+				continue
+			}
+			if _, exists := seen[candidate]; exists {
+				continue
+			}
+			seen[candidate] = struct{}{}
+			variants = append(variants, candidate)
+		}
+	}
+
+	// 1. Lemma and stem
+	add(lower)
+	add(lemmaCandidates(lower)...)
+	add(porterStem(lower))
+
+	// 2. Prefix removals, also lemmatizing the remainder ("microservices" -> "services" -> "service")
+	for _, prefix := range englishPrefixes {
+		if rest, ok := strings.CutPrefix(lower, prefix); ok && len(rest) >= minMorphologyStemLen {
+			rest = strings.TrimLeft(rest, "-")
+			add(rest)
+			add(lemmaCandidates(rest)...)
+		}
+	}
+
+	// 3. Suffix removals, with and without a restored final e ("usable" -> "us", "use")
+	for _, suffix := range englishSuffixes {
+		if stem, ok := strings.CutSuffix(lower, suffix); ok && len(stem) >= minMorphologyStemLen {
+			add(stem, stem+"e")
+			if strings.HasSuffix(stem, "i") {
+				add(stem[:len(stem)-1] + "y") // "happiness" -> "happy"
+			}
+		}
+	}
+
+	return variants
+}
+
+// lemmaCandidates returns possible lemmas of an inflected English word
+func lemmaCandidates(word string) []string {
+	if lemma, exists := irregularLemmas[word]; exists {
+		return []string{lemma}
+	}
+
+	var candidates []string
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		candidates = append(candidates, word[:len(word)-3]+"y")
+	case strings.HasSuffix(word, "ves"):
+		stem := word[:len(word)-3]
+		candidates = append(candidates, stem+"f", stem+"fe")
+	case strings.HasSuffix(word, "ses"), strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "zes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"):
+		candidates = append(candidates, word[:len(word)-2], word[:len(word)-1])
+	case strings.HasSuffix(word, "ss"):
+	case strings.HasSuffix(word, "s"):
+		candidates = append(candidates, word[:len(word)-1])
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ied"):
+		candidates = append(candidates, word[:len(word)-3]+"y")
+	case strings.HasSuffix(word, "ed"):
+		stem := word[:len(word)-2]
+		candidates = append(candidates, stem, stem+"e")
+		if undoubled, ok := undoubleFinalConsonant(stem); ok {
+			candidates = append(candidates, undoubled)
+		}
+	case strings.HasSuffix(word, "ing"):
+		stem := word[:len(word)-3]
+		candidates = append(candidates, stem, stem+"e")
+		if undoubled, ok := undoubleFinalConsonant(stem); ok {
+			candidates = append(candidates, undoubled)
+		}
+	}
+
+	return candidates
+}
+
+// undoubleFinalConsonant turns "stopp" into "stop"
+func undoubleFinalConsonant(stem string) (string, bool) {
+	n := len(stem)
+	if n < 2 || stem[n-1] != stem[n-2] {
+		return "", false
+	}
+	switch stem[n-1] {
+	case 'a', 'e', 'i', 'o', 'u', 'l', 's', 'z':
+		return "", false
+	}
+	return stem[:n-1], true
+}
+
+// splitCompoundFallback handles hyphenated and underscored compounds: it first tries the
+// joined form ("e-mail" -> "email") and otherwise averages the parts found in the vocabulary,
+// lemmatizing parts that are missing
+func splitCompoundFallback(word string, lookup VocabularyLookup) ([]float32, bool) {
+	isSeparator := func(r rune) bool { return r == '-' || r == '_' }
+	parts := strings.FieldsFunc(strings.ToLower(word), isSeparator)
+	if len(parts) < 2 {
+		return nil, false
+	}
+
+	if vector, exists := lookup(strings.Join(parts, "")); exists {
+		return copyVector(vector), true
+	}
+
+	var sum []float32
+	found := 0
+	for _, part := range parts {
+		vector, exists := lookup(part)
+		if !exists {
+			for _, lemma := range lemmaCandidates(part) {
+				if vector, exists = lookup(lemma); exists {
+					break
+				}
+			}
+		}
+		if !exists {
+			continue
+		}
+
+		if sum == nil {
+			sum = make([]float32, len(vector))
+		}
+		for i, val := range vector {
+			sum[i] += val
+		}
+		found++
+	}
+
+	if found == 0 {
+		return nil, false
+	}
+	for i := range sum {
+		sum[i] /= float32(found)
+	}
+	return sum, true
+}
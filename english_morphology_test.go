@@ -0,0 +1,138 @@
+package semanticmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMorphologyTestModel creates a model with a few English base forms
+func newMorphologyTestModel() *vectorModel {
+	vm := NewVectorModel(3).(*vectorModel)
+	vm.AddVector("service", []float32{1, 0, 0})
+	vm.AddVector("train", []float32{0, 1, 0})
+	vm.AddVector("state", []float32{0, 0, 1})
+	vm.AddVector("art", []float32{1, 1, 0})
+	vm.AddVector("user", []float32{0, 1, 1})
+	vm.AddVector("name", []float32{1, 0, 1})
+	vm.AddVector("email", []float32{2, 0, 0})
+	vm.AddVector("happy", []float32{0, 2, 0})
+	vm.AddVector("connect", []float32{0, 0, 2})
+	vm.AddVector("child", []float32{3, 0, 0})
+	vm.AddVector("stop", []float32{0, 3, 0})
+	vm.AddVector("没", []float32{1, 2, 3})
+	return vm
+}
+
+func TestEnglishMorphologyFallback(t *testing.T) {
+	vm := newMorphologyTestModel()
+	strategy := EnglishMorphologyFallback{}
+
+	tests := []struct {
+		name     string
+		word     string
+		expected []float32
+		ok       bool
+	}{
+		{"plural", "services", []float32{1, 0, 0}, true},
+		{"irregular plural", "children", []float32{3, 0, 0}, true},
+		{"past tense with doubling", "stopped", []float32{0, 3, 0}, true},
+		{"porter stem", "connections", []float32{0, 0, 2}, true},
+		{"prefix and plural", "microservices", []float32{1, 0, 0}, true},
+		{"prefix and past tense", "retrained", []float32{0, 1, 0}, true},
+		{"derivational suffix", "happiness", []float32{0, 2, 0}, true},
+		{"capitalized", "Services", []float32{1, 0, 0}, true},
+		{"hyphen joined form", "e-mail", []float32{2, 0, 0}, true},
+		{"hyphen parts averaged", "state-of-the-art", []float32{0.5, 0.5, 0.5}, true},
+		{"underscore parts averaged", "user_names", []float32{0.5, 0.5, 1}, true},
+		{"unknown word", "xyzzy", nil, false},
+		{"CJK words are skipped", "没有", nil, false},
+		{"empty word", "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vector, ok := strategy.Fallback(tt.word, vm.lookupExact)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.InDeltaSlice(t, tt.expected, vector, 1e-6)
+			}
+		})
+	}
+}
+
+func TestEnglishMorphologyFallback_NoAntonyms(t *testing.T) {
+	vm := NewVectorModel(3).(*vectorModel)
+	for _, word := range []string{
+		"refundable", "safe", "home", "honest", "understand", "war", "productive", "activate", "hope",
+	} {
+		vm.AddVector(word, []float32{1, 0, 0})
+	}
+
+	tests := []struct {
+		word    string
+		antonym string
+	}{
+		{"nonrefundable", "refundable"},
+		{"unsafe", "safe"},
+		{"homeless", "home"},
+		{"dishonest", "honest"},
+		{"misunderstand", "understand"},
+		{"antiwar", "war"},
+		{"counterproductive", "productive"},
+		{"deactivate", "activate"},
+		{"hopelessness", "hope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			_, ok := EnglishMorphologyFallback{}.Fallback(tt.word, vm.lookupExact)
+			assert.False(t, ok, "%s must not be rescued as %s", tt.word, tt.antonym)
+
+			_, ok = vm.GetVector(tt.word)
+			assert.False(t, ok, "default chain must not rescue %s as %s", tt.word, tt.antonym)
+		})
+	}
+}
+
+func TestEnglishMorphologyFallback_ReturnsCopy(t *testing.T) {
+	vm := newMorphologyTestModel()
+
+	vector, ok := EnglishMorphologyFallback{}.Fallback("services", vm.lookupExact)
+	require.True(t, ok)
+	vector[0] = 42
+
+	original, _ := vm.lookupExact("service")
+	assert.Equal(t, float32(1), original[0])
+}
+
+func TestVectorModel_DefaultChainUsesMorphology(t *testing.T) {
+	vm := newMorphologyTestModel()
+
+	tests := []struct {
+		word     string
+		expected []float32
+	}{
+		{"microservices", []float32{1, 0, 0}},
+		{"retrained", []float32{0, 1, 0}},
+		{"user_name", []float32{0.5, 0.5, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			vector, ok := vm.GetVector(tt.word)
+			require.True(t, ok)
+			assert.InDeltaSlice(t, tt.expected, vector, 1e-6)
+		})
+	}
+
+	_, _, _, attempts, successes, failures := vm.GetLookupStats()
+	assert.Equal(t, int64(3), attempts)
+	assert.Equal(t, int64(3), successes)
+	assert.Equal(t, int64(0), failures)
+
+	stats := vm.GetFallbackStrategyStats()
+	assert.Equal(t, FallbackStrategyStats{Attempts: 3, Successes: 3}, stats[FallbackEnglishMorphology])
+	assert.Zero(t, stats[FallbackCharAverage].Attempts)
+}
@@ -30,4 +30,31 @@ var (
 
 	// ErrNoVectorFiles indicates no vector files were specified in configuration
 	ErrNoVectorFiles = errors.New("no vector files specified")
+
+	// ErrWordNotFound indicates a word is not present in the vocabulary
+	ErrWordNotFound = errors.New("word not found in vocabulary")
+
+	// ErrDuplicateID indicates an ID has already been added to an index
+	ErrDuplicateID = errors.New("duplicate index id")
+
+	// ErrInvalidIndexFormat indicates a serialized index could not be decoded
+	ErrInvalidIndexFormat = errors.New("invalid index format")
+
+	// ErrInvalidIDFFormat indicates a saved IDF model could not be parsed
+	ErrInvalidIDFFormat = errors.New("invalid IDF model format")
+
+	// ErrInvalidCalibrationData indicates labeled calibration pairs could not be parsed or fitted
+	ErrInvalidCalibrationData = errors.New("invalid calibration data")
+
+	// ErrInvalidCalibratorFormat indicates a saved calibrator could not be decoded
+	ErrInvalidCalibratorFormat = errors.New("invalid calibrator format")
+
+	// ErrInvalidProjectionFormat indicates a saved projection could not be decoded
+	ErrInvalidProjectionFormat = errors.New("invalid projection format")
+
+	// ErrInvalidMappingFormat indicates a saved vector mapping could not be decoded or is not orthogonal
+	ErrInvalidMappingFormat = errors.New("invalid vector mapping format")
+
+	// ErrLayerNotFound indicates a vector layer name is not part of the layered model
+	ErrLayerNotFound = errors.New("vector layer not found")
 )
@@ -0,0 +1,296 @@
+package semanticmatcher
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// LanguageChinese is the language code used for words containing Han characters
+	LanguageChinese = "zh"
+
+	// LanguageEnglish is the language code used for all other words
+	LanguageEnglish = "en"
+)
+
+// Fallback strategy names accepted in Config.FallbackChains
+const (
+	FallbackCharAverage    = "char_average"
+	FallbackCharAverageCJK = "char_average_cjk"
+	FallbackCaseFold       = "case_fold"
+	FallbackSynonym        = "synonym"
+	FallbackNone           = "none"
+)
+
+// VocabularyLookup performs an exact vocabulary lookup without fallback or statistics.
+// The returned slice is owned by the model and must not be modified.
+type VocabularyLookup func(word string) ([]float32, bool)
+
+// FallbackStrategy produces a vector for a word that is missing from the vocabulary.
+// Strategies are tried in order by the model's fallback chain; the first success wins.
+type FallbackStrategy interface {
+	// Name identifies the strategy in statistics and configuration
+	Name() string
+
+	// Fallback attempts to build a vector for word using exact vocabulary lookups
+	Fallback(word string, lookup VocabularyLookup) ([]float32, bool)
+}
+
+// FallbackStrategyStats holds per-strategy fallback counters
+type FallbackStrategyStats struct {
+	Attempts  int64 `json:"attempts"`
+	Successes int64 `json:"successes"`
+}
+
+// CharacterFallback averages the vectors of the individual characters of a word.
+// With CJKOnly set, only words containing CJK characters are handled and only their
+// CJK characters are averaged, which avoids averaging Latin letters like "a" and "b".
+type CharacterFallback struct {
+	CJKOnly bool
+}
+
+// Name returns the strategy name
+func (f CharacterFallback) Name() string {
+	if f.CJKOnly {
+		return FallbackCharAverageCJK
+	}
+	return FallbackCharAverage
+}
+
+// Fallback averages the vectors of the word's characters that exist in the vocabulary
+// Single character words are not handled since they already failed the main lookup
+func (f CharacterFallback) Fallback(word string, lookup VocabularyLookup) ([]float32, bool) {
+	if utf8.RuneCountInString(word) <= 1 {
+		return nil, false
+	}
+	if f.CJKOnly && !containsCJK(word) {
+		return nil, false
+	}
+
+	var sum []float32
+	validChars := 0
+
+	for _, r := range word {
+		if f.CJKOnly && !isCJK(r) {
+			continue
+		}
+
+		charVec, exists := lookup(string(r))
+		if !exists {
+			continue
+		}
+		if sum == nil {
+			sum = make([]float32, len(charVec))
+		}
+		for i, val := range charVec {
+			sum[i] += val
+		}
+		validChars++
+	}
+
+	if validChars == 0 {
+		return nil, false
+	}
+
+	for i := range sum {
+		sum[i] /= float32(validChars)
+	}
+	return sum, true
+}
+
+// CaseFoldFallback retries the lookup with the lowercase, capitalized and uppercase forms
+type CaseFoldFallback struct{}
+
+// Name returns the strategy name
+func (CaseFoldFallback) Name() string {
+	return FallbackCaseFold
+}
+
+// Fallback returns the vector of the first case variant found in the vocabulary
+func (CaseFoldFallback) Fallback(word string, lookup VocabularyLookup) ([]float32, bool) {
+	lower := strings.ToLower(word)
+	variants := []string{lower, capitalize(lower), strings.ToUpper(word)}
+
+	for _, variant := range variants {
+		if variant == word {
+			continue
+		}
+		if vector, exists := lookup(variant); exists {
+			return copyVector(vector), true
+		}
+	}
+	return nil, false
+}
+
+// SynonymFallback maps OOV words to synonyms that are in the vocabulary
+type SynonymFallback struct {
+	synonyms map[string][]string
+}
+
+// NewSynonymFallback creates a synonym strategy from a word -> synonyms map
+// Synonyms are tried in order and the first one found in the vocabulary is used
+func NewSynonymFallback(synonyms map[string][]string) *SynonymFallback {
+	copied := make(map[string][]string, len(synonyms))
+	for word, list := range synonyms {
+		copied[word] = append([]string(nil), list...)
+	}
+	return &SynonymFallback{synonyms: copied}
+}
+
+// LoadSynonymFallback creates a synonym strategy from a file where each line holds a word
+// followed by its synonyms, separated by whitespace. Empty lines and lines starting with #
+// are ignored.
+func LoadSynonymFallback(path string) (*SynonymFallback, error) {
+	synonyms, err := loadWordListFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &SynonymFallback{synonyms: synonyms}, nil
+}
+
+// Name returns the strategy name
+func (*SynonymFallback) Name() string {
+	return FallbackSynonym
+}
+
+// Fallback returns the vector of the first synonym found in the vocabulary
+func (f *SynonymFallback) Fallback(word string, lookup VocabularyLookup) ([]float32, bool) {
+	synonyms, exists := f.synonyms[word]
+	if !exists {
+		synonyms = f.synonyms[strings.ToLower(word)]
+	}
+
+	for _, synonym := range synonyms {
+		if vector, exists := lookup(synonym); exists {
+			return copyVector(vector), true
+		}
+	}
+	return nil, false
+}
+
+// NoFallback never produces a vector; use it to disable fallback for a language
+type NoFallback struct{}
+
+// Name returns the strategy name
+func (NoFallback) Name() string {
+	return FallbackNone
+}
+
+// Fallback always fails
+func (NoFallback) Fallback(string, VocabularyLookup) ([]float32, bool) {
+	return nil, false
+}
+
+// DefaultFallbackChain returns the chain used when none is configured:
+// English morphological variants, then character-level averaging of CJK words
+// Letters are not averaged: the mean of letter vectors says little about an English word.
+func DefaultFallbackChain() []FallbackStrategy {
+	return []FallbackStrategy{EnglishMorphologyFallback{}, CharacterFallback{CJKOnly: true}}
+}
+
+// NewFallbackChain builds a fallback chain from strategy names (see Config.FallbackChains)
+// synonyms is used for the "synonym" strategy and may be nil if that strategy is not requested
+func NewFallbackChain(names []string, synonyms *SynonymFallback) ([]FallbackStrategy, error) {
+	chain := make([]FallbackStrategy, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case FallbackCharAverage:
+			chain = append(chain, CharacterFallback{})
+		case FallbackCharAverageCJK:
+			chain = append(chain, CharacterFallback{CJKOnly: true})
+		case FallbackCaseFold:
+			chain = append(chain, CaseFoldFallback{})
+		case FallbackEnglishMorphology:
+			chain = append(chain, EnglishMorphologyFallback{})
+		case FallbackChineseScript:
+			chain = append(chain, ChineseScriptFallback{})
+		case FallbackSynonym:
+			if synonyms == nil {
+				return nil, fmt.Errorf("%w: synonym fallback requires a synonym map", ErrInvalidConfiguration)
+			}
+			chain = append(chain, synonyms)
+		case FallbackNone:
+			chain = append(chain, NoFallback{})
+		default:
+			return nil, fmt.Errorf("%w: unknown fallback strategy %q", ErrInvalidConfiguration, name)
+		}
+	}
+	return chain, nil
+}
+
+// wordLanguage returns the language used to select a fallback chain for word
+func wordLanguage(word string) string {
+	for _, r := range word {
+		if unicode.Is(unicode.Han, r) {
+			return LanguageChinese
+		}
+	}
+	return LanguageEnglish
+}
+
+// isCJK reports whether r is a Chinese, Japanese or Korean character
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// containsCJK reports whether s contains at least one CJK character
+func containsCJK(s string) bool {
+	for _, r := range s {
+		if isCJK(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// capitalize uppercases the first rune of s
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// copyVector returns a copy of v
+func copyVector(v []float32) []float32 {
+	result := make([]float32, len(v))
+	copy(result, v)
+	return result
+}
+
+// loadWordListFile reads lines of "word item1 item2 ..." into a map
+// Empty lines and lines starting with # are skipped
+func loadWordListFile(path string) (map[string][]string, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make(map[string][]string)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		entries[fields[0]] = append(entries[fields[0]], fields[1:]...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
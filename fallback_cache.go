@@ -0,0 +1,123 @@
+package semanticmatcher
+
+import "container/list"
+
+// DefaultFallbackCacheSize is the number of fallback results cached by NewSemanticMatcherFromConfig by default
+const DefaultFallbackCacheSize = 10000
+
+// FallbackCacheStats reports the use of a vector model's fallback cache
+type FallbackCacheStats struct {
+	Capacity  int   `json:"capacity"` // Maximum number of cached words; 0 if the cache is disabled
+	Size      int   `json:"size"`     // Number of cached words
+	Hits      int64 `json:"hits"`     // OOV lookups answered from the cache
+	Misses    int64 `json:"misses"`   // OOV lookups that ran the fallback chain
+	Evictions int64 `json:"evictions"`
+}
+
+// HitRate returns the fraction of OOV lookups answered from the cache
+func (s FallbackCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0.0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// fallbackCache is a least-recently-used cache of fallback chain results, including
+// failures, keyed by normalized word. Repeated OOV words such as product names then run
+// the fallback chain only once.
+// The cache is not thread-safe; callers must hold the model lock.
+type fallbackCache struct {
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // Most recently used first
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// fallbackCacheEntry is a cached fallback result
+type fallbackCacheEntry struct {
+	word   string
+	vector []float32 // nil if the fallback chain failed
+	found  bool
+}
+
+// newFallbackCache creates a cache holding at most capacity words, or returns nil to
+// disable caching if capacity is not positive
+func newFallbackCache(capacity int) *fallbackCache {
+	if capacity <= 0 {
+		return nil
+	}
+
+	return &fallbackCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// get returns the cached fallback result of word and whether there was one
+func (c *fallbackCache) get(word string) ([]float32, bool, bool) {
+	element, exists := c.entries[word]
+	if !exists {
+		c.misses++
+		return nil, false, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(element)
+	entry := element.Value.(*fallbackCacheEntry) //nolint:errcheck,forcetypeassert
+	return entry.vector, entry.found, true
+}
+
+// put caches the fallback result of word, evicting the least recently used word if the cache is full
+func (c *fallbackCache) put(word string, vector []float32, found bool) {
+	if element, exists := c.entries[word]; exists {
+		entry := element.Value.(*fallbackCacheEntry) //nolint:errcheck,forcetypeassert
+		entry.vector, entry.found = vector, found
+		c.order.MoveToFront(element)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*fallbackCacheEntry).word) //nolint:errcheck,forcetypeassert
+		c.evictions++
+	}
+
+	c.entries[word] = c.order.PushFront(&fallbackCacheEntry{word: word, vector: vector, found: found})
+}
+
+// clear drops all cached results, e.g. after the vocabulary changed
+// Counters are kept.
+func (c *fallbackCache) clear() {
+	if c == nil || c.order.Len() == 0 {
+		return
+	}
+	c.entries = make(map[string]*list.Element, c.capacity)
+	c.order.Init()
+}
+
+// resetCounters zeroes the hit, miss and eviction counters
+func (c *fallbackCache) resetCounters() {
+	if c == nil {
+		return
+	}
+	c.hits, c.misses, c.evictions = 0, 0, 0
+}
+
+// stats returns the cache counters; a nil cache reports zero capacity
+func (c *fallbackCache) stats() FallbackCacheStats {
+	if c == nil {
+		return FallbackCacheStats{}
+	}
+	return FallbackCacheStats{
+		Capacity:  c.capacity,
+		Size:      c.order.Len(),
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
@@ -0,0 +1,95 @@
+package semanticmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackCache_LRU(t *testing.T) {
+	cache := newFallbackCache(2)
+	cache.put("ab", []float32{1}, true)
+	cache.put("xyz", nil, false)
+
+	vector, found, cached := cache.get("ab")
+	require.True(t, cached)
+	assert.True(t, found)
+	assert.Equal(t, []float32{1}, vector)
+
+	// "xyz" is now the least recently used word
+	cache.put("cd", []float32{2}, true)
+	_, _, cached = cache.get("xyz")
+	assert.False(t, cached)
+	_, _, cached = cache.get("cd")
+	assert.True(t, cached)
+
+	assert.Equal(t, FallbackCacheStats{Capacity: 2, Size: 2, Hits: 2, Misses: 1, Evictions: 1}, cache.stats())
+	assert.InDelta(t, 2.0/3.0, cache.stats().HitRate(), 1e-9)
+
+	cache.clear()
+	assert.Equal(t, 0, cache.stats().Size)
+	assert.Equal(t, int64(2), cache.stats().Hits)
+
+	assert.Nil(t, newFallbackCache(0))
+	var disabled *fallbackCache
+	assert.Equal(t, FallbackCacheStats{}, disabled.stats())
+}
+
+func TestVectorModel_FallbackCache(t *testing.T) {
+	vm := newFallbackTestModel()
+	vm.SetFallbackChain("", CharacterFallback{})
+	vm.SetFallbackCacheSize(10)
+
+	for range 3 {
+		vector, ok := vm.GetVector("ab")
+		require.True(t, ok)
+		assert.Equal(t, []float32{0.5, 0.5, 0}, vector)
+		_, ok = vm.GetVector("xyz")
+		assert.False(t, ok)
+	}
+
+	// The chain runs once per word; cached results still count as fallback outcomes
+	assert.Equal(t, FallbackStrategyStats{Attempts: 2, Successes: 1}, vm.GetFallbackStrategyStats()[FallbackCharAverage])
+	_, _, _, attempts, successes, failures := vm.GetLookupStats()
+	assert.Equal(t, int64(6), attempts)
+	assert.Equal(t, int64(3), successes)
+	assert.Equal(t, int64(3), failures)
+	assert.Equal(t, FallbackCacheStats{Capacity: 10, Size: 2, Hits: 4, Misses: 2}, vm.GetFallbackCacheStats())
+
+	// Adding a word that changes a fallback result invalidates the cache
+	vm.AddVector("x", []float32{0, 0, 1})
+	assert.Equal(t, 0, vm.GetFallbackCacheStats().Size)
+	vector, ok := vm.GetVector("xyz")
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 0, 1}, vector)
+
+	// So do fallback chain changes
+	vm.SetFallbackChain("")
+	_, ok = vm.GetVector("xyz")
+	assert.False(t, ok)
+
+	vm.ResetStats()
+	assert.Equal(t, FallbackCacheStats{Capacity: 10, Size: 1}, vm.GetFallbackCacheStats())
+
+	vm.SetFallbackCacheSize(0)
+	assert.Equal(t, FallbackCacheStats{}, vm.GetFallbackCacheStats())
+}
+
+func TestLayeredVectorModel_FallbackCache(t *testing.T) {
+	model := newLayeredTestModel(t)
+	model.SetFallbackCacheSize(10)
+
+	_, ok := model.GetVector("gadgets")
+	assert.False(t, ok)
+
+	// A replaced layer can change fallback results
+	retrained := NewVectorModel(3)
+	retrained.(*vectorModel).AddVector("gadget", []float32{1, 0, 1})
+	require.NoError(t, model.ReplaceLayer("domain", retrained))
+	assert.Equal(t, 0, model.GetFallbackCacheStats().Size)
+
+	vector, ok := model.GetVector("gadgets")
+	require.True(t, ok)
+	assert.Equal(t, []float32{1, 0, 1}, vector)
+}
@@ -0,0 +1,197 @@
+package semanticmatcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFallbackTestModel creates a model with single letters, CJK characters and a few words
+func newFallbackTestModel() *vectorModel {
+	vm := NewVectorModel(3).(*vectorModel)
+	vm.AddVector("a", []float32{1, 0, 0})
+	vm.AddVector("b", []float32{0, 1, 0})
+	vm.AddVector("没", []float32{1, 2, 3})
+	vm.AddVector("事", []float32{3, 4, 5})
+	vm.AddVector("refund", []float32{0, 0, 1})
+	vm.AddVector("London", []float32{0.5, 0.5, 0})
+	return vm
+}
+
+func TestCharacterFallback(t *testing.T) {
+	vm := newFallbackTestModel()
+
+	tests := []struct {
+		name     string
+		strategy CharacterFallback
+		word     string
+		expected []float32
+		ok       bool
+	}{
+		{"all scripts averages letters", CharacterFallback{}, "ab", []float32{0.5, 0.5, 0}, true},
+		{"all scripts averages CJK", CharacterFallback{}, "没事", []float32{2, 3, 4}, true},
+		{"CJK only skips latin words", CharacterFallback{CJKOnly: true}, "ab", nil, false},
+		{"CJK only averages CJK", CharacterFallback{CJKOnly: true}, "没事", []float32{2, 3, 4}, true},
+		{"CJK only ignores latin letters in mixed words", CharacterFallback{CJKOnly: true}, "a没b事", []float32{2, 3, 4}, true},
+		{"single character fails", CharacterFallback{}, "c", nil, false},
+		{"no known characters fails", CharacterFallback{}, "xyz", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vector, ok := tt.strategy.Fallback(tt.word, vm.lookupExact)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, vector)
+		})
+	}
+}
+
+func TestCaseFoldFallback(t *testing.T) {
+	vm := newFallbackTestModel()
+	strategy := CaseFoldFallback{}
+
+	vector, ok := strategy.Fallback("REFUND", vm.lookupExact)
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 0, 1}, vector)
+
+	vector, ok = strategy.Fallback("london", vm.lookupExact)
+	require.True(t, ok)
+	assert.Equal(t, []float32{0.5, 0.5, 0}, vector)
+
+	_, ok = strategy.Fallback("missing", vm.lookupExact)
+	assert.False(t, ok)
+}
+
+func TestSynonymFallback(t *testing.T) {
+	vm := newFallbackTestModel()
+	strategy := NewSynonymFallback(map[string][]string{
+		"退钱": {"退款", "refund"},
+	})
+
+	vector, ok := strategy.Fallback("退钱", vm.lookupExact)
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 0, 1}, vector)
+
+	_, ok = strategy.Fallback("退货", vm.lookupExact)
+	assert.False(t, ok)
+}
+
+func TestLoadSynonymFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.txt")
+	content := "# comment\n退钱 退款 refund\n\nlonely\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	strategy, err := LoadSynonymFallback(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"退钱": {"退款", "refund"}}, strategy.synonyms)
+
+	_, err = LoadSynonymFallback(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
+
+func TestNoFallback(t *testing.T) {
+	vm := newFallbackTestModel()
+	_, ok := NoFallback{}.Fallback("ab", vm.lookupExact)
+	assert.False(t, ok)
+}
+
+func TestVectorModel_FallbackChainPerLanguage(t *testing.T) {
+	vm := newFallbackTestModel()
+	vm.SetFallbackChain(LanguageEnglish, CaseFoldFallback{}, NoFallback{})
+	vm.SetFallbackChain(LanguageChinese, CharacterFallback{CJKOnly: true})
+
+	// English words no longer average letters
+	_, ok := vm.GetVector("ab")
+	assert.False(t, ok)
+
+	vector, ok := vm.GetVector("Refund")
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 0, 1}, vector)
+
+	// Chinese words still use character averaging
+	vector, ok = vm.GetVector("没事")
+	require.True(t, ok)
+	assert.Equal(t, []float32{2, 3, 4}, vector)
+
+	stats := vm.GetFallbackStrategyStats()
+	assert.Equal(t, FallbackStrategyStats{Attempts: 2, Successes: 1}, stats[FallbackCaseFold])
+	assert.Equal(t, FallbackStrategyStats{Attempts: 1, Successes: 0}, stats[FallbackNone])
+	assert.Equal(t, FallbackStrategyStats{Attempts: 1, Successes: 1}, stats[FallbackCharAverageCJK])
+
+	_, _, _, attempts, successes, failures := vm.GetLookupStats()
+	assert.Equal(t, int64(3), attempts)
+	assert.Equal(t, int64(2), successes)
+	assert.Equal(t, int64(1), failures)
+
+	vm.ResetStats()
+	assert.Empty(t, vm.GetFallbackStrategyStats())
+}
+
+func TestVectorModel_DefaultAndDisabledFallbackChain(t *testing.T) {
+	vm := newFallbackTestModel()
+
+	// The default chain averages the characters of CJK words only, not letters
+	_, ok := vm.GetVector("ab")
+	assert.False(t, ok)
+	vector, ok := vm.GetVector("没事")
+	assert.True(t, ok)
+	assert.Equal(t, []float32{2, 3, 4}, vector)
+	assert.Equal(t, FallbackStrategyStats{Attempts: 2, Successes: 1}, vm.GetFallbackStrategyStats()[FallbackCharAverageCJK])
+
+	// An empty default chain disables fallback entirely
+	vm.SetFallbackChain("")
+	_, ok = vm.GetVector("ab")
+	assert.False(t, ok)
+
+	_, ok = vm.GetAverageVector([]string{"没事"})
+	assert.False(t, ok)
+}
+
+func TestNewFallbackChain(t *testing.T) {
+	synonyms := NewSynonymFallback(map[string][]string{"x": {"y"}})
+
+	chain, err := NewFallbackChain(
+		[]string{FallbackCaseFold, FallbackEnglishMorphology, FallbackSynonym, FallbackCharAverageCJK, FallbackCharAverage, FallbackNone},
+		synonyms,
+	)
+	require.NoError(t, err)
+
+	names := make([]string, len(chain))
+	for i, strategy := range chain {
+		names[i] = strategy.Name()
+	}
+	assert.Equal(t,
+		[]string{FallbackCaseFold, FallbackEnglishMorphology, FallbackSynonym, FallbackCharAverageCJK, FallbackCharAverage, FallbackNone},
+		names)
+
+	_, err = NewFallbackChain([]string{FallbackSynonym}, nil)
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	_, err = NewFallbackChain([]string{"unknown"}, nil)
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+}
+
+func TestConfigureFallbackChains(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.txt")
+	require.NoError(t, os.WriteFile(path, []byte("退钱 refund\n"), 0o644))
+
+	vm := newFallbackTestModel()
+	config := DefaultConfig()
+	config.SynonymMapPath = path
+	config.FallbackChains = map[string][]string{
+		LanguageChinese:         {FallbackSynonym, FallbackCharAverageCJK},
+		DefaultFallbackChainKey: {FallbackNone},
+	}
+
+	require.NoError(t, configureFallbackChains(vm, config, DiscardLogger{}))
+
+	vector, ok := vm.GetVector("退钱")
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 0, 1}, vector)
+
+	_, ok = vm.GetVector("ab")
+	assert.False(t, ok)
+}
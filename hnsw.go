@@ -0,0 +1,641 @@
+package semanticmatcher
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+)
+
+// HNSWMetric selects the similarity function used by an HNSWIndex
+type HNSWMetric int
+
+const (
+	// HNSWCosine ranks neighbors by cosine similarity; vectors are normalized on insert
+	HNSWCosine HNSWMetric = iota
+
+	// HNSWDot ranks neighbors by raw dot product (maximum inner product search)
+	HNSWDot
+)
+
+const (
+	DefaultHNSWM              = 16
+	DefaultHNSWEfConstruction = 200
+	DefaultHNSWEfSearch       = 64
+	DefaultHNSWSeed           = 42
+
+	// hnswFormatVersion is bumped whenever the serialized layout changes
+	hnswFormatVersion = 1
+)
+
+// String returns the human-readable name of the metric
+func (m HNSWMetric) String() string {
+	switch m {
+	case HNSWCosine:
+		return "cosine"
+	case HNSWDot:
+		return "dot"
+	default:
+		return fmt.Sprintf("HNSWMetric(%d)", int(m))
+	}
+}
+
+// HNSWConfig holds construction and search parameters for an HNSWIndex
+type HNSWConfig struct {
+	// M is the number of bi-directional links created per node on every layer above 0.
+	// Layer 0 allows 2*M links.
+	M int `mapstructure:"m"`
+
+	// EfConstruction is the size of the dynamic candidate list used while inserting
+	EfConstruction int `mapstructure:"ef_construction"`
+
+	// EfSearch is the default size of the dynamic candidate list used while searching
+	EfSearch int `mapstructure:"ef_search"`
+
+	// Metric selects cosine or dot-product similarity
+	Metric HNSWMetric `mapstructure:"metric"`
+
+	// Seed makes level assignment, and therefore the graph, reproducible
+	Seed int64 `mapstructure:"seed"`
+}
+
+// DefaultHNSWConfig returns HNSW parameters suitable for 100-300 dimensional word vectors
+func DefaultHNSWConfig() HNSWConfig {
+	return HNSWConfig{
+		M:              DefaultHNSWM,
+		EfConstruction: DefaultHNSWEfConstruction,
+		EfSearch:       DefaultHNSWEfSearch,
+		Metric:         HNSWCosine,
+		Seed:           DefaultHNSWSeed,
+	}
+}
+
+// IndexResult is a single approximate nearest-neighbor search result
+type IndexResult struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"` // Cosine similarity or dot product, depending on the metric
+}
+
+// HNSWIndex is an approximate nearest-neighbor index based on
+// Hierarchical Navigable Small World graphs (Malkov & Yashunin).
+// It supports incremental inserts, concurrent searches and serialization to disk.
+type HNSWIndex struct {
+	config    HNSWConfig
+	dimension int
+	levelMult float64    // 1/ln(M), normalization factor for level generation
+	rng       *rand.Rand // Level generator, guarded by mtx
+
+	ids        []string       // Node ID by internal index
+	idIndex    map[string]int // Internal index by node ID
+	vectors    [][]float32    // Stored (normalized for cosine) vectors
+	links      [][][]int32    // links[node][level] lists neighbor nodes
+	entryPoint int
+	maxLevel   int
+
+	mtx sync.RWMutex
+}
+
+// NewHNSWIndex creates an empty index for vectors of the given dimension
+// Zero-valued config fields are replaced with their defaults
+func NewHNSWIndex(dimension int, config HNSWConfig) (*HNSWIndex, error) {
+	if dimension <= 0 {
+		return nil, fmt.Errorf("%w: index dimension must be positive", ErrInvalidConfiguration)
+	}
+
+	defaults := DefaultHNSWConfig()
+	if config.M == 0 {
+		config.M = defaults.M
+	}
+	if config.EfConstruction == 0 {
+		config.EfConstruction = defaults.EfConstruction
+	}
+	if config.EfSearch == 0 {
+		config.EfSearch = defaults.EfSearch
+	}
+	if config.M < 2 || config.EfConstruction < 1 || config.EfSearch < 1 {
+		return nil, fmt.Errorf("%w: HNSW parameters must be positive and M >= 2", ErrInvalidConfiguration)
+	}
+	if config.Metric != HNSWCosine && config.Metric != HNSWDot {
+		return nil, fmt.Errorf("%w: unknown HNSW metric %d", ErrInvalidConfiguration, int(config.Metric))
+	}
+
+	return &HNSWIndex{
+		config:     config,
+		dimension:  dimension,
+		levelMult:  1 / math.Log(float64(config.M)),
+		rng:        rand.New(rand.NewSource(config.Seed)), //nolint:gosec
+		idIndex:    make(map[string]int),
+		entryPoint: -1,
+		maxLevel:   -1,
+	}, nil
+}
+
+// BuildVocabularyIndex indexes every word of a vector model for neighbor search
+// Words are inserted in the order of VectorModel.All, ranked words first and the rest
+// sorted, so that the same model always yields the same graph. Words with an all-zero vector,
+// which Add rejects, are skipped instead of failing the whole build.
+func BuildVocabularyIndex(model VectorModel, config HNSWConfig) (*HNSWIndex, error) {
+	if model == nil {
+		return nil, ErrModelNotInitialized
+	}
+
+	index, err := NewHNSWIndex(model.Dimension(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	for word, vector := range model.All() {
+		if !isValidVector(vector) {
+			continue
+		}
+		if err := index.Add(word, vector); err != nil {
+			return nil, fmt.Errorf("failed to index word %q: %w", word, err)
+		}
+	}
+
+	return index, nil
+}
+
+// Dimension returns the vector dimension of the index
+func (idx *HNSWIndex) Dimension() int {
+	return idx.dimension
+}
+
+// Len returns the number of vectors in the index
+func (idx *HNSWIndex) Len() int {
+	idx.mtx.RLock()
+	defer idx.mtx.RUnlock()
+	return len(idx.ids)
+}
+
+// Config returns the parameters the index was built with
+func (idx *HNSWIndex) Config() HNSWConfig {
+	idx.mtx.RLock()
+	defer idx.mtx.RUnlock()
+	return idx.config
+}
+
+// SetEfSearch changes the default search-time candidate list size
+// Larger values improve recall at the cost of latency
+func (idx *HNSWIndex) SetEfSearch(ef int) {
+	if ef <= 0 {
+		return
+	}
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	idx.config.EfSearch = ef
+}
+
+// Contains reports whether an ID has been indexed
+func (idx *HNSWIndex) Contains(id string) bool {
+	idx.mtx.RLock()
+	defer idx.mtx.RUnlock()
+	_, exists := idx.idIndex[id]
+	return exists
+}
+
+// Add inserts a vector under the given ID
+// Returns ErrDimensionMismatch for vectors of the wrong size and ErrDuplicateID if the ID exists
+func (idx *HNSWIndex) Add(id string, vector []float32) error {
+	if id == "" || !isValidVector(vector) {
+		return ErrEmptyInput
+	}
+	if len(vector) != idx.dimension {
+		return fmt.Errorf("%w: expected dimension %d, got %d",
+			ErrDimensionMismatch, idx.dimension, len(vector))
+	}
+
+	stored := make([]float32, len(vector))
+	copy(stored, vector)
+	if idx.config.Metric == HNSWCosine {
+		stored = NormalizeVector(stored)
+	}
+
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+
+	if _, exists := idx.idIndex[id]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateID, id)
+	}
+
+	node := len(idx.ids)
+	level := int(math.Floor(-math.Log(1-idx.rng.Float64()) * idx.levelMult))
+
+	idx.ids = append(idx.ids, id)
+	idx.idIndex[id] = node
+	idx.vectors = append(idx.vectors, stored)
+	idx.links = append(idx.links, make([][]int32, level+1))
+
+	// First node becomes the entry point
+	if idx.entryPoint < 0 {
+		idx.entryPoint = node
+		idx.maxLevel = level
+		return nil
+	}
+
+	// Greedy descent through the layers above the new node's level
+	entry := idx.entryPoint
+	for lc := idx.maxLevel; lc > level; lc-- {
+		entry = idx.greedyClosest(stored, entry, lc)
+	}
+
+	entries := []int32{int32(entry)} //nolint:gosec
+	for lc := min(level, idx.maxLevel); lc >= 0; lc-- {
+		candidates := idx.searchLayer(stored, entries, idx.config.EfConstruction, lc)
+		neighbors := idx.selectNeighbors(candidates, idx.config.M)
+
+		idx.links[node][lc] = neighbors
+		for _, neighbor := range neighbors {
+			idx.connect(int(neighbor), int32(node), lc) //nolint:gosec
+		}
+
+		entries = entries[:0]
+		for _, candidate := range candidates {
+			entries = append(entries, candidate.node)
+		}
+	}
+
+	if level > idx.maxLevel {
+		idx.entryPoint = node
+		idx.maxLevel = level
+	}
+
+	return nil
+}
+
+// Search returns the k approximate nearest neighbors of query using the default ef
+func (idx *HNSWIndex) Search(query []float32, k int) []IndexResult {
+	idx.mtx.RLock()
+	ef := idx.config.EfSearch
+	idx.mtx.RUnlock()
+	return idx.SearchWithEf(query, k, ef)
+}
+
+// SearchWithEf returns the k approximate nearest neighbors of query using the given ef
+// Results are sorted by score in descending order. ef is raised to k if smaller.
+func (idx *HNSWIndex) SearchWithEf(query []float32, k, ef int) []IndexResult {
+	if k <= 0 || len(query) != idx.dimension || !isValidVector(query) {
+		return []IndexResult{}
+	}
+
+	if idx.config.Metric == HNSWCosine {
+		query = NormalizeVector(query)
+	}
+
+	idx.mtx.RLock()
+	defer idx.mtx.RUnlock()
+
+	if idx.entryPoint < 0 {
+		return []IndexResult{}
+	}
+
+	entry := idx.entryPoint
+	for lc := idx.maxLevel; lc > 0; lc-- {
+		entry = idx.greedyClosest(query, entry, lc)
+	}
+
+	candidates := idx.searchLayer(query, []int32{int32(entry)}, max(ef, k), 0) //nolint:gosec
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+
+	results := make([]IndexResult, len(candidates))
+	for i, candidate := range candidates {
+		results[i] = IndexResult{ID: idx.ids[candidate.node], Score: candidate.score}
+	}
+	return results
+}
+
+// similarity computes the configured similarity between a query and a stored node
+func (idx *HNSWIndex) similarity(query []float32, node int32) float64 {
+	return DotProduct(query, idx.vectors[node])
+}
+
+// greedyClosest walks a single layer towards the node most similar to query
+// This method is called with the lock already held.
+func (idx *HNSWIndex) greedyClosest(query []float32, entry, level int) int {
+	current := entry
+	currentScore := idx.similarity(query, int32(current)) //nolint:gosec
+
+	for changed := true; changed; {
+		changed = false
+		for _, neighbor := range idx.links[current][level] {
+			if score := idx.similarity(query, neighbor); score > currentScore {
+				current, currentScore = int(neighbor), score
+				changed = true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer performs a best-first beam search of width ef on one layer
+// Returns candidates sorted by score in descending order.
+// This method is called with the lock already held.
+func (idx *HNSWIndex) searchLayer(query []float32, entries []int32, ef, level int) []hnswCandidate {
+	visited := make(map[int32]struct{}, ef*4)
+	frontier := &hnswMaxHeap{}
+	found := &hnswMinHeap{}
+
+	for _, entry := range entries {
+		if _, seen := visited[entry]; seen {
+			continue
+		}
+		visited[entry] = struct{}{}
+		candidate := hnswCandidate{node: entry, score: idx.similarity(query, entry)}
+		heap.Push(frontier, candidate)
+		heap.Push(found, candidate)
+		if found.Len() > ef {
+			heap.Pop(found)
+		}
+	}
+
+	for frontier.Len() > 0 {
+		current := heap.Pop(frontier).(hnswCandidate) //nolint:errcheck,forcetypeassert
+		if found.Len() >= ef && current.score < (*found)[0].score {
+			break // Every remaining candidate is worse than the worst result
+		}
+
+		if level >= len(idx.links[current.node]) {
+			continue
+		}
+		for _, neighbor := range idx.links[current.node][level] {
+			if _, seen := visited[neighbor]; seen {
+				continue
+			}
+			visited[neighbor] = struct{}{}
+
+			score := idx.similarity(query, neighbor)
+			if found.Len() < ef || score > (*found)[0].score {
+				candidate := hnswCandidate{node: neighbor, score: score}
+				heap.Push(frontier, candidate)
+				heap.Push(found, candidate)
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	results := make([]hnswCandidate, found.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(found).(hnswCandidate) //nolint:errcheck,forcetypeassert
+	}
+	return results
+}
+
+// selectNeighbors picks up to m diverse neighbors from candidates sorted by descending score
+// using the heuristic from the HNSW paper: a candidate is kept only if it is closer to the
+// base node than to any already selected neighbor. Remaining slots are filled with the
+// best pruned candidates so that nodes stay well connected.
+func (idx *HNSWIndex) selectNeighbors(candidates []hnswCandidate, m int) []int32 {
+	selected := make([]int32, 0, m)
+	pruned := make([]int32, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		if len(selected) >= m {
+			break
+		}
+
+		diverse := true
+		for _, chosen := range selected {
+			if DotProduct(idx.vectors[candidate.node], idx.vectors[chosen]) > candidate.score {
+				diverse = false
+				break
+			}
+		}
+
+		if diverse {
+			selected = append(selected, candidate.node)
+		} else {
+			pruned = append(pruned, candidate.node)
+		}
+	}
+
+	for _, node := range pruned {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, node)
+	}
+
+	return selected
+}
+
+// connect adds a link from node to neighbor on a layer, shrinking the neighbor list if needed
+// This method is called with the lock already held.
+func (idx *HNSWIndex) connect(node int, neighbor int32, level int) {
+	maxLinks := idx.config.M
+	if level == 0 {
+		maxLinks = 2 * idx.config.M
+	}
+
+	links := append(idx.links[node][level], neighbor)
+	if len(links) <= maxLinks {
+		idx.links[node][level] = links
+		return
+	}
+
+	// Too many links: keep the most similar, diverse subset
+	base := idx.vectors[node]
+	candidates := make([]hnswCandidate, len(links))
+	for i, link := range links {
+		candidates[i] = hnswCandidate{node: link, score: idx.similarity(base, link)}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	idx.links[node][level] = idx.selectNeighbors(candidates, maxLinks)
+}
+
+// hnswSnapshot is the serialized form of an HNSWIndex
+type hnswSnapshot struct {
+	Version    int
+	Config     HNSWConfig
+	Dimension  int
+	IDs        []string
+	Vectors    [][]float32
+	Links      [][][]int32
+	EntryPoint int
+	MaxLevel   int
+}
+
+// validate checks that the snapshot's graph only refers to existing nodes and levels, so
+// that searches on the loaded index cannot index out of range
+func (s *hnswSnapshot) validate() error {
+	n := len(s.IDs)
+	if len(s.Vectors) != n || len(s.Links) != n {
+		return fmt.Errorf("%w: inconsistent node counts", ErrInvalidIndexFormat)
+	}
+
+	if n == 0 {
+		if s.EntryPoint != -1 || s.MaxLevel != -1 {
+			return fmt.Errorf("%w: entry point in an empty index", ErrInvalidIndexFormat)
+		}
+		return nil
+	}
+	if s.EntryPoint < 0 || s.EntryPoint >= n || s.MaxLevel < 0 || len(s.Links[s.EntryPoint]) != s.MaxLevel+1 {
+		return fmt.Errorf("%w: invalid entry point %d at level %d", ErrInvalidIndexFormat, s.EntryPoint, s.MaxLevel)
+	}
+
+	seen := make(map[string]bool, n)
+	for node, id := range s.IDs {
+		if seen[id] {
+			return fmt.Errorf("%w: duplicate node ID %q", ErrInvalidIndexFormat, id)
+		}
+		seen[id] = true
+
+		if len(s.Vectors[node]) != s.Dimension {
+			return fmt.Errorf("%w: node %d has dimension %d, expected %d",
+				ErrInvalidIndexFormat, node, len(s.Vectors[node]), s.Dimension)
+		}
+
+		levels := s.Links[node]
+		if len(levels) == 0 || len(levels) > s.MaxLevel+1 {
+			return fmt.Errorf("%w: node %d has %d levels, max level is %d",
+				ErrInvalidIndexFormat, node, len(levels), s.MaxLevel)
+		}
+		// A neighbor at a level must exist and reach that level
+		for level, neighbors := range levels {
+			for _, neighbor := range neighbors {
+				if neighbor < 0 || int(neighbor) >= n || len(s.Links[neighbor]) <= level {
+					return fmt.Errorf("%w: node %d links to invalid node %d at level %d",
+						ErrInvalidIndexFormat, node, neighbor, level)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Save writes the index to w in a binary (gob) format
+func (idx *HNSWIndex) Save(w io.Writer) error {
+	idx.mtx.RLock()
+	defer idx.mtx.RUnlock()
+
+	snapshot := hnswSnapshot{
+		Version:    hnswFormatVersion,
+		Config:     idx.config,
+		Dimension:  idx.dimension,
+		IDs:        idx.ids,
+		Vectors:    idx.vectors,
+		Links:      idx.links,
+		EntryPoint: idx.entryPoint,
+		MaxLevel:   idx.maxLevel,
+	}
+
+	if err := gob.NewEncoder(w).Encode(&snapshot); err != nil {
+		return fmt.Errorf("failed to encode HNSW index: %w", err)
+	}
+	return nil
+}
+
+// SaveToFile writes the index to a file, replacing any existing file
+func (idx *HNSWIndex) SaveToFile(path string) error {
+	file, err := os.Create(path) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to create index file: %w", err)
+	}
+
+	if err := idx.Save(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadHNSWIndex reads an index previously written with Save
+// The loaded index accepts further inserts.
+func LoadHNSWIndex(r io.Reader) (*HNSWIndex, error) {
+	var snapshot hnswSnapshot
+	if err := gob.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode HNSW index: %w", ErrInvalidIndexFormat, err)
+	}
+
+	if snapshot.Version != hnswFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidIndexFormat, snapshot.Version)
+	}
+	if err := snapshot.validate(); err != nil {
+		return nil, err
+	}
+
+	index, err := NewHNSWIndex(snapshot.Dimension, snapshot.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	index.ids = snapshot.IDs
+	index.vectors = snapshot.Vectors
+	index.links = snapshot.Links
+	index.entryPoint = snapshot.EntryPoint
+	index.maxLevel = snapshot.MaxLevel
+	for i, id := range index.ids {
+		index.idIndex[id] = i
+	}
+
+	// Advance the level generator so new inserts don't replay the saved levels
+	for range index.ids {
+		index.rng.Float64()
+	}
+
+	return index, nil
+}
+
+// LoadHNSWIndexFromFile reads an index from a file written with SaveToFile
+func LoadHNSWIndexFromFile(path string) (*HNSWIndex, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index file: %w", err)
+	}
+	defer file.Close()
+
+	return LoadHNSWIndex(file)
+}
+
+// hnswCandidate is a node paired with its similarity to the current query
+type hnswCandidate struct {
+	node  int32
+	score float64
+}
+
+// hnswMaxHeap pops the most similar candidate first
+type hnswMaxHeap []hnswCandidate
+
+func (h hnswMaxHeap) Len() int { return len(h) }
+
+func (h hnswMaxHeap) Less(i, j int) bool { return h[i].score > h[j].score }
+
+func (h hnswMaxHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *hnswMaxHeap) Push(x any) { *h = append(*h, x.(hnswCandidate)) } //nolint:errcheck,forcetypeassert
+
+func (h *hnswMaxHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// hnswMinHeap pops the least similar candidate first
+type hnswMinHeap []hnswCandidate
+
+func (h hnswMinHeap) Len() int { return len(h) }
+
+func (h hnswMinHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+
+func (h hnswMinHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *hnswMinHeap) Push(x any) { *h = append(*h, x.(hnswCandidate)) } //nolint:errcheck,forcetypeassert
+
+func (h *hnswMinHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,323 @@
+package semanticmatcher
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomVectors generates n reproducible random vectors of the given dimension
+func randomVectors(n, dimension int, seed int64) [][]float32 {
+	rng := rand.New(rand.NewSource(seed)) //nolint:gosec
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		vectors[i] = make([]float32, dimension)
+		for j := range vectors[i] {
+			vectors[i][j] = float32(rng.NormFloat64())
+		}
+	}
+	return vectors
+}
+
+// bruteForceTopK returns the indices of the k best candidates according to BatchSimilarity
+func bruteForceTopK(query []float32, candidates [][]float32, k int) []int {
+	scores := NewSimilarityCalculator().BatchSimilarity(query, candidates)
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+	return order[:k]
+}
+
+func buildTestIndex(t testing.TB, vectors [][]float32, config HNSWConfig) *HNSWIndex {
+	index, err := NewHNSWIndex(len(vectors[0]), config)
+	require.NoError(t, err)
+	for i, vector := range vectors {
+		require.NoError(t, index.Add(fmt.Sprintf("v%d", i), vector))
+	}
+	return index
+}
+
+func TestHNSWIndex_RecallAgainstBruteForce(t *testing.T) {
+	const (
+		n         = 3000
+		dimension = 32
+		queries   = 100
+		k         = 10
+	)
+
+	vectors := randomVectors(n, dimension, 1)
+	queryVectors := randomVectors(queries, dimension, 2)
+	index := buildTestIndex(t, vectors, DefaultHNSWConfig())
+	require.Equal(t, n, index.Len())
+
+	hits := 0
+	for _, query := range queryVectors {
+		expected := make(map[string]struct{}, k)
+		for _, i := range bruteForceTopK(query, vectors, k) {
+			expected[fmt.Sprintf("v%d", i)] = struct{}{}
+		}
+
+		results := index.SearchWithEf(query, k, 100)
+		require.Len(t, results, k)
+		for _, result := range results {
+			if _, ok := expected[result.ID]; ok {
+				hits++
+			}
+		}
+	}
+
+	recall := float64(hits) / float64(queries*k)
+	t.Logf("HNSW recall@%d: %.4f", k, recall)
+	assert.GreaterOrEqual(t, recall, 0.95)
+}
+
+func TestHNSWIndex_ScoresMatchCosine(t *testing.T) {
+	vectors := randomVectors(200, 16, 3)
+	index := buildTestIndex(t, vectors, DefaultHNSWConfig())
+	calc := NewSimilarityCalculator()
+
+	query := vectors[7]
+	results := index.Search(query, 5)
+	require.NotEmpty(t, results)
+
+	// The query itself is the best match
+	assert.Equal(t, "v7", results[0].ID)
+	assert.InDelta(t, 1.0, results[0].Score, 1e-5)
+
+	for i, result := range results {
+		var id int
+		_, err := fmt.Sscanf(result.ID, "v%d", &id)
+		require.NoError(t, err)
+		assert.InDelta(t, calc.CosineSimilarity(query, vectors[id]), result.Score, 1e-5)
+		if i > 0 {
+			assert.GreaterOrEqual(t, results[i-1].Score, result.Score)
+		}
+	}
+}
+
+func TestHNSWIndex_DotMetric(t *testing.T) {
+	config := DefaultHNSWConfig()
+	config.Metric = HNSWDot
+
+	index, err := NewHNSWIndex(2, config)
+	require.NoError(t, err)
+	require.NoError(t, index.Add("short", []float32{1, 0}))
+	require.NoError(t, index.Add("long", []float32{10, 1}))
+	require.NoError(t, index.Add("opposite", []float32{-1, 0}))
+
+	results := index.Search([]float32{1, 0}, 3)
+	require.Len(t, results, 3)
+
+	// Dot product prefers the longer vector even though "short" has higher cosine
+	assert.Equal(t, "long", results[0].ID)
+	assert.InDelta(t, 10.0, results[0].Score, 1e-6)
+	assert.Equal(t, "opposite", results[2].ID)
+}
+
+func TestHNSWIndex_IncrementalInserts(t *testing.T) {
+	vectors := randomVectors(500, 16, 4)
+	index := buildTestIndex(t, vectors[:250], DefaultHNSWConfig())
+
+	for i := 250; i < len(vectors); i++ {
+		require.NoError(t, index.Add(fmt.Sprintf("v%d", i), vectors[i]))
+	}
+	assert.Equal(t, 500, index.Len())
+
+	results := index.Search(vectors[400], 1)
+	require.Len(t, results, 1)
+	assert.Equal(t, "v400", results[0].ID)
+}
+
+func TestHNSWIndex_AddErrors(t *testing.T) {
+	index, err := NewHNSWIndex(3, DefaultHNSWConfig())
+	require.NoError(t, err)
+
+	require.NoError(t, index.Add("a", []float32{1, 2, 3}))
+	require.ErrorIs(t, index.Add("a", []float32{1, 2, 3}), ErrDuplicateID)
+	require.ErrorIs(t, index.Add("b", []float32{1, 2}), ErrDimensionMismatch)
+	require.ErrorIs(t, index.Add("c", []float32{0, 0, 0}), ErrEmptyInput)
+	require.ErrorIs(t, index.Add("", []float32{1, 2, 3}), ErrEmptyInput)
+
+	assert.Empty(t, index.Search([]float32{1, 2}, 1))
+	assert.Empty(t, index.Search([]float32{1, 2, 3}, 0))
+}
+
+func TestHNSWIndex_InvalidConfig(t *testing.T) {
+	_, err := NewHNSWIndex(0, DefaultHNSWConfig())
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	_, err = NewHNSWIndex(3, HNSWConfig{M: 1})
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	_, err = NewHNSWIndex(3, HNSWConfig{Metric: HNSWMetric(9)})
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	// Zero-valued graph parameters fall back to defaults
+	index, err := NewHNSWIndex(3, HNSWConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultHNSWM, index.Config().M)
+	assert.Equal(t, DefaultHNSWEfConstruction, index.Config().EfConstruction)
+	assert.Equal(t, DefaultHNSWEfSearch, index.Config().EfSearch)
+}
+
+func TestHNSWIndex_SaveAndLoad(t *testing.T) {
+	vectors := randomVectors(300, 16, 5)
+	index := buildTestIndex(t, vectors, DefaultHNSWConfig())
+
+	path := filepath.Join(t.TempDir(), "index.hnsw")
+	require.NoError(t, index.SaveToFile(path))
+
+	loaded, err := LoadHNSWIndexFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, index.Len(), loaded.Len())
+	assert.Equal(t, index.Config(), loaded.Config())
+
+	for _, query := range randomVectors(10, 16, 6) {
+		assert.Equal(t, index.Search(query, 5), loaded.Search(query, 5))
+	}
+
+	// A loaded index accepts further inserts
+	require.NoError(t, loaded.Add("extra", []float32{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}))
+	assert.True(t, loaded.Contains("extra"))
+}
+
+func TestHNSWIndex_LoadInvalidData(t *testing.T) {
+	_, err := LoadHNSWIndex(bytes.NewReader([]byte("not an index")))
+	require.ErrorIs(t, err, ErrInvalidIndexFormat)
+}
+
+func TestHNSWIndex_LoadTamperedSnapshot(t *testing.T) {
+	index := buildTestIndex(t, randomVectors(50, 4, 7), DefaultHNSWConfig())
+	var saved bytes.Buffer
+	require.NoError(t, index.Save(&saved))
+
+	for name, tamper := range map[string]func(s *hnswSnapshot){
+		"none":                  func(*hnswSnapshot) {},
+		"neighbor out of range": func(s *hnswSnapshot) { s.Links[3][0] = append(s.Links[3][0], 50) },
+		"negative neighbor":     func(s *hnswSnapshot) { s.Links[3][0][0] = -1 },
+		"entry point out of range": func(s *hnswSnapshot) {
+			s.EntryPoint = len(s.IDs)
+		},
+		"max level above entry point": func(s *hnswSnapshot) { s.MaxLevel++ },
+		"node above max level": func(s *hnswSnapshot) {
+			s.Links[1] = append(s.Links[1], make([][]int32, s.MaxLevel+1)...)
+		},
+		"neighbor below level": func(s *hnswSnapshot) {
+			entry := s.Links[s.EntryPoint]
+			for node, levels := range s.Links {
+				if len(levels) == 1 {
+					entry[len(entry)-1] = append(entry[len(entry)-1], int32(node))
+					return
+				}
+			}
+		},
+		"truncated vector":     func(s *hnswSnapshot) { s.Vectors[2] = s.Vectors[2][:3] },
+		"node without levels":  func(s *hnswSnapshot) { s.Links[4] = nil },
+		"duplicate ID":         func(s *hnswSnapshot) { s.IDs[1] = s.IDs[0] },
+		"entry point in empty": func(s *hnswSnapshot) { *s = hnswSnapshot{Version: s.Version, Dimension: 4} },
+	} {
+		var snapshot hnswSnapshot
+		require.NoError(t, gob.NewDecoder(bytes.NewReader(saved.Bytes())).Decode(&snapshot))
+		tamper(&snapshot)
+		var tampered bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&tampered).Encode(&snapshot))
+
+		_, err := LoadHNSWIndex(&tampered)
+		if name == "none" {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidIndexFormat, name)
+		}
+	}
+}
+
+func TestBuildVocabularyIndex(t *testing.T) {
+	model := createTestVectorModel()
+
+	index, err := BuildVocabularyIndex(model, DefaultHNSWConfig())
+	require.NoError(t, err)
+	assert.Equal(t, model.VocabularySize(), index.Len())
+
+	query, ok := model.GetVector("测试")
+	require.True(t, ok)
+
+	results := index.Search(query, 3)
+	require.Len(t, results, 3)
+	assert.Equal(t, "测试", results[0].ID)
+}
+
+func TestBuildVocabularyIndex_LayeredModel(t *testing.T) {
+	domain := NewVectorModel(3).(*vectorModel) //nolint:errcheck,forcetypeassert
+	domain.AddVector("测试", []float32{-1, 0, 0})
+	domain.AddVector("部署", []float32{0, 0, 1})
+	model, err := NewLayeredVectorModel(
+		VectorLayer{Name: "domain", Model: domain},
+		VectorLayer{Name: "base", Model: createTestVectorModel()},
+	)
+	require.NoError(t, err)
+
+	index, err := BuildVocabularyIndex(model, DefaultHNSWConfig())
+	require.NoError(t, err)
+	assert.Equal(t, model.VocabularySize(), index.Len())
+
+	// Shadowed words are indexed with the vector of the highest layer
+	results := index.Search([]float32{-1, 0, 0}, 1)
+	require.Len(t, results, 1)
+	assert.Equal(t, "测试", results[0].ID)
+	assert.InDelta(t, 1.0, results[0].Score, 1e-6)
+	assert.True(t, index.Contains("部署"))
+}
+
+func TestBuildVocabularyIndex_SkipsZeroVectors(t *testing.T) {
+	model := NewVectorModel(3).(*vectorModel) //nolint:errcheck,forcetypeassert
+	model.AddVector("测试", []float32{1, 0, 0})
+	model.AddVector("空白", []float32{0, 0, 0})
+	model.AddVector("部署", []float32{0, 0, 1})
+
+	index, err := BuildVocabularyIndex(model, DefaultHNSWConfig())
+	require.NoError(t, err)
+	assert.Equal(t, 2, index.Len())
+	assert.False(t, index.Contains("空白"))
+	assert.True(t, index.Contains("部署"))
+}
+
+func TestHNSWIndex_TextVectorsFromMatcher(t *testing.T) {
+	matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(), NewSimilarityCalculator())
+
+	index, err := NewHNSWIndex(3, DefaultHNSWConfig())
+	require.NoError(t, err)
+
+	texts := []string{"测试段落", "关键词文本", "第一个", "第二个"}
+	for _, text := range texts {
+		vector, ok := matcher.VectorizeText(text)
+		require.True(t, ok)
+		require.NoError(t, index.Add(text, vector))
+	}
+
+	query, ok := matcher.VectorizeText("关键词文本")
+	require.True(t, ok)
+
+	results := index.Search(query, 1)
+	require.Len(t, results, 1)
+	assert.Equal(t, "关键词文本", results[0].ID)
+}
+
+func BenchmarkHNSWIndex_Search(b *testing.B) {
+	vectors := randomVectors(10000, 64, 7)
+	index := buildTestIndex(b, vectors, DefaultHNSWConfig())
+	queries := randomVectors(100, 64, 8)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index.Search(queries[i%len(queries)], 10)
+	}
+}
@@ -0,0 +1,222 @@
+package semanticmatcher
+
+import (
+	"math"
+	"slices"
+)
+
+// Fusion methods of HybridConfig
+const (
+	FusionWeightedSum = "weighted_sum" // LexicalWeight * lexical + SemanticWeight * semantic
+	FusionRRF         = "rrf"          // Reciprocal rank fusion: Σ weight / (RRFConstant + rank)
+)
+
+const (
+	// DefaultLexicalWeight is the weight of the lexical score if neither weight is set
+	DefaultLexicalWeight = 0.5
+
+	// DefaultSemanticWeight is the weight of the semantic score if neither weight is set
+	DefaultSemanticWeight = 0.5
+
+	// DefaultRRFConstant damps the influence of top ranks in reciprocal rank fusion
+	DefaultRRFConstant = 60.0
+
+	// DefaultBM25K1 controls how fast repeated keyword terms in the paragraph saturate
+	DefaultBM25K1 = 1.2
+)
+
+// HybridConfig fuses a lexical BM25 score of the keyword tokens in the paragraph with the
+// semantic score in FindTopKeywords, so exact mentions count even if their vectors do not
+// Both scores are reported in KeywordMatch.Components.
+type HybridConfig struct {
+	// Fusion is FusionWeightedSum or FusionRRF; empty disables hybrid scoring
+	Fusion string `mapstructure:"fusion"`
+
+	// LexicalWeight and SemanticWeight weigh the two scores; if both are zero,
+	// DefaultLexicalWeight and DefaultSemanticWeight are used. Weighted sums are divided by the
+	// sum of the weights except in ScoreModeRaw, so only the ratio of the weights matters.
+	LexicalWeight  float64 `mapstructure:"lexical_weight"`
+	SemanticWeight float64 `mapstructure:"semantic_weight"`
+
+	// RRFConstant is the k in weight / (k + rank) of FusionRRF; zero uses DefaultRRFConstant
+	RRFConstant float64 `mapstructure:"rrf_constant"`
+
+	// BM25K1 is the term frequency saturation of BM25; zero uses DefaultBM25K1
+	BM25K1 float64 `mapstructure:"bm25_k1"`
+}
+
+// ScoreComponents reports the parts of a hybrid keyword score
+type ScoreComponents struct {
+	Semantic     float64 `json:"semantic"`      // Score of the similarity metric in the score mode
+	Lexical      float64 `json:"lexical"`       // Normalized BM25 score of the keyword in the paragraph, in [0, 1)
+	SemanticRank int     `json:"semantic_rank"` // 1-based rank among the keywords by semantic score; ties share a rank
+	LexicalRank  int     `json:"lexical_rank"`  // 1-based rank among the keywords by lexical score; ties share a rank
+}
+
+// WithHybridScoring fuses lexical and semantic keyword scores in FindTopKeywordsWithOptions
+// with hybrid instead of the matcher's configured hybrid scoring; an empty Fusion disables it.
+// It applies to pooled vector metrics only; token alignment, token set and word vector metrics
+// and other methods ignore it. Fused scores follow the score mode, except that
+// ScoreModeCalibrated clamps: a fused score is not a probability.
+func WithHybridScoring(hybrid HybridConfig) MatchOption {
+	return func(o *matchOptions) {
+		o.hybrid = hybrid
+	}
+}
+
+// enabled reports whether hybrid scoring is on
+func (h HybridConfig) enabled() bool {
+	return h.Fusion != ""
+}
+
+// withDefaults returns h with zero parameters replaced by their defaults
+func (h HybridConfig) withDefaults() HybridConfig {
+	if h.LexicalWeight == 0 && h.SemanticWeight == 0 {
+		h.LexicalWeight, h.SemanticWeight = DefaultLexicalWeight, DefaultSemanticWeight
+	}
+	if h.RRFConstant == 0 {
+		h.RRFConstant = DefaultRRFConstant
+	}
+	if h.BM25K1 == 0 {
+		h.BM25K1 = DefaultBM25K1
+	}
+	return h
+}
+
+// validateHybrid checks the fusion method and that no parameter is negative
+func validateHybrid(hybrid HybridConfig) error {
+	switch hybrid.Fusion {
+	case "", FusionWeightedSum, FusionRRF:
+	default:
+		return ErrInvalidConfiguration
+	}
+
+	if hybrid.LexicalWeight < 0 || hybrid.SemanticWeight < 0 || hybrid.RRFConstant < 0 || hybrid.BM25K1 < 0 {
+		return ErrInvalidConfiguration
+	}
+	return nil
+}
+
+// lexicalScore returns the BM25 score of the keyword tokens in a paragraph with the given term
+// frequencies, divided by its limit for infinitely frequent terms so that it lies in [0, 1)
+// Length normalization is left out (b = 0): all keywords are scored against the same paragraph.
+// Terms are weighted by idf if it is not nil, otherwise equally.
+func lexicalScore(keywordTokens []string, termFrequency map[string]int, idf *IDFModel, k1 float64) float64 {
+	var score, limit float64
+	for _, token := range keywordTokens {
+		weight := 1.0
+		if idf != nil {
+			weight = idf.IDF(token)
+		}
+		limit += weight * (k1 + 1)
+
+		if tf := float64(termFrequency[token]); tf > 0 {
+			score += weight * tf * (k1 + 1) / (tf + k1)
+		}
+	}
+	if limit == 0 {
+		return 0.0
+	}
+	return score / limit
+}
+
+// fuseScores replaces the semantic score of each match with its hybrid score and records both
+// components; keywordTokens holds the tokens of each match's keyword
+// BM25 terms are weighted by the IDF model of "tfidf" weighting if weighting is one.
+func fuseScores(
+	matches []KeywordMatch,
+	keywordTokens [][]string,
+	paragraphTokens []string,
+	hybrid HybridConfig,
+	weighting TokenWeighting,
+	mapping scoreMapping,
+) {
+	hybrid = hybrid.withDefaults()
+	idf, _ := weighting.(*IDFModel) //nolint:errcheck
+
+	termFrequency := make(map[string]int, len(paragraphTokens))
+	for _, token := range paragraphTokens {
+		termFrequency[token]++
+	}
+
+	semantic := make([]float64, len(matches))
+	lexical := make([]float64, len(matches))
+	for i := range matches {
+		semantic[i] = matches[i].Score
+		lexical[i] = lexicalScore(keywordTokens[i], termFrequency, idf, hybrid.BM25K1)
+	}
+	semanticRanks, lexicalRanks := competitionRanks(semantic), competitionRanks(lexical)
+
+	for i := range matches {
+		matches[i].Components = &ScoreComponents{
+			Semantic:     semantic[i],
+			Lexical:      lexical[i],
+			SemanticRank: semanticRanks[i],
+			LexicalRank:  lexicalRanks[i],
+		}
+
+		var score float64
+		switch hybrid.Fusion {
+		case FusionRRF:
+			score = hybrid.SemanticWeight/(hybrid.RRFConstant+float64(semanticRanks[i])) +
+				hybrid.LexicalWeight/(hybrid.RRFConstant+float64(lexicalRanks[i]))
+		default:
+			score = hybrid.SemanticWeight*semantic[i] + hybrid.LexicalWeight*lexical[i]
+		}
+		matches[i].Score = mapping.fusedScore(score, hybrid)
+	}
+}
+
+// fusedScore maps a fused score into the score mode. The semantic part of a weighted sum is
+// already mapped, and the sum is divided by the sum of the weights in every mode but
+// ScoreModeRaw so that it stays in [0, 1] like the semantic score, unless the metric is
+// unbounded. Reciprocal rank fusion depends on ranks only and is already in [0, 1];
+// ScoreModeAffine divides it by its largest value so that the best keyword scores 1.
+func (m scoreMapping) fusedScore(score float64, hybrid HybridConfig) float64 {
+	if m.mode == ScoreModeRaw {
+		return score
+	}
+
+	maximum := hybrid.SemanticWeight + hybrid.LexicalWeight
+	if hybrid.Fusion == FusionRRF {
+		if m.mode != ScoreModeAffine {
+			return score
+		}
+		maximum /= hybrid.RRFConstant + 1
+	} else if lower, upper := m.metric.Range(); math.IsInf(lower, 0) || math.IsInf(upper, 0) {
+		return score
+	}
+	if maximum <= 0 {
+		return score
+	}
+	return score / maximum
+}
+
+// competitionRanks returns the 1-based rank of each score in descending order, where equal
+// scores share the best rank of their group ("1224" ranking)
+func competitionRanks(scores []float64) []int {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	ranks := make([]int, len(scores))
+	for position, idx := range order {
+		if position > 0 && scores[idx] == scores[order[position-1]] {
+			ranks[idx] = ranks[order[position-1]]
+			continue
+		}
+		ranks[idx] = position + 1
+	}
+	return ranks
+}
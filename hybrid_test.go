@@ -0,0 +1,142 @@
+package semanticmatcher
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHybridTestMatcher() SemanticMatcher {
+	model := NewVectorModel(3).(*vectorModel) //nolint:errcheck,forcetypeassert
+	model.AddVector("cluster", []float32{1, 0, 0})
+	model.AddVector("docker", []float32{1, 0.1, 0})
+	model.AddVector("kubernetes", []float32{0, 1, 0})
+	model.AddVector("report", []float32{0, 0, 1})
+	return NewSemanticMatcher(NewTextProcessor(), model, NewSimilarityCalculator())
+}
+
+func TestWithHybridScoring_ExactMention(t *testing.T) {
+	matcher := newHybridTestMatcher()
+	paragraph := "kubernetes cluster report"
+	keywords := []string{"docker", "kubernetes"}
+
+	semantic := matcher.FindTopKeywords(paragraph, keywords, 0)
+	require.Len(t, semantic, 2)
+	assert.Equal(t, "docker", semantic[0].Keyword)
+	assert.Nil(t, semantic[0].Components)
+
+	for _, hybrid := range []HybridConfig{
+		{Fusion: FusionWeightedSum},
+		{Fusion: FusionRRF, LexicalWeight: 0.7, SemanticWeight: 0.3},
+	} {
+		matches := matcher.FindTopKeywordsWithOptions(paragraph, keywords, 0, WithHybridScoring(hybrid))
+		require.Len(t, matches, 2, hybrid.Fusion)
+		assert.Equal(t, "kubernetes", matches[0].Keyword, hybrid.Fusion)
+
+		components := matches[0].Components
+		require.NotNil(t, components, hybrid.Fusion)
+		assert.InDelta(t, semantic[1].Score, components.Semantic, 1e-6)
+		assert.InDelta(t, 1/(1+DefaultBM25K1), components.Lexical, 1e-9)
+		assert.Equal(t, 2, components.SemanticRank)
+		assert.Equal(t, 1, components.LexicalRank)
+
+		require.NotNil(t, matches[1].Components)
+		assert.Zero(t, matches[1].Components.Lexical)
+	}
+
+	weighted := matcher.FindTopKeywordsWithOptions(paragraph, []string{"kubernetes"}, 0,
+		WithHybridScoring(HybridConfig{Fusion: FusionWeightedSum, LexicalWeight: 1, SemanticWeight: 2}))
+	require.Len(t, weighted, 1)
+	assert.InDelta(t, (2*semantic[1].Score+1/(1+DefaultBM25K1))/3, weighted[0].Score, 1e-6)
+}
+
+func TestWithHybridScoring_OOVKeyword(t *testing.T) {
+	matcher := newHybridTestMatcher()
+
+	// Keywords without vectors can still match lexically
+	matches := matcher.FindTopKeywordsWithOptions("helm cluster report", []string{"helm", "docker"}, 1,
+		WithHybridScoring(HybridConfig{Fusion: FusionWeightedSum, LexicalWeight: 1, SemanticWeight: 0.1}))
+	require.Len(t, matches, 1)
+	assert.Equal(t, "helm", matches[0].Keyword)
+	assert.Equal(t, 1, matches[0].OOVCount)
+}
+
+func TestWithHybridScoring_ConfigDefault(t *testing.T) {
+	matcher := newHybridTestMatcher().(*semanticMatcher) //nolint:errcheck,forcetypeassert
+	matcher.hybrid = HybridConfig{Fusion: FusionWeightedSum}
+
+	matches := matcher.FindTopKeywords("kubernetes cluster report", []string{"docker", "kubernetes"}, 0)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "kubernetes", matches[0].Keyword)
+
+	// An empty fusion disables the configured hybrid scoring for one call
+	matches = matcher.FindTopKeywordsWithOptions("kubernetes cluster report", []string{"docker", "kubernetes"}, 0,
+		WithHybridScoring(HybridConfig{}))
+	require.Len(t, matches, 2)
+	assert.Equal(t, "docker", matches[0].Keyword)
+	assert.Nil(t, matches[0].Components)
+}
+
+func TestLexicalScore(t *testing.T) {
+	termFrequency := map[string]int{"market": 2, "report": 1}
+
+	// One of two equally weighted terms, seen once
+	assert.InDelta(t, 0.5/(1+DefaultBM25K1), lexicalScore([]string{"report", "weather"}, termFrequency, nil, DefaultBM25K1), 1e-9)
+
+	// Repeated terms saturate below 1
+	twice := lexicalScore([]string{"market"}, termFrequency, nil, DefaultBM25K1)
+	assert.InDelta(t, 2/(2+DefaultBM25K1), twice, 1e-9)
+	assert.Less(t, twice, 1.0)
+
+	assert.Zero(t, lexicalScore(nil, termFrequency, nil, DefaultBM25K1))
+
+	// Rare terms weigh more with an IDF model
+	idf := FitIDFModel(NewTextProcessor(), slices.Values(idfTestCorpus))
+	weighted := lexicalScore([]string{"report", "weather"}, map[string]int{"weather": 1}, idf, DefaultBM25K1)
+	unweighted := lexicalScore([]string{"report", "weather"}, map[string]int{"weather": 1}, nil, DefaultBM25K1)
+	assert.Greater(t, weighted, unweighted)
+}
+
+func TestCompetitionRanks(t *testing.T) {
+	assert.Equal(t, []int{2, 1, 2, 4}, competitionRanks([]float64{0.5, 0.9, 0.5, 0.1}))
+	assert.Empty(t, competitionRanks(nil))
+}
+
+func TestValidateHybrid(t *testing.T) {
+	assert.NoError(t, validateHybrid(HybridConfig{}))
+	assert.NoError(t, validateHybrid(HybridConfig{Fusion: FusionRRF, RRFConstant: 10}))
+	assert.ErrorIs(t, validateHybrid(HybridConfig{Fusion: "max"}), ErrInvalidConfiguration)
+	assert.ErrorIs(t, validateHybrid(HybridConfig{Fusion: FusionWeightedSum, LexicalWeight: -1}), ErrInvalidConfiguration)
+	assert.ErrorIs(t, validateHybrid(HybridConfig{BM25K1: -0.5}), ErrInvalidConfiguration)
+}
+
+func TestWithHybridScoring_NonUnitWeights(t *testing.T) {
+	matcher := newHybridTestMatcher()
+	paragraph := "kubernetes cluster report"
+	keywords := []string{"docker", "kubernetes", "report"}
+
+	unit := HybridConfig{Fusion: FusionWeightedSum, LexicalWeight: 0.5, SemanticWeight: 0.5}
+	double := HybridConfig{Fusion: FusionWeightedSum, LexicalWeight: 1, SemanticWeight: 1}
+
+	for _, mode := range []string{ScoreModeClamped, ScoreModeAffine, ScoreModeRaw} {
+		expected := matcher.FindTopKeywordsWithOptions(paragraph, keywords, 0,
+			WithScoreMode(mode), WithHybridScoring(unit))
+		matches := matcher.FindTopKeywordsWithOptions(paragraph, keywords, 0,
+			WithScoreMode(mode), WithHybridScoring(double))
+		require.Len(t, matches, 3, mode)
+
+		for i, match := range matches {
+			assert.Equal(t, expected[i].Keyword, match.Keyword, mode)
+			if mode == ScoreModeRaw {
+				// Raw scores are not rescaled
+				assert.InDelta(t, 2*expected[i].Score, match.Score, 1e-6, mode)
+				continue
+			}
+			assert.InDelta(t, expected[i].Score, match.Score, 1e-6, mode)
+			assert.LessOrEqual(t, match.Score, 1.0, mode)
+			assert.GreaterOrEqual(t, match.Score, 0.0, mode)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package semanticmatcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WeightingTFIDF is the Config.TokenWeighting name for IDF weighting with an IDFModel
+const WeightingTFIDF = "tfidf"
+
+// DefaultIDFBatchSize is the number of texts preprocessed per PreprocessBatch call when fitting
+const DefaultIDFBatchSize = 1000
+
+// IDFModel holds document frequencies fitted on a domain corpus and weighs tokens by their
+// smoothed inverse document frequency, idf(t) = ln((1 + N) / (1 + df(t))) + 1.
+// Repeated tokens are pooled once per occurrence, so IDF weighting of the token vectors
+// gives TF-IDF weighted pooling. An IDFModel is immutable and safe for concurrent use.
+type IDFModel struct {
+	documentFrequency map[string]int
+	documents         int
+	defaultIDF        float64 // IDF of tokens unseen in the corpus; 0 uses the smoothed IDF of df = 0
+}
+
+// FitIDFModel counts the document frequency of every token in the corpus, preprocessing
+// the texts with processor.PreprocessBatch in batches of DefaultIDFBatchSize
+func FitIDFModel(processor TextProcessor, corpus iter.Seq[string]) *IDFModel {
+	model := &IDFModel{documentFrequency: make(map[string]int)}
+
+	batch := make([]string, 0, DefaultIDFBatchSize)
+	flush := func() {
+		for _, tokens := range processor.PreprocessBatch(batch) {
+			model.addDocument(tokens)
+		}
+		batch = batch[:0]
+	}
+
+	for text := range corpus {
+		batch = append(batch, text)
+		if len(batch) >= DefaultIDFBatchSize {
+			flush()
+		}
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+
+	return model
+}
+
+// addDocument counts each distinct token of one document
+func (m *IDFModel) addDocument(tokens []string) {
+	m.documents++
+
+	seen := make(map[string]struct{}, len(tokens))
+	for _, token := range tokens {
+		if _, exists := seen[token]; exists {
+			continue
+		}
+		seen[token] = struct{}{}
+		m.documentFrequency[token]++
+	}
+}
+
+// WithDefaultIDF returns a model sharing m's frequencies whose unseen tokens get idf
+// A zero idf restores the smoothed IDF of a token with document frequency 0.
+func (m *IDFModel) WithDefaultIDF(idf float64) *IDFModel {
+	return &IDFModel{
+		documentFrequency: m.documentFrequency,
+		documents:         m.documents,
+		defaultIDF:        idf,
+	}
+}
+
+// DocumentCount returns the number of documents the model was fitted on
+func (m *IDFModel) DocumentCount() int {
+	return m.documents
+}
+
+// DocumentFrequency returns the number of documents containing token
+func (m *IDFModel) DocumentFrequency(token string) int {
+	return m.documentFrequency[token]
+}
+
+// Len returns the number of distinct tokens in the model
+func (m *IDFModel) Len() int {
+	return len(m.documentFrequency)
+}
+
+// IDF returns the inverse document frequency of token, or the default IDF if it is unseen
+func (m *IDFModel) IDF(token string) float64 {
+	df, exists := m.documentFrequency[token]
+	if !exists && m.defaultIDF > 0 {
+		return m.defaultIDF
+	}
+	return math.Log(float64(1+m.documents)/float64(1+df)) + 1
+}
+
+// Name returns the weighting name
+func (m *IDFModel) Name() string {
+	return WeightingTFIDF
+}
+
+// Weight returns the IDF of token; frequency ranks are not used
+func (m *IDFModel) Weight(token string, _, _ int) float64 {
+	return m.IDF(token)
+}
+
+// WriteTo writes the model in a text format similar to .vec files: a "documents terms"
+// header followed by one "token document_frequency" line per token, most frequent first
+func (m *IDFModel) WriteTo(w io.Writer) (int64, error) {
+	tokens := make([]string, 0, len(m.documentFrequency))
+	for token := range m.documentFrequency {
+		tokens = append(tokens, token)
+	}
+	slices.SortFunc(tokens, func(a, b string) int {
+		if m.documentFrequency[a] != m.documentFrequency[b] {
+			return m.documentFrequency[b] - m.documentFrequency[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	writer := bufio.NewWriter(w)
+	var written int64
+
+	n, err := fmt.Fprintf(writer, "%d %d\n", m.documents, len(tokens))
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, token := range tokens {
+		n, err := fmt.Fprintf(writer, "%s %d\n", token, m.documentFrequency[token])
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, writer.Flush()
+}
+
+// SaveIDFModel writes model to path
+func SaveIDFModel(model *IDFModel, path string) error {
+	file, err := os.Create(path) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to create IDF model file: %w", err)
+	}
+
+	if _, err := model.WriteTo(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadIDFModel reads a model written by SaveIDFModel
+func LoadIDFModel(path string) (*IDFModel, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open IDF model file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadIDFModel(file)
+}
+
+// ReadIDFModel reads a model in the format written by IDFModel.WriteTo
+func ReadIDFModel(reader io.Reader) (*IDFModel, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: missing IDF header", ErrInvalidIDFFormat)
+	}
+
+	header := strings.Fields(scanner.Text())
+	if len(header) != 2 {
+		return nil, fmt.Errorf("%w: invalid IDF header %q", ErrInvalidIDFFormat, scanner.Text())
+	}
+	documents, err := strconv.Atoi(header[0])
+	if err != nil || documents < 0 {
+		return nil, fmt.Errorf("%w: invalid document count %q", ErrInvalidIDFFormat, header[0])
+	}
+	terms, err := strconv.Atoi(header[1])
+	if err != nil || terms < 0 {
+		return nil, fmt.Errorf("%w: invalid term count %q", ErrInvalidIDFFormat, header[1])
+	}
+
+	model := &IDFModel{
+		documentFrequency: make(map[string]int, terms),
+		documents:         documents,
+	}
+
+	lineNumber := 1
+	for scanner.Scan() {
+		lineNumber++
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 0 {
+			continue
+		}
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: line %d: expected \"token frequency\"", ErrInvalidIDFFormat, lineNumber)
+		}
+
+		df, err := strconv.Atoi(parts[1])
+		if err != nil || df < 0 || df > documents {
+			return nil, fmt.Errorf("%w: line %d: invalid document frequency %q",
+				ErrInvalidIDFFormat, lineNumber, parts[1])
+		}
+		model.documentFrequency[parts[0]] = df
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
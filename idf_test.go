@@ -0,0 +1,144 @@
+package semanticmatcher
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var idfTestCorpus = []string{
+	"market report stock",
+	"market report weather",
+	"market report rain rain",
+	"",
+}
+
+func TestFitIDFModel(t *testing.T) {
+	model := FitIDFModel(NewTextProcessor(), slices.Values(idfTestCorpus))
+
+	assert.Equal(t, 4, model.DocumentCount())
+	assert.Equal(t, 3, model.DocumentFrequency("market"))
+	assert.Equal(t, 1, model.DocumentFrequency("rain"), "repeated tokens count once per document")
+	assert.Equal(t, 0, model.DocumentFrequency("unseen"))
+	assert.Equal(t, 5, model.Len())
+
+	assert.InDelta(t, math.Log(5.0/4.0)+1, model.IDF("market"), 1e-12)
+	assert.InDelta(t, math.Log(5.0/2.0)+1, model.IDF("stock"), 1e-12)
+	assert.Less(t, model.IDF("report"), model.IDF("weather"))
+
+	// Unseen tokens get the smoothed IDF of df = 0 unless a default is configured
+	assert.InDelta(t, math.Log(5.0)+1, model.IDF("unseen"), 1e-12)
+	withDefault := model.WithDefaultIDF(2.5)
+	assert.InDelta(t, 2.5, withDefault.IDF("unseen"), 1e-12)
+	assert.InDelta(t, model.IDF("market"), withDefault.IDF("market"), 1e-12)
+	assert.InDelta(t, math.Log(5.0)+1, model.IDF("unseen"), 1e-12, "original model is unchanged")
+
+	assert.Equal(t, WeightingTFIDF, model.Name())
+	assert.InDelta(t, model.IDF("stock"), model.Weight("stock", 3, 10), 1e-12)
+}
+
+func TestFitIDFModel_Batches(t *testing.T) {
+	corpus := func(yield func(string) bool) {
+		for i := range DefaultIDFBatchSize + 10 {
+			text := "market"
+			if i%2 == 0 {
+				text += " stock"
+			}
+			if !yield(text) {
+				return
+			}
+		}
+	}
+
+	model := FitIDFModel(NewTextProcessor(), corpus)
+	assert.Equal(t, DefaultIDFBatchSize+10, model.DocumentCount())
+	assert.Equal(t, DefaultIDFBatchSize+10, model.DocumentFrequency("market"))
+	assert.Equal(t, (DefaultIDFBatchSize+10)/2, model.DocumentFrequency("stock"))
+}
+
+func TestIDFModel_SaveLoad(t *testing.T) {
+	model := FitIDFModel(NewTextProcessor(), slices.Values(idfTestCorpus))
+
+	var buf bytes.Buffer
+	written, err := model.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), written)
+	assert.True(t, strings.HasPrefix(buf.String(), "4 5\nmarket 3\nreport 3\n"), buf.String())
+
+	path := filepath.Join(t.TempDir(), "corpus.idf")
+	require.NoError(t, SaveIDFModel(model, path))
+	loaded, err := LoadIDFModel(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.DocumentCount(), loaded.DocumentCount())
+	assert.Equal(t, model.Len(), loaded.Len())
+	for _, token := range []string{"market", "report", "stock", "weather", "rain", "unseen"} {
+		assert.InDelta(t, model.IDF(token), loaded.IDF(token), 1e-12, token)
+	}
+
+	_, err = LoadIDFModel(filepath.Join(t.TempDir(), "missing.idf"))
+	assert.Error(t, err)
+}
+
+func TestReadIDFModel_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"4\n",
+		"x 1\n",
+		"4 1\nmarket\n",
+		"4 1\nmarket many\n",
+		"4 1\nmarket 5\n",
+	} {
+		_, err := ReadIDFModel(strings.NewReader(input))
+		assert.ErrorIs(t, err, ErrInvalidIDFFormat, input)
+	}
+}
+
+func TestSemanticMatcher_TFIDF(t *testing.T) {
+	model := NewVectorModel(3).(*vectorModel)
+	model.AddVector("market", []float32{1, 0, 0})
+	model.AddVector("report", []float32{0, 1, 0})
+	model.AddVector("weather", []float32{0, 0, 1})
+	matcher := NewSemanticMatcher(NewTextProcessor(), model, NewSimilarityCalculator())
+	idf := FitIDFModel(NewTextProcessor(), slices.Values(idfTestCorpus))
+
+	// The corpus-frequent "report" counts less, so the texts agree less on it
+	plain := matcher.ComputeSimilarity("report weather", "report market")
+	weighted := matcher.ComputeSimilarityWithOptions("report weather", "report market", WithTokenWeighting(idf))
+	assert.Less(t, weighted, plain)
+
+	// Repeated tokens weigh once per occurrence (TF-IDF)
+	vector, ok := matcher.VectorizeTextWithOptions("weather weather report", WithTokenWeighting(idf))
+	require.True(t, ok)
+	assert.InDelta(t, 2*idf.IDF("weather")/3, vector[2], 1e-6)
+	assert.InDelta(t, idf.IDF("report")/3, vector[1], 1e-6)
+}
+
+func TestNewSemanticMatcherFromConfig_TFIDF(t *testing.T) {
+	dir := t.TempDir()
+	vectorFile := filepath.Join(dir, "test.vec")
+	idfFile := filepath.Join(dir, "corpus.idf")
+	require.NoError(t, os.WriteFile(vectorFile, []byte(sifTestVectors), 0o644))
+	require.NoError(t, SaveIDFModel(FitIDFModel(NewTextProcessor(), slices.Values(idfTestCorpus)), idfFile))
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{vectorFile}
+	config.TokenWeighting = WeightingTFIDF
+	config.IDFModelPath = idfFile
+	config.IDFDefault = 3
+
+	matcher, err := NewSemanticMatcherFromConfig(config, DiscardLogger{})
+	require.NoError(t, err)
+
+	idf, ok := matcher.(*semanticMatcher).weighting.(*IDFModel)
+	require.True(t, ok)
+	assert.Equal(t, 4, idf.DocumentCount())
+	assert.InDelta(t, 3.0, idf.IDF("unseen"), 1e-12)
+}
@@ -0,0 +1,606 @@
+package semanticmatcher
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// BaseLayerName is the name of the layer loaded from Config.VectorFilePaths
+const BaseLayerName = "base"
+
+// VectorLayerConfig describes one layer of vectors stacked over the base vector files
+type VectorLayerConfig struct {
+	// Name identifies the layer, e.g. for SemanticMatcher.ReloadVectorLayer
+	Name string `mapstructure:"name"`
+
+	// VectorFilePaths are the .vec files of the layer, merged like Config.VectorFilePaths
+	VectorFilePaths []string `mapstructure:"vector_file_paths"`
+}
+
+// VectorLayer is a named model in a LayeredVectorModel
+type VectorLayer struct {
+	Name  string
+	Model VectorModel
+}
+
+// LayerStats reports the vocabulary and lookup counters of one layer
+type LayerStats struct {
+	Name           string `json:"name"`
+	VocabularySize int    `json:"vocabulary_size"`
+	Lookups        int64  `json:"lookups"` // Words looked up in this layer, i.e. missing from all layers above it
+	Hits           int64  `json:"hits"`    // Words found in this layer
+}
+
+// layerModel is implemented by the vector models of this package, which can be stacked in a
+// LayeredVectorModel
+type layerModel interface {
+	VectorModel
+
+	// lookupVector returns the vocabulary vector of a word and its frequency rank (0 if unranked)
+	// without fallback, statistics or copying
+	lookupVector(word string) ([]float32, int, bool)
+
+	// rankedWords returns the largest frequency rank recorded
+	rankedWords() int
+
+	// rangeVectors calls yield for every vocabulary word and its stored vector until yield returns false
+	rangeVectors(yield func(word string, vector []float32) bool)
+
+	// keyNormalizer returns the normalizer of vocabulary keys and lookups, nil if disabled
+	keyNormalizer() *Normalizer
+
+	// pooledVector is GetWeightedPooledVector that also returns the number of words without
+	// a vector, so that callers need no second lookup to count OOV words
+	pooledVector(words []string, pooling Pooling, weighting TokenWeighting) ([]float32, int, bool)
+}
+
+// stackedLayer is a layer of a LayeredVectorModel with its lookup counters
+type stackedLayer struct {
+	name    string
+	model   layerModel
+	lookups int64
+	hits    int64
+}
+
+// LayeredVectorModel stacks vector models in priority order, e.g. small domain-trained
+// vectors over generic wiki vectors. A word is taken from the first layer that has it;
+// fallback strategies only run when no layer has the word, and see the vocabulary of all
+// layers. Layers can be replaced while the model is in use.
+type LayeredVectorModel struct {
+	mtx        sync.RWMutex
+	layers     []*stackedLayer // Highest priority first
+	dimension  int
+	normalizer *Normalizer
+
+	vocabularySize int // Distinct words over all layers, counted when the stack changes
+
+	lookupState // Lookup statistics, OOV tracking and fallback chains of the stack, guarded by mtx
+}
+
+var _ layerModel = (*LayeredVectorModel)(nil)
+
+// NewLayeredVectorModel stacks the layers, highest priority first
+// Layers must be models of this package with the same dimension and distinct, non-empty names.
+func NewLayeredVectorModel(layers ...VectorLayer) (*LayeredVectorModel, error) {
+	if len(layers) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	lm := &LayeredVectorModel{
+		layers:      make([]*stackedLayer, 0, len(layers)),
+		dimension:   layers[0].Model.Dimension(),
+		lookupState: newLookupState(),
+	}
+	for _, layer := range layers {
+		if layer.Name == "" {
+			return nil, fmt.Errorf("%w: layer without a name", ErrInvalidConfiguration)
+		}
+		if _, exists := lm.layer(layer.Name); exists {
+			return nil, fmt.Errorf("%w: duplicate layer %s", ErrInvalidConfiguration, layer.Name)
+		}
+		model, err := lm.checkLayerModel(layer.Name, layer.Model)
+		if err != nil {
+			return nil, err
+		}
+		lm.layers = append(lm.layers, &stackedLayer{name: layer.Name, model: model})
+	}
+
+	lm.vocabularySize = lm.countVocabulary()
+	return lm, nil
+}
+
+// checkLayerModel checks that model can be stacked as the named layer
+func (lm *LayeredVectorModel) checkLayerModel(name string, model VectorModel) (layerModel, error) {
+	layer, ok := model.(layerModel)
+	if !ok || layer == nil {
+		return nil, fmt.Errorf("%w: layer %s is not a vector model of this package", ErrInvalidConfiguration, name)
+	}
+	if layer.Dimension() != lm.dimension {
+		return nil, fmt.Errorf("%w: layer %s has dimension %d, expected %d",
+			ErrDimensionMismatch, name, layer.Dimension(), lm.dimension)
+	}
+	return layer, nil
+}
+
+// ReplaceLayer swaps the model of the named layer, e.g. after the domain vectors were
+// retrained, and resets the layer's counters. The other layers are kept as they are.
+// The new model should use the same normalizer as the stack, e.g. by being loaded with the
+// same EmbeddingLoader.
+func (lm *LayeredVectorModel) ReplaceLayer(name string, model VectorModel) error {
+	lm.mtx.Lock()
+	defer lm.mtx.Unlock()
+
+	index, exists := lm.layer(name)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrLayerNotFound, name)
+	}
+	replacement, err := lm.checkLayerModel(name, model)
+	if err != nil {
+		return err
+	}
+
+	lm.layers[index] = &stackedLayer{name: name, model: replacement}
+	lm.clearFallbackCache()
+	lm.vocabularySize = lm.countVocabulary()
+	return nil
+}
+
+// Layer returns the model of the named layer
+func (lm *LayeredVectorModel) Layer(name string) (VectorModel, bool) {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+
+	index, exists := lm.layer(name)
+	if !exists {
+		return nil, false
+	}
+	return lm.layers[index].model, true
+}
+
+// LayerStats returns the vocabulary size and lookup counters of each layer, highest priority first
+func (lm *LayeredVectorModel) LayerStats() []LayerStats {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+
+	stats := make([]LayerStats, len(lm.layers))
+	for i, layer := range lm.layers {
+		stats[i] = LayerStats{
+			Name:           layer.name,
+			VocabularySize: layer.model.VocabularySize(),
+			Lookups:        atomic.LoadInt64(&layer.lookups),
+			Hits:           atomic.LoadInt64(&layer.hits),
+		}
+	}
+	return stats
+}
+
+// layer returns the index of the named layer
+// This method is called with the lock already held.
+func (lm *LayeredVectorModel) layer(name string) (int, bool) {
+	for i, layer := range lm.layers {
+		if layer.name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// find returns the vector of word from the first layer that has it, its rank there and the
+// index of the layer. Per-layer counters are updated atomically if count is set.
+// This method is called with at least the read lock held.
+func (lm *LayeredVectorModel) find(word string, count bool) ([]float32, int, int, bool) {
+	for i, layer := range lm.layers {
+		if count {
+			atomic.AddInt64(&layer.lookups, 1)
+		}
+		if vector, rank, exists := layer.model.lookupVector(word); exists {
+			if count {
+				atomic.AddInt64(&layer.hits, 1)
+			}
+			return vector, rank, i, true
+		}
+	}
+	return nil, 0, -1, false
+}
+
+// shadowed reports whether a layer above the given index has word
+// This method is called with the lock already held.
+func (lm *LayeredVectorModel) shadowed(word string, index int) bool {
+	return isShadowed(lm.layers[:index], word)
+}
+
+// countVocabulary counts the distinct words of all layers
+// This method is called with the lock already held.
+func (lm *LayeredVectorModel) countVocabulary() int {
+	count := 0
+	for i, layer := range lm.layers {
+		layer.model.rangeVectors(func(word string, _ []float32) bool {
+			if !lm.shadowed(word, i) {
+				count++
+			}
+			return true
+		})
+	}
+	return count
+}
+
+// GetVector retrieves the vector of a word from the first layer that has it
+// If no layer has the word (OOV), attempts the fallback chain for the word's language
+func (lm *LayeredVectorModel) GetVector(word string) ([]float32, bool) {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+
+	vectors := lm.collectVectors([]string{word}, nil)
+	if len(vectors) == 0 {
+		return nil, false
+	}
+	return copyVector(vectors[0]), true
+}
+
+// GetAverageVector computes mean pooling for multiple words
+func (lm *LayeredVectorModel) GetAverageVector(words []string) ([]float32, bool) {
+	return lm.GetPooledVector(words, MeanPooling{})
+}
+
+// GetPooledVector combines the vectors of multiple words with the given pooling strategy
+// OOV words go through the fallback chain and are skipped if it fails
+func (lm *LayeredVectorModel) GetPooledVector(words []string, pooling Pooling) ([]float32, bool) {
+	return lm.GetWeightedPooledVector(words, pooling, nil)
+}
+
+// GetWeightedPooledVector is GetPooledVector with each word's vector scaled by its weight
+// Frequency ranks come from the layer the word's vector was taken from.
+func (lm *LayeredVectorModel) GetWeightedPooledVector(
+	words []string,
+	pooling Pooling,
+	weighting TokenWeighting,
+) ([]float32, bool) {
+	vector, _, ok := lm.pooledVector(words, pooling, weighting)
+	return vector, ok
+}
+
+// pooledVector is GetWeightedPooledVector that also returns the number of words without a vector
+func (lm *LayeredVectorModel) pooledVector(
+	words []string,
+	pooling Pooling,
+	weighting TokenWeighting,
+) ([]float32, int, bool) {
+	if len(words) == 0 {
+		return nil, 0, false
+	}
+
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+
+	vectors := lm.collectVectors(words, weighting)
+	if len(vectors) == 0 {
+		return nil, len(words), false
+	}
+
+	return pooling.Pool(vectors), len(words) - len(vectors), true
+}
+
+// collectVectors looks up each word, using the fallback chain for OOV words, and returns
+// the vectors found in word order, scaled by weighting if it is not nil. Unscaled
+// vocabulary vectors are returned without copying.
+// This method is called with at least the read lock held.
+func (lm *LayeredVectorModel) collectVectors(words []string, weighting TokenWeighting) [][]float32 {
+	vectors := make([][]float32, 0, len(words))
+
+	for _, word := range words {
+		word = lm.normalizer.Normalize(word)
+
+		if vector, rank, index, exists := lm.find(word, true); exists {
+			lm.recordHit()
+			if weighting != nil {
+				weight := weighting.Weight(word, rank, lm.layers[index].model.rankedWords())
+				vector = ScaleVector(vector, float32(weight))
+			}
+			vectors = append(vectors, vector)
+			continue
+		}
+
+		lm.recordMiss()
+		vector, success := lm.fallbackFor(word, lm.dimension, lm.lookupExact)
+		lm.recordOOV(word, success)
+		if !success {
+			continue
+		}
+		if weighting != nil {
+			vector = ScaleVector(vector, float32(weighting.Weight(word, 0, lm.rankedWordsLocked())))
+		}
+		vectors = append(vectors, vector)
+	}
+
+	return vectors
+}
+
+// lookupExact returns the vocabulary vector of word from the first layer that has it,
+// without fallback or statistics
+// This method is called with the lock already held.
+func (lm *LayeredVectorModel) lookupExact(word string) ([]float32, bool) {
+	vector, _, _, exists := lm.find(word, false)
+	return vector, exists
+}
+
+// lookupVector returns the vocabulary vector of a word and its rank in the layer it was
+// taken from, without fallback, statistics or copying
+func (lm *LayeredVectorModel) lookupVector(word string) ([]float32, int, bool) {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+
+	vector, rank, _, exists := lm.find(lm.normalizer.Normalize(word), false)
+	return vector, rank, exists
+}
+
+// rankedWords returns the largest frequency rank recorded by any layer
+func (lm *LayeredVectorModel) rankedWords() int {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return lm.rankedWordsLocked()
+}
+
+// rankedWordsLocked is rankedWords for callers that already hold the lock
+func (lm *LayeredVectorModel) rankedWordsLocked() int {
+	ranked := 0
+	for _, layer := range lm.layers {
+		ranked = max(ranked, layer.model.rankedWords())
+	}
+	return ranked
+}
+
+// rangeVectors calls yield for every word of the stack and the vector it resolves to until
+// yield returns false
+func (lm *LayeredVectorModel) rangeVectors(yield func(word string, vector []float32) bool) {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	lm.rangeVectorsLocked(yield)
+}
+
+// rangeVectorsLocked is rangeVectors for callers that already hold the lock
+func (lm *LayeredVectorModel) rangeVectorsLocked(yield func(word string, vector []float32) bool) {
+	for i, layer := range lm.layers {
+		stopped := false
+		layer.model.rangeVectors(func(word string, vector []float32) bool {
+			if lm.shadowed(word, i) {
+				return true
+			}
+			stopped = !yield(word, vector)
+			return !stopped
+		})
+		if stopped {
+			return
+		}
+	}
+}
+
+// WordRank returns the frequency rank of word in the layer its vector is taken from
+func (lm *LayeredVectorModel) WordRank(word string) (int, bool) {
+	_, rank, exists := lm.lookupVector(word)
+	if !exists || rank == 0 {
+		return 0, false
+	}
+	return rank, true
+}
+
+// Words returns an iterator over the words of the stack: the words of each layer in the
+// layer's order, highest priority layer first, skipping words shadowed by a higher layer
+func (lm *LayeredVectorModel) Words() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		layers := lm.snapshotLayers()
+		for i, layer := range layers {
+			for word := range layer.model.Words() {
+				if isShadowed(layers[:i], word) {
+					continue
+				}
+				if !yield(word) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All returns an iterator over word-vector pairs in the same order as Words
+// Vectors are copies. The stack is not locked while the caller handles a pair.
+func (lm *LayeredVectorModel) All() iter.Seq2[string, []float32] {
+	return func(yield func(string, []float32) bool) {
+		layers := lm.snapshotLayers()
+		for i, layer := range layers {
+			for word, vector := range layer.model.All() {
+				if isShadowed(layers[:i], word) {
+					continue
+				}
+				if !yield(word, vector) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// snapshotLayers returns a copy of the layer list, so iteration is not affected by ReplaceLayer
+func (lm *LayeredVectorModel) snapshotLayers() []*stackedLayer {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return append([]*stackedLayer{}, lm.layers...)
+}
+
+// isShadowed reports whether any of the layers has word
+func isShadowed(layers []*stackedLayer, word string) bool {
+	for _, layer := range layers {
+		if _, _, exists := layer.model.lookupVector(word); exists {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteVec writes the words of the stack in .vec text format, in the order of Words
+// Returns the number of bytes written.
+func (lm *LayeredVectorModel) WriteVec(w io.Writer) (int64, error) {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+
+	words := make([]string, 0, lm.vocabularySize)
+	for i, layer := range lm.layers {
+		for word := range layer.model.Words() {
+			if !lm.shadowed(word, i) {
+				words = append(words, word)
+			}
+		}
+	}
+
+	return writeVec(w, lm.dimension, words, func(word string) []float32 {
+		vector, _ := lm.lookupExact(word)
+		return vector
+	})
+}
+
+// Dimension returns the vector dimension
+func (lm *LayeredVectorModel) Dimension() int {
+	return lm.dimension
+}
+
+// VocabularySize returns the number of distinct words over all layers
+// It is counted when the stack is built or a layer is replaced.
+func (lm *LayeredVectorModel) VocabularySize() int {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return lm.vocabularySize
+}
+
+// MemoryUsage returns the estimated memory usage of all layers in bytes
+func (lm *LayeredVectorModel) MemoryUsage() int64 {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+
+	var usage int64
+	for _, layer := range lm.layers {
+		usage += layer.model.MemoryUsage()
+	}
+	return usage
+}
+
+// GetOOVRate returns the rate of words found in no layer
+func (lm *LayeredVectorModel) GetOOVRate() float64 {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return lm.oovRate()
+}
+
+// GetVectorHitRate returns the rate of words found in some layer
+func (lm *LayeredVectorModel) GetVectorHitRate() float64 {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return lm.hitRate()
+}
+
+// GetLookupStats returns lookup statistics over the whole stack; see LayerStats for per-layer counters
+func (lm *LayeredVectorModel) GetLookupStats() (totalLookups, oovLookups, hitLookups, fallbackAttempts, fallbackSuccesses, fallbackFailures int64) {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+
+	return lm.lookupStats()
+}
+
+// GetFallbackSuccessRate returns the success rate of fallback operations
+func (lm *LayeredVectorModel) GetFallbackSuccessRate() float64 {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return lm.fallbackSuccessRate()
+}
+
+// GetFallbackStrategyStats returns attempt and success counters per fallback strategy name
+func (lm *LayeredVectorModel) GetFallbackStrategyStats() map[string]FallbackStrategyStats {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return lm.strategyStats()
+}
+
+// SetFallbackChain sets the fallback strategies for OOV words of a language
+// The chains of the layers are not used.
+func (lm *LayeredVectorModel) SetFallbackChain(language string, chain ...FallbackStrategy) {
+	lm.mtx.Lock()
+	defer lm.mtx.Unlock()
+	lm.setFallbackChain(language, chain)
+}
+
+// SetFallbackCacheSize enables an LRU cache of up to size fallback results; zero disables it
+// The cache is emptied when a layer is replaced, but not when a layer model is modified directly.
+func (lm *LayeredVectorModel) SetFallbackCacheSize(size int) {
+	lm.mtx.Lock()
+	defer lm.mtx.Unlock()
+	lm.setFallbackCacheSize(size)
+}
+
+// GetFallbackCacheStats returns the size and hit/miss counters of the fallback cache of the stack
+func (lm *LayeredVectorModel) GetFallbackCacheStats() FallbackCacheStats {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return lm.fallbackCacheStats()
+}
+
+// keyNormalizer returns the normalizer of lookups and layer keys
+func (lm *LayeredVectorModel) keyNormalizer() *Normalizer {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return lm.normalizer
+}
+
+// SetNormalizer sets the Unicode normalization of lookups and of the keys of every layer
+func (lm *LayeredVectorModel) SetNormalizer(normalizer *Normalizer) {
+	lm.mtx.Lock()
+	defer lm.mtx.Unlock()
+
+	lm.normalizer = normalizer
+	lm.clearFallbackCache()
+	for _, layer := range lm.layers {
+		layer.model.SetNormalizer(normalizer)
+	}
+	lm.vocabularySize = lm.countVocabulary()
+}
+
+// ResetStats resets the statistics of the stack and the counters of every layer
+func (lm *LayeredVectorModel) ResetStats() {
+	lm.mtx.Lock()
+	defer lm.mtx.Unlock()
+
+	lm.resetStats()
+	for _, layer := range lm.layers {
+		atomic.StoreInt64(&layer.lookups, 0)
+		atomic.StoreInt64(&layer.hits, 0)
+	}
+}
+
+// TopOOVWords returns the n most frequent words found in no layer
+func (lm *LayeredVectorModel) TopOOVWords(n int) []OOVWordStat {
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+	return lm.topOOVWords(n)
+}
+
+// SetOOVTrackerCapacity changes the number of distinct OOV words tracked
+// This resets the words tracked so far
+func (lm *LayeredVectorModel) SetOOVTrackerCapacity(capacity int) {
+	lm.mtx.Lock()
+	defer lm.mtx.Unlock()
+	lm.oovTracker = newOOVTracker(capacity)
+}
+
+// Analogy answers "a is to b as c is to ?" over the words of the stack, each word
+// represented by the vector it resolves to
+func (lm *LayeredVectorModel) Analogy(a, b, c string, k int, method AnalogyMethod) ([]WordScore, error) {
+	if err := validateAnalogyQuery(a, b, c, k, method); err != nil {
+		return nil, err
+	}
+
+	lm.mtx.RLock()
+	defer lm.mtx.RUnlock()
+
+	a, b, c = lm.normalizer.Normalize(a), lm.normalizer.Normalize(b), lm.normalizer.Normalize(c)
+	return scanAnalogy(a, b, c, k, method, lm.lookupExact, lm.rangeVectorsLocked)
+}
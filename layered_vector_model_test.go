@@ -0,0 +1,237 @@
+package semanticmatcher
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	domainTestVectors = `2 3
+widget 0 0 1
+market 0 1 1
+`
+	baseTestVectors = `4 3
+market 1 0 0
+stock 0 1 0
+rain 1 1 0
+wid 0 1 1
+`
+)
+
+// newLayeredTestModel stacks the domain test vectors over the base test vectors
+func newLayeredTestModel(t *testing.T) *LayeredVectorModel {
+	t.Helper()
+	loader := NewEmbeddingLoader(DiscardLogger{})
+	domain, err := loader.LoadFromReader(strings.NewReader(domainTestVectors))
+	require.NoError(t, err)
+	base, err := loader.LoadFromReader(strings.NewReader(baseTestVectors))
+	require.NoError(t, err)
+
+	model, err := NewLayeredVectorModel(
+		VectorLayer{Name: "domain", Model: domain},
+		VectorLayer{Name: BaseLayerName, Model: base},
+	)
+	require.NoError(t, err)
+	return model
+}
+
+func TestLayeredVectorModel_Lookup(t *testing.T) {
+	model := newLayeredTestModel(t)
+	assert.Equal(t, 3, model.Dimension())
+	assert.Equal(t, 5, model.VocabularySize())
+
+	// Domain vectors win over base vectors
+	vector, ok := model.GetVector("market")
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 1, 1}, vector)
+
+	// Words missing from the domain layer fall through to the base layer
+	vector, ok = model.GetVector("stock")
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 1, 0}, vector)
+
+	// Fallback sees all layers
+	vector, ok = model.GetVector("widgets")
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 0, 1}, vector)
+
+	_, ok = model.GetVector("zzz")
+	assert.False(t, ok)
+
+	total, oov, hits, attempts, _, _ := model.GetLookupStats()
+	assert.Equal(t, int64(4), total)
+	assert.Equal(t, int64(2), oov)
+	assert.Equal(t, int64(2), hits)
+	assert.Equal(t, int64(2), attempts)
+	assert.Equal(t, []LayerStats{
+		{Name: "domain", VocabularySize: 2, Lookups: 4, Hits: 1},
+		{Name: BaseLayerName, VocabularySize: 4, Lookups: 3, Hits: 1},
+	}, model.LayerStats())
+
+	model.ResetStats()
+	assert.Zero(t, model.LayerStats()[0].Lookups)
+	assert.Zero(t, model.GetOOVRate())
+}
+
+func TestLayeredVectorModel_PooledAndWeighted(t *testing.T) {
+	model := newLayeredTestModel(t)
+
+	pooled, ok := model.GetPooledVector([]string{"market", "stock", "zzz"}, MeanPooling{})
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 1, 0.5}, pooled)
+
+	// Ranks come from the layer the vector is taken from
+	rank, ok := model.WordRank("market")
+	require.True(t, ok)
+	assert.Equal(t, 2, rank)
+	rank, ok = model.WordRank("rain")
+	require.True(t, ok)
+	assert.Equal(t, 3, rank)
+
+	weighted, ok := model.GetWeightedPooledVector([]string{"market"}, MeanPooling{}, SIFWeighting{A: 1})
+	require.True(t, ok)
+	weight := SIFWeighting{A: 1}.Weight("market", 2, 2)
+	assert.InDeltaSlice(t, []float32{0, float32(weight), float32(weight)}, weighted, 1e-6)
+}
+
+func TestLayeredVectorModel_ConcurrentLookups(t *testing.T) {
+	model := newLayeredTestModel(t)
+
+	var wg sync.WaitGroup
+	numGoroutines, iterations := 8, 100
+	for range numGoroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range iterations {
+				model.GetAverageVector([]string{"widget", "stock", "unknown"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	lookups := int64(numGoroutines * iterations)
+	total, oov, hits, _, _, _ := model.GetLookupStats()
+	assert.Equal(t, 3*lookups, total)
+	assert.Equal(t, 2*lookups, hits)
+	assert.Equal(t, lookups, oov)
+
+	stats := model.LayerStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, 3*lookups, stats[0].Lookups)
+	assert.Equal(t, lookups, stats[0].Hits)
+	assert.Equal(t, 2*lookups, stats[1].Lookups)
+	assert.Equal(t, lookups, stats[1].Hits)
+}
+
+func TestLayeredVectorModel_Iteration(t *testing.T) {
+	model := newLayeredTestModel(t)
+
+	assert.Equal(t, []string{"widget", "market", "stock", "rain", "wid"}, slices.Collect(model.Words()))
+	for word, vector := range model.All() {
+		if word == "market" {
+			assert.Equal(t, []float32{0, 1, 1}, vector)
+		}
+	}
+
+	var buf bytes.Buffer
+	written, err := model.WriteVec(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), written)
+
+	reloaded, err := NewEmbeddingLoader(DiscardLogger{}).LoadFromReader(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 5, reloaded.VocabularySize())
+	vector, _ := reloaded.GetVector("market")
+	assert.Equal(t, []float32{0, 1, 1}, vector)
+
+	results, err := model.Analogy("stock", "market", "rain", 5, Analogy3CosAdd)
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.NotContains(t, []string{"stock", "market", "rain"}, result.Word)
+	}
+	assert.Len(t, results, 2)
+}
+
+func TestLayeredVectorModel_ReplaceLayer(t *testing.T) {
+	model := newLayeredTestModel(t)
+	model.GetVector("market")
+
+	retrained := NewVectorModel(3)
+	retrained.(*vectorModel).AddVector("gadget", []float32{1, 0, 1})
+	require.NoError(t, model.ReplaceLayer("domain", retrained))
+
+	_, ok := model.GetVector("gadget")
+	assert.True(t, ok)
+	vector, _ := model.GetVector("market")
+	assert.Equal(t, []float32{1, 0, 0}, vector)
+	assert.Equal(t, 5, model.VocabularySize())
+	assert.Equal(t, int64(2), model.LayerStats()[0].Lookups)
+
+	layer, ok := model.Layer("domain")
+	require.True(t, ok)
+	assert.Same(t, retrained, layer)
+
+	require.ErrorIs(t, model.ReplaceLayer("missing", retrained), ErrLayerNotFound)
+	require.ErrorIs(t, model.ReplaceLayer("domain", NewVectorModel(4)), ErrDimensionMismatch)
+}
+
+func TestNewLayeredVectorModel_Errors(t *testing.T) {
+	_, err := NewLayeredVectorModel()
+	require.ErrorIs(t, err, ErrEmptyInput)
+
+	_, err = NewLayeredVectorModel(VectorLayer{Name: "a", Model: NewVectorModel(3)}, VectorLayer{Name: "a", Model: NewVectorModel(3)})
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	_, err = NewLayeredVectorModel(VectorLayer{Model: NewVectorModel(3)})
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	_, err = NewLayeredVectorModel(VectorLayer{Name: "a", Model: NewVectorModel(3)}, VectorLayer{Name: "b", Model: NewVectorModel(2)})
+	require.ErrorIs(t, err, ErrDimensionMismatch)
+
+	// Layered models can be stacked again
+	inner := newLayeredTestModel(t)
+	outer, err := NewLayeredVectorModel(VectorLayer{Name: "inner", Model: inner})
+	require.NoError(t, err)
+	assert.Equal(t, 5, outer.VocabularySize())
+}
+
+func TestNewSemanticMatcherFromConfig_VectorLayers(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.vec")
+	domainPath := filepath.Join(dir, "domain.vec")
+	require.NoError(t, os.WriteFile(basePath, []byte(baseTestVectors), 0o644))
+	require.NoError(t, os.WriteFile(domainPath, []byte(domainTestVectors), 0o644))
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{basePath}
+	config.VectorLayers = []VectorLayerConfig{{Name: "domain", VectorFilePaths: []string{domainPath}}}
+
+	matcher, err := NewSemanticMatcherFromConfig(config, DiscardLogger{})
+	require.NoError(t, err)
+
+	vector, ok := matcher.VectorizeText("market")
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 1, 1}, vector)
+
+	stats := matcher.GetStats()
+	require.Len(t, stats.Layers, 2)
+	assert.Equal(t, int64(1), stats.Layers[0].Hits)
+
+	// Retrained domain vectors are picked up without reloading the base
+	require.NoError(t, os.WriteFile(domainPath, []byte("1 3\nmarket 1 1 1\n"), 0o644))
+	require.NoError(t, matcher.ReloadVectorLayer("domain"))
+	vector, ok = matcher.VectorizeText("market")
+	require.True(t, ok)
+	assert.Equal(t, []float32{1, 1, 1}, vector)
+
+	assert.ErrorIs(t, matcher.ReloadVectorLayer("missing"), ErrLayerNotFound)
+}
@@ -0,0 +1,236 @@
+package semanticmatcher
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// lookupState holds the lookup statistics, OOV tracking and fallback configuration of a
+// vector model. Lookups run under the model's read lock, so that they can run in parallel:
+// the counters are updated atomically and the OOV tracker, per-strategy counters and fallback
+// cache are guarded by mtx. The fallback configuration is only changed under the model's write lock.
+type lookupState struct {
+	mtx sync.Mutex // Guards oovTracker, fallbackStrategyStats and fallbackCache during lookups
+
+	// Statistics tracking, updated atomically
+	totalLookups int64 // Total number of vector lookups
+	oovLookups   int64 // Number of OOV (out-of-vocabulary) lookups
+	hitLookups   int64 // Number of successful lookups
+
+	// Fallback statistics
+	fallbackAttempts  int64 // Number of character-level fallback attempts
+	fallbackSuccesses int64 // Number of successful fallback operations
+	fallbackFailures  int64 // Number of failed fallback operations
+
+	oovTracker *oovTracker // Heavy-hitter tracking of individual OOV words
+
+	// Fallback configuration
+	defaultFallbackChain  []FallbackStrategy                // Chain used for languages without their own chain
+	fallbackChains        map[string][]FallbackStrategy     // Per-language chains, keyed by language code
+	fallbackStrategyStats map[string]*FallbackStrategyStats // Per-strategy counters, keyed by strategy name
+	fallbackCache         *fallbackCache                    // Fallback results by word; nil disables caching
+}
+
+// newLookupState returns zeroed statistics with the default fallback chain
+func newLookupState() lookupState {
+	return lookupState{
+		oovTracker:            newOOVTracker(DefaultOOVTrackerCapacity),
+		defaultFallbackChain:  DefaultFallbackChain(),
+		fallbackChains:        make(map[string][]FallbackStrategy),
+		fallbackStrategyStats: make(map[string]*FallbackStrategyStats),
+	}
+}
+
+// recordHit counts a lookup found in the vocabulary
+func (s *lookupState) recordHit() {
+	atomic.AddInt64(&s.totalLookups, 1)
+	atomic.AddInt64(&s.hitLookups, 1)
+}
+
+// recordMiss counts a lookup missing from the vocabulary, before its fallback
+func (s *lookupState) recordMiss() {
+	atomic.AddInt64(&s.totalLookups, 1)
+	atomic.AddInt64(&s.oovLookups, 1)
+	atomic.AddInt64(&s.fallbackAttempts, 1)
+}
+
+// recordOOV tracks an OOV word and whether fallback rescued it
+func (s *lookupState) recordOOV(word string, rescued bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.oovTracker.record(word, rescued)
+}
+
+// lookupStats returns the lookup and fallback counters
+func (s *lookupState) lookupStats() (
+	totalLookups, oovLookups, hitLookups, fallbackAttempts, fallbackSuccesses, fallbackFailures int64,
+) {
+	return atomic.LoadInt64(&s.totalLookups), atomic.LoadInt64(&s.oovLookups), atomic.LoadInt64(&s.hitLookups),
+		atomic.LoadInt64(&s.fallbackAttempts), atomic.LoadInt64(&s.fallbackSuccesses),
+		atomic.LoadInt64(&s.fallbackFailures)
+}
+
+// oovRate returns the rate of out-of-vocabulary lookups
+func (s *lookupState) oovRate() float64 {
+	total, oov, _, _, _, _ := s.lookupStats()
+	if total == 0 {
+		return 0.0
+	}
+	return float64(oov) / float64(total)
+}
+
+// hitRate returns the rate of successful vocabulary lookups
+func (s *lookupState) hitRate() float64 {
+	total, _, hit, _, _, _ := s.lookupStats()
+	if total == 0 {
+		return 0.0
+	}
+	return float64(hit) / float64(total)
+}
+
+// fallbackSuccessRate returns the success rate of fallback operations
+func (s *lookupState) fallbackSuccessRate() float64 {
+	_, _, _, attempts, successes, _ := s.lookupStats()
+	if attempts == 0 {
+		return 0.0
+	}
+	return float64(successes) / float64(attempts)
+}
+
+// topOOVWords returns the n most frequent tracked OOV words
+func (s *lookupState) topOOVWords(n int) []OOVWordStat {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.oovTracker.top(n)
+}
+
+// fallbackCacheStats returns the counters of the fallback cache
+func (s *lookupState) fallbackCacheStats() FallbackCacheStats {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.fallbackCache.stats()
+}
+
+// clearFallbackCache forgets all cached fallback results
+func (s *lookupState) clearFallbackCache() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.fallbackCache.clear()
+}
+
+// resetStats zeroes all counters and forgets tracked OOV words
+func (s *lookupState) resetStats() {
+	atomic.StoreInt64(&s.totalLookups, 0)
+	atomic.StoreInt64(&s.oovLookups, 0)
+	atomic.StoreInt64(&s.hitLookups, 0)
+	atomic.StoreInt64(&s.fallbackAttempts, 0)
+	atomic.StoreInt64(&s.fallbackSuccesses, 0)
+	atomic.StoreInt64(&s.fallbackFailures, 0)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.oovTracker.reset()
+	s.fallbackStrategyStats = make(map[string]*FallbackStrategyStats)
+	s.fallbackCache.resetCounters()
+}
+
+// setFallbackCacheSize replaces the fallback cache with an empty one of the given size;
+// zero or less disables caching
+func (s *lookupState) setFallbackCacheSize(size int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.fallbackCache = newFallbackCache(size)
+}
+
+// setFallbackChain sets the chain of a language, or the default chain for an empty language
+func (s *lookupState) setFallbackChain(language string, chain []FallbackStrategy) {
+	chainCopy := append([]FallbackStrategy{}, chain...)
+	s.clearFallbackCache()
+	if language == "" {
+		s.defaultFallbackChain = chainCopy
+		return
+	}
+	s.fallbackChains[language] = chainCopy
+}
+
+// strategyStats returns a copy of the per-strategy counters
+func (s *lookupState) strategyStats() map[string]FallbackStrategyStats {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	stats := make(map[string]FallbackStrategyStats, len(s.fallbackStrategyStats))
+	for name, counters := range s.fallbackStrategyStats {
+		stats[name] = *counters
+	}
+	return stats
+}
+
+// fallbackFor runs the fallback chain configured for the word's language, or returns its
+// cached result. Cached results count as fallback successes or failures, but not as
+// strategy attempts.
+func (s *lookupState) fallbackFor(word string, dimension int, lookup VocabularyLookup) ([]float32, bool) {
+	if vector, found, cached := s.cachedFallback(word); cached {
+		if found {
+			atomic.AddInt64(&s.fallbackSuccesses, 1)
+		} else {
+			atomic.AddInt64(&s.fallbackFailures, 1)
+		}
+		return vector, found
+	}
+
+	chain, exists := s.fallbackChains[wordLanguage(word)]
+	if !exists {
+		chain = s.defaultFallbackChain
+	}
+	vector, found := s.runChain(word, chain, dimension, lookup)
+
+	s.mtx.Lock()
+	if s.fallbackCache != nil {
+		s.fallbackCache.put(word, vector, found)
+	}
+	s.mtx.Unlock()
+	return vector, found
+}
+
+// cachedFallback returns the cached fallback result of word and whether there was one
+func (s *lookupState) cachedFallback(word string) ([]float32, bool, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.fallbackCache == nil {
+		return nil, false, false
+	}
+	return s.fallbackCache.get(word)
+}
+
+// runChain tries each strategy in order and records per-strategy and overall outcomes
+// Vectors of the wrong dimension count as failures.
+func (s *lookupState) runChain(
+	word string,
+	chain []FallbackStrategy,
+	dimension int,
+	lookup VocabularyLookup,
+) ([]float32, bool) {
+	for _, strategy := range chain {
+		s.mtx.Lock()
+		counters, exists := s.fallbackStrategyStats[strategy.Name()]
+		if !exists {
+			counters = &FallbackStrategyStats{}
+			s.fallbackStrategyStats[strategy.Name()] = counters
+		}
+		counters.Attempts++
+		s.mtx.Unlock()
+
+		// Strategies only read the vocabulary, so they run without holding mtx
+		vector, ok := strategy.Fallback(word, lookup)
+		if ok && len(vector) == dimension {
+			s.mtx.Lock()
+			counters.Successes++
+			s.mtx.Unlock()
+			atomic.AddInt64(&s.fallbackSuccesses, 1)
+			return vector, true
+		}
+	}
+
+	atomic.AddInt64(&s.fallbackFailures, 1)
+	return nil, false
+}
@@ -0,0 +1,73 @@
+package semanticmatcher
+
+// MatchOption overrides matcher settings for a single call
+type MatchOption func(*matchOptions)
+
+// matchOptions holds the settings used by one matching call
+type matchOptions struct {
+	pooling         Pooling
+	weighting       TokenWeighting    // nil weighs all tokens the same
+	commonComponent *CommonComponent  // Removed from text vectors if not nil
+	alignment       *AlignmentScoring // Token alignment scoring of keywords if not nil
+	hybrid          HybridConfig      // Fusion of lexical and semantic keyword scores
+	calibrator      *Calibrator       // Maps raw scores to probabilities if not nil
+	scoreMode       string            // Mapping of raw scores; empty calibrates if possible, else clamps
+
+	metric            string      // Name of the matcher's metric, which calibrators must be fitted with
+	ignoredCalibrator *Calibrator // Calibrator passed to WithCalibrator for another metric, if any
+	ignoredScoreMode  string      // Unknown mode passed to WithScoreMode, if any
+}
+
+// WithPooling pools token vectors with pooling instead of the matcher's configured strategy
+// A nil pooling is ignored.
+func WithPooling(pooling Pooling) MatchOption {
+	return func(o *matchOptions) {
+		if pooling != nil {
+			o.pooling = pooling
+		}
+	}
+}
+
+// WithTokenWeighting weighs token vectors with weighting instead of the matcher's configured
+// weighting; nil weighs all tokens the same
+func WithTokenWeighting(weighting TokenWeighting) MatchOption {
+	return func(o *matchOptions) {
+		o.weighting = weighting
+	}
+}
+
+// resolveOptions applies opts on top of the matcher's defaults
+// The fitted common component is only used with the pooling and weighting it was fitted with.
+func (sm *semanticMatcher) resolveOptions(opts []MatchOption) matchOptions {
+	sm.mtx.RLock()
+	options := matchOptions{
+		pooling:    sm.pooling,
+		weighting:  sm.weighting,
+		hybrid:     sm.hybrid,
+		calibrator: sm.calibrator,
+		scoreMode:  sm.scoreMode,
+		metric:     sm.similarityMetricName(),
+	}
+	component := sm.commonComponent
+	sm.mtx.RUnlock()
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if ignored := options.ignoredCalibrator; ignored != nil {
+		sm.logger.Warnf("Ignoring calibrator fitted with another metric, calibrator_metric: %s, metric: %s",
+			ignored.Metric, options.metric)
+	}
+	if options.ignoredScoreMode != "" {
+		sm.logger.Warnf("Ignoring unknown score mode, score_mode: %s", options.ignoredScoreMode)
+	}
+	if options.alignment != nil && !validAlignmentScore(options.alignment.Score) {
+		sm.logger.Warnf("Unknown alignment score, scoring by %s, score: %s", AlignmentPrecision, options.alignment.Score)
+	}
+
+	if component != nil && component.pooling == options.pooling.Name() &&
+		component.weighting == tokenWeightingName(options.weighting) {
+		options.commonComponent = component
+	}
+	return options
+}
@@ -0,0 +1,115 @@
+package semanticmatcher
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizationConfig selects the Unicode normalization steps applied to vocabulary keys
+// and query tokens. Steps run in field order.
+type NormalizationConfig struct {
+	// NFKC applies Unicode compatibility composition ("ﬁ" -> "fi", "①" -> "1")
+	NFKC bool `mapstructure:"nfkc"`
+
+	// FullWidth maps full-width ASCII variants and the ideographic space to their
+	// half-width forms ("ＡＩ" -> "AI", "２０２４" -> "2024")
+	FullWidth bool `mapstructure:"full_width"`
+
+	// StripAccents removes combining diacritical marks ("café" -> "cafe").
+	// Only the Latin combining block is removed, so kana voicing marks are kept.
+	StripAccents bool `mapstructure:"strip_accents"`
+
+	// CaseFold applies Unicode case folding ("Straße" -> "strasse")
+	CaseFold bool `mapstructure:"case_fold"`
+}
+
+// Enabled reports whether any normalization step is selected
+func (c NormalizationConfig) Enabled() bool {
+	return c.NFKC || c.FullWidth || c.StripAccents || c.CaseFold
+}
+
+// Normalizer applies the same normalization to vocabulary keys at load time and to
+// query tokens at lookup time. A nil *Normalizer leaves text unchanged.
+type Normalizer struct {
+	config NormalizationConfig
+}
+
+// NewNormalizer creates a Normalizer for the given steps
+// Returns nil if no step is enabled
+func NewNormalizer(config NormalizationConfig) *Normalizer {
+	if !config.Enabled() {
+		return nil
+	}
+	return &Normalizer{config: config}
+}
+
+// Config returns the normalization steps of n
+func (n *Normalizer) Config() NormalizationConfig {
+	if n == nil {
+		return NormalizationConfig{}
+	}
+	return n.config
+}
+
+// Normalize returns the normalized form of s
+func (n *Normalizer) Normalize(s string) string {
+	if n == nil || s == "" {
+		return s
+	}
+
+	// Only case folding affects plain ASCII
+	if isASCII(s) {
+		if n.config.CaseFold {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+
+	if n.config.NFKC {
+		s = norm.NFKC.String(s)
+	}
+	if n.config.FullWidth {
+		s = strings.Map(toHalfWidth, s)
+	}
+	if n.config.StripAccents {
+		s = norm.NFC.String(strings.Map(dropCombiningDiacritic, norm.NFD.String(s)))
+	}
+	if n.config.CaseFold {
+		// Casers keep state and are not safe for concurrent use
+		s = cases.Fold().String(s)
+	}
+	return s
+}
+
+// toHalfWidth maps U+FF01-U+FF5E to U+0021-U+007E and the ideographic space to a space
+func toHalfWidth(r rune) rune {
+	switch {
+	case r == '\u3000':
+		return ' '
+	case r >= '\uFF01' && r <= '\uFF5E':
+		return r - 0xFEE0
+	default:
+		return r
+	}
+}
+
+// dropCombiningDiacritic removes runes in the Combining Diacritical Marks block
+func dropCombiningDiacritic(r rune) rune {
+	if r >= '\u0300' && r <= '\u036F' {
+		return -1
+	}
+	return r
+}
+
+// isASCII reports whether s contains only ASCII bytes
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package semanticmatcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	all := NormalizationConfig{NFKC: true, FullWidth: true, StripAccents: true, CaseFold: true}
+
+	tests := []struct {
+		name     string
+		config   NormalizationConfig
+		input    string
+		expected string
+	}{
+		{"NFKC ligature", NormalizationConfig{NFKC: true}, "ﬁle", "file"},
+		{"NFKC full-width", NormalizationConfig{NFKC: true}, "ＡＩ２０２４", "AI2024"},
+		{"NFKC circled digit", NormalizationConfig{NFKC: true}, "①", "1"},
+		{"full-width letters and digits", NormalizationConfig{FullWidth: true}, "ＡＩ ２０２４", "AI 2024"},
+		{"ideographic space", NormalizationConfig{FullWidth: true}, "人工　智能", "人工 智能"},
+		{"full-width keeps katakana", NormalizationConfig{FullWidth: true}, "アイ", "アイ"},
+		{"strip accents", NormalizationConfig{StripAccents: true}, "café naïve", "cafe naive"},
+		{"strip accents keeps kana voicing", NormalizationConfig{StripAccents: true}, "ガ", "ガ"},
+		{"case fold ASCII", NormalizationConfig{CaseFold: true}, "London", "london"},
+		{"case fold unicode", NormalizationConfig{CaseFold: true}, "Straße", "strasse"},
+		{"all steps", all, "Ｃａｆé", "cafe"},
+		{"Chinese unchanged", all, "人工智能", "人工智能"},
+		{"empty", all, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizer := NewNormalizer(tt.config)
+			require.NotNil(t, normalizer)
+			assert.Equal(t, tt.expected, normalizer.Normalize(tt.input))
+		})
+	}
+}
+
+func TestNormalizer_Disabled(t *testing.T) {
+	normalizer := NewNormalizer(NormalizationConfig{})
+	assert.Nil(t, normalizer)
+	assert.Equal(t, "ＡＩ", normalizer.Normalize("ＡＩ"))
+	assert.Equal(t, NormalizationConfig{}, normalizer.Config())
+}
+
+func TestVectorModel_Normalization(t *testing.T) {
+	vm := NewVectorModel(2).(*vectorModel)
+	vm.SetNormalizer(NewNormalizer(NormalizationConfig{NFKC: true, CaseFold: true}))
+
+	vm.AddVector("ai", []float32{1, 0})
+	vm.AddVector("ＡＩ", []float32{0, 1}) // Variant does not replace the exact form
+	vm.AddVector("Café", []float32{1, 1})
+
+	assert.Equal(t, 2, vm.VocabularySize())
+
+	tests := []struct {
+		word     string
+		expected []float32
+	}{
+		{"ai", []float32{1, 0}},
+		{"AI", []float32{1, 0}},
+		{"ＡＩ", []float32{1, 0}},
+		{"CAFÉ", []float32{1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			vector, ok := vm.GetVector(tt.word)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, vector)
+		})
+	}
+
+	_, oov, hits, _, _, _ := vm.GetLookupStats()
+	assert.Equal(t, int64(0), oov)
+	assert.Equal(t, int64(4), hits)
+
+	vector, ok := vm.GetAverageVector([]string{"ＡＩ", "café"})
+	require.True(t, ok)
+	assert.Equal(t, []float32{1, 0.5}, vector)
+}
+
+func TestVectorModel_SetNormalizerRekeysVocabulary(t *testing.T) {
+	vm := NewVectorModel(2).(*vectorModel)
+	vm.AddVector("ＡＩ", []float32{0, 1})
+	vm.AddVector("ai", []float32{1, 0})
+	vm.AddVector("Data", []float32{1, 1})
+	require.Equal(t, 3, vm.VocabularySize())
+
+	vm.SetNormalizer(NewNormalizer(NormalizationConfig{NFKC: true, CaseFold: true}))
+
+	// "ai" was already normalized, so it wins over "ＡＩ"
+	assert.Equal(t, 2, vm.VocabularySize())
+	vector, ok := vm.GetVector("ＡＩ")
+	require.True(t, ok)
+	assert.Equal(t, []float32{1, 0}, vector)
+
+	vector, ok = vm.GetVector("data")
+	require.True(t, ok)
+	assert.Equal(t, []float32{1, 1}, vector)
+}
+
+func TestEmbeddingLoader_Normalization(t *testing.T) {
+	content := "3 2\nＡＩ 1 0\n数据 0 1\nCafé 1 1\n"
+
+	loader := NewEmbeddingLoader(&mockLogger{})
+	loader.SetNormalizer(NewNormalizer(NormalizationConfig{NFKC: true, StripAccents: true, CaseFold: true}))
+
+	model, err := loader.LoadFromReader(strings.NewReader(content))
+	require.NoError(t, err)
+
+	for _, word := range []string{"ai", "AI", "ＡＩ", "数据", "cafe", "CAFÉ"} {
+		_, ok := model.GetVector(word)
+		assert.True(t, ok, word)
+	}
+	_, oov, _, _, _, _ := model.GetLookupStats()
+	assert.Equal(t, int64(0), oov)
+}
+
+func TestTextProcessor_Normalization(t *testing.T) {
+	processor := NewTextProcessor()
+	processor.SetNormalizer(NewNormalizer(NormalizationConfig{NFKC: true, FullWidth: true}))
+
+	tokens := processor.Preprocess("ＡＩ ｍｏｄｅｌｓ")
+	assert.Equal(t, []string{"ai", "models"}, tokens)
+
+	batch := processor.PreprocessBatch([]string{"ＡＩ ｍｏｄｅｌｓ"})
+	assert.Equal(t, [][]string{{"ai", "models"}}, batch)
+}
@@ -0,0 +1,161 @@
+package semanticmatcher
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"sort"
+)
+
+const (
+	// DefaultOOVTrackerCapacity is the number of distinct OOV words tracked by default
+	DefaultOOVTrackerCapacity = 1000
+
+	// DefaultTopOOVReportSize is the number of OOV words reported in MatcherStats
+	DefaultTopOOVReportSize = 20
+)
+
+// OOVWordStat describes a frequently looked-up out-of-vocabulary word
+type OOVWordStat struct {
+	Word string `json:"word"`
+
+	// Count is the (possibly over-estimated) number of OOV lookups for the word
+	Count int64 `json:"count"`
+
+	// Error is the maximum over-estimation of Count; Count-Error is a guaranteed lower bound
+	Error int64 `json:"error"`
+
+	// FallbackRescued is the number of lookups where fallback still produced a vector
+	FallbackRescued int64 `json:"fallback_rescued"`
+}
+
+// oovTracker finds the most frequent OOV words in bounded memory using the
+// Space-Saving algorithm (Metwally et al.). When the tracker is full, a new word
+// replaces the least frequent entry and inherits its count as the error bound.
+// The tracker is not thread-safe; callers must hold the model lock.
+type oovTracker struct {
+	capacity int
+	entries  map[string]*oovEntry
+	heap     oovEntryHeap
+}
+
+// oovEntry is a tracked word with its position in the min-heap
+type oovEntry struct {
+	stat  OOVWordStat
+	index int
+}
+
+// newOOVTracker creates a tracker holding at most capacity words
+func newOOVTracker(capacity int) *oovTracker {
+	if capacity <= 0 {
+		capacity = DefaultOOVTrackerCapacity
+	}
+
+	return &oovTracker{
+		capacity: capacity,
+		entries:  make(map[string]*oovEntry, capacity),
+		heap:     make(oovEntryHeap, 0, capacity),
+	}
+}
+
+// record counts one OOV lookup of word and whether fallback rescued it
+func (t *oovTracker) record(word string, rescued bool) {
+	rescuedCount := int64(0)
+	if rescued {
+		rescuedCount = 1
+	}
+
+	if entry, exists := t.entries[word]; exists {
+		entry.stat.Count++
+		entry.stat.FallbackRescued += rescuedCount
+		heap.Fix(&t.heap, entry.index)
+		return
+	}
+
+	if len(t.heap) < t.capacity {
+		entry := &oovEntry{stat: OOVWordStat{Word: word, Count: 1, FallbackRescued: rescuedCount}}
+		t.entries[word] = entry
+		heap.Push(&t.heap, entry)
+		return
+	}
+
+	// Replace the least frequent word; its count becomes the error bound of the new word
+	evicted := t.heap[0]
+	delete(t.entries, evicted.stat.Word)
+
+	evicted.stat = OOVWordStat{
+		Word:            word,
+		Count:           evicted.stat.Count + 1,
+		Error:           evicted.stat.Count,
+		FallbackRescued: rescuedCount,
+	}
+	t.entries[word] = evicted
+	heap.Fix(&t.heap, evicted.index)
+}
+
+// top returns the n most frequent OOV words sorted by count in descending order
+// If n <= 0, all tracked words are returned
+func (t *oovTracker) top(n int) []OOVWordStat {
+	results := make([]OOVWordStat, 0, len(t.heap))
+	for _, entry := range t.heap {
+		results = append(results, entry.stat)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		return results[i].Word < results[j].Word
+	})
+
+	if n > 0 && n < len(results) {
+		results = results[:n]
+	}
+	return results
+}
+
+// reset clears all tracked words
+func (t *oovTracker) reset() {
+	t.entries = make(map[string]*oovEntry, t.capacity)
+	t.heap = make(oovEntryHeap, 0, t.capacity)
+}
+
+// WriteOOVDictionary writes OOV words in the "word frequency" format accepted by
+// custom segmentation dictionaries (Config.DictPaths)
+func WriteOOVDictionary(w io.Writer, words []OOVWordStat) error {
+	writer := bufio.NewWriter(w)
+	for _, stat := range words {
+		if _, err := fmt.Fprintf(writer, "%s %d\n", stat.Word, stat.Count); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// oovEntryHeap is a min-heap of tracked words ordered by count
+type oovEntryHeap []*oovEntry
+
+func (h oovEntryHeap) Len() int { return len(h) }
+
+func (h oovEntryHeap) Less(i, j int) bool { return h[i].stat.Count < h[j].stat.Count }
+
+func (h oovEntryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *oovEntryHeap) Push(x any) {
+	entry := x.(*oovEntry) //nolint:errcheck,forcetypeassert
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *oovEntryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	*h = old[:n-1]
+	return entry
+}
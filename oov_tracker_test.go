@@ -0,0 +1,152 @@
+package semanticmatcher
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOOVTracker_ExactCountsWithinCapacity(t *testing.T) {
+	tracker := newOOVTracker(10)
+
+	for range 5 {
+		tracker.record("microservices", false)
+	}
+	for range 3 {
+		tracker.record("没事", true)
+	}
+	tracker.record("没事", false)
+	tracker.record("rare", false)
+
+	top := tracker.top(0)
+	require.Len(t, top, 3)
+
+	assert.Equal(t, OOVWordStat{Word: "microservices", Count: 5}, top[0])
+	assert.Equal(t, OOVWordStat{Word: "没事", Count: 4, FallbackRescued: 3}, top[1])
+	assert.Equal(t, OOVWordStat{Word: "rare", Count: 1}, top[2])
+
+	assert.Len(t, tracker.top(2), 2)
+}
+
+func TestOOVTracker_HeavyHittersSurviveEviction(t *testing.T) {
+	tracker := newOOVTracker(5)
+
+	// Two frequent words interleaved with a long tail of unique words
+	for i := range 200 {
+		tracker.record("frequent-a", false)
+		if i%2 == 0 {
+			tracker.record("frequent-b", true)
+		}
+		tracker.record(fmt.Sprintf("tail-%d", i), false)
+	}
+
+	top := tracker.top(2)
+	require.Len(t, top, 2)
+	assert.Equal(t, "frequent-a", top[0].Word)
+	assert.Equal(t, "frequent-b", top[1].Word)
+
+	// Space-Saving never under-estimates and bounds the over-estimation
+	assert.GreaterOrEqual(t, top[0].Count, int64(200))
+	assert.LessOrEqual(t, top[0].Count-top[0].Error, int64(200))
+	assert.Len(t, tracker.top(0), 5)
+}
+
+func TestOOVTracker_Reset(t *testing.T) {
+	tracker := newOOVTracker(3)
+	tracker.record("word", false)
+	tracker.reset()
+	assert.Empty(t, tracker.top(0))
+}
+
+func TestVectorModel_TopOOVWords(t *testing.T) {
+	vm := NewVectorModel(3).(*vectorModel)
+	vm.AddVector("没", []float32{1, 0, 0})
+	vm.AddVector("事", []float32{0, 1, 0})
+	vm.AddVector("known", []float32{0, 0, 1})
+
+	vm.GetVector("没事")    // rescued by character fallback
+	vm.GetVector("xyzzy") // not rescued
+	vm.GetVector("xyzzy")
+	vm.GetVector("known")
+	vm.GetAverageVector([]string{"known", "xyzzy", "没事"})
+
+	top := vm.TopOOVWords(10)
+	require.Len(t, top, 2)
+	assert.Equal(t, OOVWordStat{Word: "xyzzy", Count: 3}, top[0])
+	assert.Equal(t, OOVWordStat{Word: "没事", Count: 2, FallbackRescued: 2}, top[1])
+
+	vm.ResetStats()
+	assert.Empty(t, vm.TopOOVWords(10))
+}
+
+func TestVectorModel_SetOOVTrackerCapacity(t *testing.T) {
+	vm := NewVectorModel(3).(*vectorModel)
+	vm.SetOOVTrackerCapacity(2)
+
+	for _, word := range []string{"a1", "a1", "a1", "b2", "b2", "c3"} {
+		vm.GetVector(word)
+	}
+
+	top := vm.TopOOVWords(0)
+	require.Len(t, top, 2)
+	assert.Equal(t, "a1", top[0].Word)
+}
+
+func TestSemanticMatcher_StatsIncludeTopOOVWords(t *testing.T) {
+	matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(), NewSimilarityCalculator())
+
+	matcher.ComputeSimilarity("测试 kubernetes", "段落 kubernetes")
+
+	stats := matcher.GetStats()
+	require.NotEmpty(t, stats.TopOOVWords)
+	assert.Equal(t, "kubernetes", stats.TopOOVWords[0].Word)
+}
+
+func TestSemanticMatcher_ConcurrentGetStats(t *testing.T) {
+	matcher := newHybridTestMatcher()
+	matcher.ComputeSimilarity("docker kubernetes", "cluster helm")
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				stats := matcher.GetStats()
+				assert.Equal(t, "helm", stats.TopOOVWords[0].Word)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), matcher.GetStats().TotalRequests)
+}
+
+func TestSemanticMatcher_OOVWordsCountedOnce(t *testing.T) {
+	for _, metric := range []SimilarityMetric{CosineMetric{}, WMDMetric{}} {
+		matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(),
+			NewSimilarityCalculatorWithMetric(metric))
+
+		matcher.FindTopKeywords("测试 kubernetes", []string{"段落 kubernetes"}, 0)
+		matcher.ComputeSimilarity("测试 kubernetes", "段落")
+
+		stats := matcher.GetStats()
+		require.NotEmpty(t, stats.TopOOVWords, metric.Name())
+		assert.Equal(t, "kubernetes", stats.TopOOVWords[0].Word, metric.Name())
+		assert.Equal(t, int64(3), stats.TopOOVWords[0].Count, metric.Name())
+	}
+}
+
+func TestWriteOOVDictionary(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteOOVDictionary(&buf, []OOVWordStat{
+		{Word: "微服务", Count: 12},
+		{Word: "kubernetes", Count: 3},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "微服务 12\nkubernetes 3\n", buf.String())
+}
@@ -0,0 +1,113 @@
+package semanticmatcher
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultParallelThreshold is the number of work items (keywords, candidate vectors or matrix
+// cells) below which matching stays serial, as goroutines would cost more than they save
+const DefaultParallelThreshold = 1024
+
+// workerPool runs blocks of work on a bounded number of goroutines
+// The zero value uses GOMAXPROCS workers and DefaultParallelThreshold.
+type workerPool struct {
+	workers   int // Maximum goroutines per call; 0 uses GOMAXPROCS, 1 runs serially
+	threshold int // Minimum work items to run in parallel; 0 uses DefaultParallelThreshold
+}
+
+// newWorkerPool creates a pool of at most workers goroutines per call that runs work of fewer
+// than threshold items serially; zero values use the defaults
+func newWorkerPool(workers, threshold int) workerPool {
+	return workerPool{workers: max(workers, 0), threshold: max(threshold, 0)}
+}
+
+// run calls fn for consecutive blocks [start, end) of blockSize items covering [0, n), in
+// parallel if n reaches the threshold, and returns when all blocks are done
+// fn must be safe to call concurrently for different blocks; writing only to the items of its
+// block keeps results identical to serial execution.
+func (p workerPool) run(n, blockSize int, fn func(start, end int)) {
+	p.runWork(n, n, blockSize, fn)
+}
+
+// runWork is run for n items whose total work is work items, e.g. the cells of matrix rows
+func (p workerPool) runWork(work, n, blockSize int, fn func(start, end int)) {
+	_ = p.runWorkContext(context.Background(), work, n, blockSize, fn) //nolint:errcheck // never canceled
+}
+
+// runContext is run that starts no further blocks once ctx is done; it returns ctx.Err() if
+// blocks were skipped, so fn has to record which items it processed
+// Serial runs also call fn block by block, unless ctx can never be canceled.
+func (p workerPool) runContext(ctx context.Context, n, blockSize int, fn func(start, end int)) error {
+	return p.runWorkContext(ctx, n, n, blockSize, fn)
+}
+
+// runWorkContext is runContext for n items whose total work is work items
+func (p workerPool) runWorkContext(ctx context.Context, work, n, blockSize int, fn func(start, end int)) error {
+	if n <= 0 {
+		return nil
+	}
+	blockSize = max(blockSize, 1)
+	blocks := (n + blockSize - 1) / blockSize
+
+	workers := min(p.maxWorkers(), blocks)
+	if workers <= 1 || work < p.minWork() {
+		if ctx.Done() == nil {
+			fn(0, n)
+			return nil
+		}
+		for start := 0; start < n; start += blockSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			fn(start, min(start+blockSize, n))
+		}
+		return nil
+	}
+
+	var next atomic.Int64
+	var skipped atomic.Bool
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for {
+				block := int(next.Add(1)) - 1
+				if block >= blocks {
+					return
+				}
+				if ctx.Err() != nil {
+					skipped.Store(true)
+					return
+				}
+				start := block * blockSize
+				fn(start, min(start+blockSize, n))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if skipped.Load() {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// maxWorkers returns the number of goroutines to use at most
+func (p workerPool) maxWorkers() int {
+	if p.workers == 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return p.workers
+}
+
+// minWork returns the number of work items needed to run in parallel
+func (p workerPool) minWork() int {
+	if p.threshold == 0 {
+		return DefaultParallelThreshold
+	}
+	return p.threshold
+}
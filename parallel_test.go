@@ -0,0 +1,74 @@
+package semanticmatcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool_Run(t *testing.T) {
+	pools := []workerPool{{}, newWorkerPool(1, 0), newWorkerPool(3, 1), newWorkerPool(0, 1)}
+	for _, pool := range pools {
+		for _, tc := range []struct{ n, blockSize int }{{0, 4}, {1, 4}, {10, 3}, {5000, 64}, {5, 0}} {
+			var mtx sync.Mutex
+			visits := make([]int, tc.n)
+			pool.run(tc.n, tc.blockSize, func(start, end int) {
+				mtx.Lock()
+				defer mtx.Unlock()
+				for i := start; i < end; i++ {
+					visits[i]++
+				}
+			})
+
+			for i, count := range visits {
+				assert.Equal(t, 1, count, "pool %+v, n=%d, block size %d, item %d", pool, tc.n, tc.blockSize, i)
+			}
+		}
+	}
+}
+
+func TestWorkerPool_SerialBelowThreshold(t *testing.T) {
+	pool := newWorkerPool(4, 100)
+
+	calls := 0
+	pool.run(99, 10, func(start, end int) {
+		calls++
+		assert.Equal(t, 0, start)
+		assert.Equal(t, 99, end)
+	})
+	assert.Equal(t, 1, calls, "work below the threshold runs as one serial block")
+
+	calls = 0
+	newWorkerPool(1, 1).run(1000, 10, func(_, _ int) { calls++ })
+	assert.Equal(t, 1, calls, "a single worker runs serially")
+
+	var mtx sync.Mutex
+	calls = 0
+	pool.runWork(100, 10, 1, func(_, _ int) {
+		mtx.Lock()
+		calls++
+		mtx.Unlock()
+	})
+	assert.Equal(t, 10, calls, "work at the threshold runs in blocks")
+}
+
+func TestWorkerPool_RunContext(t *testing.T) {
+	for _, pool := range []workerPool{newWorkerPool(1, 0), newWorkerPool(3, 1)} {
+		assert.NoError(t, pool.runContext(context.Background(), 100, 10, func(int, int) {}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var mtx sync.Mutex
+		processed := 0
+		err := pool.runContext(ctx, 100, 10, func(start, end int) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			processed += end - start
+			cancel()
+		})
+
+		assert.ErrorIs(t, err, context.Canceled, "pool %+v", pool)
+		assert.Less(t, processed, 100, "pool %+v starts no blocks after cancellation", pool)
+	}
+}
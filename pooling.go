@@ -0,0 +1,231 @@
+package semanticmatcher
+
+import (
+	"fmt"
+	"math"
+)
+
+// Pooling strategy names accepted in Config.Pooling
+const (
+	PoolingMean             = "mean"
+	PoolingMax              = "max"
+	PoolingMinMax           = "min_max"
+	PoolingNormalizedSum    = "normalized_sum"
+	PoolingPositionWeighted = "position_weighted"
+)
+
+// DefaultPositionDecay is the decay used by PositionWeightedPooling when Decay is not positive
+const DefaultPositionDecay = 0.1
+
+// Pooling turns the vectors of a text's tokens into a single text vector.
+// Implementations must not modify the input vectors.
+type Pooling interface {
+	// Name identifies the strategy in configuration and logs
+	Name() string
+
+	// Pool combines token vectors of equal dimension, in token order
+	// Returns nil if vectors is empty
+	Pool(vectors [][]float32) []float32
+
+	// OutputDimension returns the dimension of pooled vectors for token vectors of dimension dim
+	OutputDimension(dim int) int
+}
+
+// MeanPooling averages the token vectors
+type MeanPooling struct{}
+
+// Name returns the strategy name
+func (MeanPooling) Name() string {
+	return PoolingMean
+}
+
+// Pool returns the element-wise mean
+func (MeanPooling) Pool(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	result := make([]float32, len(vectors[0]))
+	for _, vector := range vectors {
+		for i, val := range vector {
+			result[i] += val
+		}
+	}
+	for i := range result {
+		result[i] /= float32(len(vectors))
+	}
+	return result
+}
+
+// OutputDimension returns dim
+func (MeanPooling) OutputDimension(dim int) int {
+	return dim
+}
+
+// MaxPooling takes the element-wise maximum, keeping the strongest signal of each dimension
+type MaxPooling struct{}
+
+// Name returns the strategy name
+func (MaxPooling) Name() string {
+	return PoolingMax
+}
+
+// Pool returns the element-wise maximum
+func (MaxPooling) Pool(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	result := copyVector(vectors[0])
+	for _, vector := range vectors[1:] {
+		for i, val := range vector {
+			result[i] = max(result[i], val)
+		}
+	}
+	return result
+}
+
+// OutputDimension returns dim
+func (MaxPooling) OutputDimension(dim int) int {
+	return dim
+}
+
+// MinMaxPooling concatenates the element-wise minimum and maximum.
+// Pooled vectors have twice the token dimension.
+type MinMaxPooling struct{}
+
+// Name returns the strategy name
+func (MinMaxPooling) Name() string {
+	return PoolingMinMax
+}
+
+// Pool returns [min..., max...]
+func (MinMaxPooling) Pool(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	dim := len(vectors[0])
+	result := make([]float32, 2*dim)
+	copy(result[:dim], vectors[0])
+	copy(result[dim:], vectors[0])
+	for _, vector := range vectors[1:] {
+		for i, val := range vector {
+			result[i] = min(result[i], val)
+			result[dim+i] = max(result[dim+i], val)
+		}
+	}
+	return result
+}
+
+// OutputDimension returns 2 * dim
+func (MinMaxPooling) OutputDimension(dim int) int {
+	return 2 * dim
+}
+
+// NormalizedSumPooling sums the token vectors and scales the sum to unit length,
+// so pooled vectors are comparable by dot product regardless of text length
+type NormalizedSumPooling struct{}
+
+// Name returns the strategy name
+func (NormalizedSumPooling) Name() string {
+	return PoolingNormalizedSum
+}
+
+// Pool returns the L2-normalized sum, or the zero vector if the sum is zero
+func (NormalizedSumPooling) Pool(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	sum := make([]float64, len(vectors[0]))
+	for _, vector := range vectors {
+		for i, val := range vector {
+			sum[i] += float64(val)
+		}
+	}
+
+	var norm float64
+	for _, val := range sum {
+		norm += val * val
+	}
+	norm = math.Sqrt(norm)
+
+	result := make([]float32, len(sum))
+	if norm == 0 {
+		return result
+	}
+	for i, val := range sum {
+		result[i] = float32(val / norm)
+	}
+	return result
+}
+
+// OutputDimension returns dim
+func (NormalizedSumPooling) OutputDimension(dim int) int {
+	return dim
+}
+
+// PositionWeightedPooling computes a weighted mean where the token at position i has
+// weight 1 / (1 + Decay*i), so the beginning of a long paragraph (typically its topic)
+// counts more than its tail. A zero or negative Decay uses DefaultPositionDecay.
+type PositionWeightedPooling struct {
+	Decay float64
+}
+
+// Name returns the strategy name
+func (PositionWeightedPooling) Name() string {
+	return PoolingPositionWeighted
+}
+
+// Pool returns the position-weighted mean
+func (p PositionWeightedPooling) Pool(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	decay := p.Decay
+	if decay <= 0 {
+		decay = DefaultPositionDecay
+	}
+
+	sum := make([]float64, len(vectors[0]))
+	var totalWeight float64
+	for position, vector := range vectors {
+		weight := 1 / (1 + decay*float64(position))
+		for i, val := range vector {
+			sum[i] += weight * float64(val)
+		}
+		totalWeight += weight
+	}
+
+	result := make([]float32, len(sum))
+	for i, val := range sum {
+		result[i] = float32(val / totalWeight)
+	}
+	return result
+}
+
+// OutputDimension returns dim
+func (PositionWeightedPooling) OutputDimension(dim int) int {
+	return dim
+}
+
+// NewPooling returns the pooling strategy for a Config.Pooling name
+// An empty name returns MeanPooling
+func NewPooling(name string) (Pooling, error) {
+	switch name {
+	case "", PoolingMean:
+		return MeanPooling{}, nil
+	case PoolingMax:
+		return MaxPooling{}, nil
+	case PoolingMinMax:
+		return MinMaxPooling{}, nil
+	case PoolingNormalizedSum:
+		return NormalizedSumPooling{}, nil
+	case PoolingPositionWeighted:
+		return PositionWeightedPooling{}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown pooling strategy %q", ErrInvalidConfiguration, name)
+	}
+}
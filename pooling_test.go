@@ -0,0 +1,122 @@
+package semanticmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPooling_Pool(t *testing.T) {
+	vectors := [][]float32{
+		{1, -2, 3},
+		{3, 2, -1},
+	}
+
+	tests := []struct {
+		pooling  Pooling
+		expected []float32
+	}{
+		{MeanPooling{}, []float32{2, 0, 1}},
+		{MaxPooling{}, []float32{3, 2, 3}},
+		{MinMaxPooling{}, []float32{1, -2, -1, 3, 2, 3}},
+		{NormalizedSumPooling{}, []float32{4 / 4.472136, 0, 2 / 4.472136}},
+		// Weights 1 and 1/1.5
+		{PositionWeightedPooling{Decay: 0.5}, []float32{1.8, -0.4, 1.4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pooling.Name(), func(t *testing.T) {
+			pooled := tt.pooling.Pool(vectors)
+			assert.InDeltaSlice(t, tt.expected, pooled, 1e-5)
+			assert.Len(t, pooled, tt.pooling.OutputDimension(3))
+			assert.Nil(t, tt.pooling.Pool(nil))
+		})
+	}
+
+	// Inputs are not modified
+	assert.Equal(t, [][]float32{{1, -2, 3}, {3, 2, -1}}, vectors)
+}
+
+func TestPositionWeightedPooling_DefaultDecay(t *testing.T) {
+	vectors := [][]float32{{1}, {0}}
+	pooled := PositionWeightedPooling{}.Pool(vectors)
+	assert.InDelta(t, 1/(1+1/1.1), pooled[0], 1e-6)
+}
+
+func TestNormalizedSumPooling_ZeroSum(t *testing.T) {
+	pooled := NormalizedSumPooling{}.Pool([][]float32{{1, 1}, {-1, -1}})
+	assert.Equal(t, []float32{0, 0}, pooled)
+}
+
+func TestNewPooling(t *testing.T) {
+	for _, name := range []string{PoolingMean, PoolingMax, PoolingMinMax, PoolingNormalizedSum, PoolingPositionWeighted} {
+		pooling, err := NewPooling(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, pooling.Name())
+	}
+
+	pooling, err := NewPooling("")
+	require.NoError(t, err)
+	assert.Equal(t, PoolingMean, pooling.Name())
+
+	_, err = NewPooling("median")
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+}
+
+func TestVectorModel_GetPooledVector(t *testing.T) {
+	vm := NewVectorModel(2).(*vectorModel)
+	vm.AddVector("a", []float32{1, 0})
+	vm.AddVector("b", []float32{0, 1})
+
+	vector, ok := vm.GetPooledVector([]string{"a", "missing", "b"}, MinMaxPooling{})
+	require.True(t, ok)
+	assert.Equal(t, []float32{0, 0, 1, 1}, vector)
+
+	total, oov, hits, _, _, _ := vm.GetLookupStats()
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, int64(1), oov)
+	assert.Equal(t, int64(2), hits)
+
+	_, ok = vm.GetPooledVector([]string{"xyzzy"}, MaxPooling{})
+	assert.False(t, ok)
+	_, ok = vm.GetPooledVector(nil, MaxPooling{})
+	assert.False(t, ok)
+
+	// GetAverageVector is mean pooling
+	mean, ok := vm.GetAverageVector([]string{"a", "b"})
+	require.True(t, ok)
+	pooled, _ := vm.GetPooledVector([]string{"a", "b"}, MeanPooling{})
+	assert.Equal(t, mean, pooled)
+}
+
+func TestSemanticMatcher_PoolingOptions(t *testing.T) {
+	matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(), NewSimilarityCalculator())
+
+	paragraph := "这是一个测试段落"
+	keywords := []string{"测试", "段落", "关键词"}
+
+	for _, pooling := range []Pooling{
+		MeanPooling{}, MaxPooling{}, MinMaxPooling{}, NormalizedSumPooling{}, PositionWeightedPooling{},
+	} {
+		t.Run(pooling.Name(), func(t *testing.T) {
+			vector, ok := matcher.VectorizeTextWithOptions(paragraph, WithPooling(pooling))
+			require.True(t, ok)
+			assert.Len(t, vector, pooling.OutputDimension(3))
+
+			matches := matcher.FindTopKeywordsWithOptions(paragraph, keywords, 0, WithPooling(pooling))
+			require.Len(t, matches, len(keywords))
+			for _, match := range matches {
+				assert.Greater(t, match.Score, 0.0, match.Keyword)
+			}
+
+			similarity := matcher.ComputeSimilarityWithOptions(paragraph, paragraph, WithPooling(pooling))
+			assert.InDelta(t, 1.0, similarity, 1e-6)
+		})
+	}
+
+	// Mean pooling is the default
+	assert.Equal(t,
+		matcher.ComputeSimilarity(paragraph, "关键词"),
+		matcher.ComputeSimilarityWithOptions(paragraph, "关键词", WithPooling(MeanPooling{})))
+}
@@ -0,0 +1,252 @@
+package semanticmatcher
+
+// porterStem reduces an English word to its stem using the original Porter (1980) algorithm.
+// The word is expected in lowercase; words that are not plain ASCII letters, or that are
+// shorter than three letters, are returned unchanged.
+func porterStem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	s := &porterState{b: []byte(word)}
+	s.step1a()
+	s.step1b()
+	s.step1c()
+	s.step2()
+	s.step3()
+	s.step4()
+	s.step5()
+	return string(s.b)
+}
+
+// porterState holds the word being stemmed
+type porterState struct {
+	b []byte
+}
+
+// isConsonant reports whether b[i] is a consonant; y is a consonant when it
+// follows a vowel or starts the word
+func (s *porterState) isConsonant(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.isConsonant(i-1)
+	default:
+		return true
+	}
+}
+
+// measure returns m in the form [C](VC)^m[V] for the first n letters
+func (s *porterState) measure(n int) int {
+	m := 0
+	i := 0
+
+	// Skip the optional leading consonants
+	for i < n && s.isConsonant(i) {
+		i++
+	}
+	for i < n {
+		// Vowel sequence
+		for i < n && !s.isConsonant(i) {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		// Consonant sequence closes a VC pair
+		for i < n && s.isConsonant(i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+// hasVowel reports whether the first n letters contain a vowel
+func (s *porterState) hasVowel(n int) bool {
+	for i := range n {
+		if !s.isConsonant(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsDoubleConsonant reports whether the first n letters end with a double consonant
+func (s *porterState) endsDoubleConsonant(n int) bool {
+	return n >= 2 && s.b[n-1] == s.b[n-2] && s.isConsonant(n-1)
+}
+
+// endsCVC reports whether the first n letters end consonant-vowel-consonant,
+// where the final consonant is not w, x or y
+func (s *porterState) endsCVC(n int) bool {
+	if n < 3 || !s.isConsonant(n-1) || s.isConsonant(n-2) || !s.isConsonant(n-3) {
+		return false
+	}
+	switch s.b[n-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// hasSuffix reports whether the word ends with suffix
+func (s *porterState) hasSuffix(suffix string) bool {
+	return len(s.b) >= len(suffix) && string(s.b[len(s.b)-len(suffix):]) == suffix
+}
+
+// replaceSuffix replaces suffix (assumed present) with replacement
+func (s *porterState) replaceSuffix(suffix, replacement string) {
+	s.b = append(s.b[:len(s.b)-len(suffix)], replacement...)
+}
+
+// stemLen returns the length of the word without suffix
+func (s *porterState) stemLen(suffix string) int {
+	return len(s.b) - len(suffix)
+}
+
+// porterRule replaces suffix with replacement when the stem measure exceeds minMeasure
+type porterRule struct {
+	suffix      string
+	replacement string
+}
+
+// applyRules finds the longest matching suffix among rules and replaces it if the stem
+// measure is greater than minMeasure. Only one rule is ever considered per step.
+func (s *porterState) applyRules(rules []porterRule, minMeasure int) bool {
+	var match *porterRule
+	for i := range rules {
+		if s.hasSuffix(rules[i].suffix) && (match == nil || len(rules[i].suffix) > len(match.suffix)) {
+			match = &rules[i]
+		}
+	}
+	if match == nil || s.measure(s.stemLen(match.suffix)) <= minMeasure {
+		return false
+	}
+	s.replaceSuffix(match.suffix, match.replacement)
+	return true
+}
+
+// step1a removes plural suffixes
+func (s *porterState) step1a() {
+	switch {
+	case s.hasSuffix("sses"):
+		s.replaceSuffix("sses", "ss")
+	case s.hasSuffix("ies"):
+		s.replaceSuffix("ies", "i")
+	case s.hasSuffix("ss"):
+	case s.hasSuffix("s"):
+		s.replaceSuffix("s", "")
+	}
+}
+
+// step1b removes -ed and -ing and tidies up the remaining stem
+func (s *porterState) step1b() {
+	if s.hasSuffix("eed") {
+		if s.measure(s.stemLen("eed")) > 0 {
+			s.replaceSuffix("eed", "ee")
+		}
+		return
+	}
+
+	removed := false
+	for _, suffix := range []string{"ed", "ing"} {
+		if s.hasSuffix(suffix) && s.hasVowel(s.stemLen(suffix)) {
+			s.replaceSuffix(suffix, "")
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		return
+	}
+
+	n := len(s.b)
+	switch {
+	case s.hasSuffix("at"), s.hasSuffix("bl"), s.hasSuffix("iz"):
+		s.b = append(s.b, 'e')
+	case s.endsDoubleConsonant(n) && s.b[n-1] != 'l' && s.b[n-1] != 's' && s.b[n-1] != 'z':
+		s.b = s.b[:n-1]
+	case s.measure(n) == 1 && s.endsCVC(n):
+		s.b = append(s.b, 'e')
+	}
+}
+
+// step1c turns a terminal y into i when the stem contains a vowel
+func (s *porterState) step1c() {
+	if s.hasSuffix("y") && s.hasVowel(s.stemLen("y")) {
+		s.replaceSuffix("y", "i")
+	}
+}
+
+var porterStep2Rules = []porterRule{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"abli", "able"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+}
+
+// step2 maps double suffixes to single ones
+func (s *porterState) step2() {
+	s.applyRules(porterStep2Rules, 0)
+}
+
+var porterStep3Rules = []porterRule{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+// step3 removes -ful, -ness and similar suffixes
+func (s *porterState) step3() {
+	s.applyRules(porterStep3Rules, 0)
+}
+
+var porterStep4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+// step4 removes residual suffixes when the stem is long enough (m > 1)
+func (s *porterState) step4() {
+	longest := ""
+	for _, suffix := range porterStep4Suffixes {
+		if s.hasSuffix(suffix) && len(suffix) > len(longest) {
+			longest = suffix
+		}
+	}
+	if longest == "" {
+		return
+	}
+
+	n := s.stemLen(longest)
+	if s.measure(n) <= 1 {
+		return
+	}
+	if longest == "ion" && (n == 0 || (s.b[n-1] != 's' && s.b[n-1] != 't')) {
+		return
+	}
+	s.replaceSuffix(longest, "")
+}
+
+// step5 removes a final -e and reduces a final -ll
+func (s *porterState) step5() {
+	if s.hasSuffix("e") {
+		n := s.stemLen("e")
+		m := s.measure(n)
+		if m > 1 || (m == 1 && !s.endsCVC(n)) {
+			s.b = s.b[:n]
+		}
+	}
+
+	n := len(s.b)
+	if s.measure(n) > 1 && s.endsDoubleConsonant(n) && s.b[n-1] == 'l' {
+		s.b = s.b[:n-1]
+	}
+}
@@ -0,0 +1,56 @@
+package semanticmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPorterStem(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		// Step 1a
+		{"caresses", "caress"},
+		{"ponies", "poni"},
+		{"caress", "caress"},
+		{"cats", "cat"},
+		// Step 1b
+		{"feed", "feed"},
+		{"agreed", "agre"},
+		{"plastered", "plaster"},
+		{"bled", "bled"},
+		{"motoring", "motor"},
+		{"sing", "sing"},
+		{"conflated", "conflat"},
+		{"hopping", "hop"},
+		{"falling", "fall"},
+		{"filing", "file"},
+		// Step 1c
+		{"happy", "happi"},
+		{"sky", "sky"},
+		// Steps 2-5
+		{"relational", "relat"},
+		{"conditional", "condit"},
+		{"rational", "ration"},
+		{"digitizer", "digit"},
+		{"hopefulness", "hope"},
+		{"electrical", "electr"},
+		{"adjustment", "adjust"},
+		{"adoption", "adopt"},
+		{"generalizations", "gener"},
+		{"controlling", "control"},
+		{"roll", "roll"},
+		// Unchanged input
+		{"is", "is"},
+		{"Running", "Running"},
+		{"数据", "数据"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			assert.Equal(t, tt.expected, porterStem(tt.word))
+		})
+	}
+}
@@ -0,0 +1,523 @@
+package semanticmatcher
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	// DefaultMappingRefineWords is the number of most frequent words of each model searched
+	// for mutual nearest neighbors when a mapping is refined
+	DefaultMappingRefineWords = 5000
+
+	// DefaultMappingSearchWords is the number of most frequent target words PrecisionAtOne
+	// searches for the translation of each source word
+	DefaultMappingSearchWords = 200000
+
+	// mappingFormatVersion is bumped whenever the serialized mapping layout changes
+	mappingFormatVersion = 1
+
+	// mappingOrthogonalityTolerance bounds |WᵀW - I| for loaded mappings, above float32 rounding
+	mappingOrthogonalityTolerance = 1e-3
+
+	// mappingBlockSize is the number of queries per block of nearest neighbor searches
+	mappingBlockSize = 64
+)
+
+// VectorMappingConfig applies a saved OrthogonalMapping to one vector file at load time
+type VectorMappingConfig struct {
+	// VectorFile is a file of Config.VectorFilePaths or of a vector layer
+	VectorFile string `mapstructure:"vector_file"`
+
+	// Path is the mapping file written by OrthogonalMapping.SaveToFile
+	Path string `mapstructure:"path"`
+}
+
+// MappingOptions configures LearnOrthogonalMapping
+type MappingOptions struct {
+	// RefineIterations re-learns the mapping this many times from the seed pairs plus the
+	// mutual nearest neighbors of the mapped source and the target words. Zero learns from
+	// the seed pairs only.
+	RefineIterations int
+
+	// RefineWords is the number of most frequent words of each model searched for mutual
+	// nearest neighbors; zero uses DefaultMappingRefineWords
+	RefineWords int
+}
+
+// OrthogonalMapping rotates vectors of one space into another, y = W x with W orthogonal
+// (orthogonal Procrustes), so that e.g. unaligned Chinese and English vectors can be merged.
+// Norms and cosine similarities within the mapped space are preserved. It is immutable and
+// safe for concurrent use.
+type OrthogonalMapping struct {
+	matrix [][]float32 // Square, dimension rows of dimension values
+}
+
+// mappingSnapshot is the serialized form of an OrthogonalMapping
+type mappingSnapshot struct {
+	Version int
+	Matrix  [][]float32
+}
+
+// vectorPair is a unit-length source vector and the target vector it should map to
+type vectorPair struct {
+	source []float32
+	target []float32
+}
+
+// LoadBilingualDictionary reads a dictionary for LearnOrthogonalMapping and PrecisionAtOne from
+// a file where each line holds a source word followed by its translations, separated by
+// whitespace. Empty lines and lines starting with # are ignored, as in the synonym map.
+func LoadBilingualDictionary(path string) (map[string][]string, error) {
+	return loadWordListFile(path)
+}
+
+// LearnOrthogonalMapping learns the orthogonal map of source vectors onto target vectors that
+// best fits the dictionary of source words and their translations (Schönemann's solution
+// W = U Vᵀ of the SVD U S Vᵀ = Σ y xᵀ, over unit-length vectors). Only pairs with both words
+// in vocabulary count. With RefineIterations, the mapping is refined as in Conneau et al.,
+// "Word Translation Without Parallel Data", with mutual nearest neighbors as further pairs.
+func LearnOrthogonalMapping(
+	source, target VectorModel,
+	dictionary map[string][]string,
+	options MappingOptions,
+) (*OrthogonalMapping, error) {
+	sourceModel, ok := source.(layerModel)
+	targetModel, targetOK := target.(layerModel)
+	if !ok || !targetOK || sourceModel == nil || targetModel == nil {
+		return nil, fmt.Errorf("%w: mapping needs vector models of this package", ErrInvalidConfiguration)
+	}
+	if source.Dimension() != target.Dimension() {
+		return nil, ErrDimensionMismatch
+	}
+	if options.RefineIterations < 0 || options.RefineWords < 0 {
+		return nil, fmt.Errorf("%w: negative mapping refinement options", ErrInvalidConfiguration)
+	}
+	if options.RefineWords == 0 {
+		options.RefineWords = DefaultMappingRefineWords
+	}
+
+	seed := dictionaryPairs(sourceModel, targetModel, dictionary)
+	if len(seed) == 0 {
+		return nil, fmt.Errorf("%w: no dictionary pair in both vocabularies", ErrEmptyInput)
+	}
+
+	mapping := fitOrthogonalMapping(seed)
+	if options.RefineIterations == 0 {
+		return mapping, nil
+	}
+
+	_, sourceVectors := frequentVectors(source, options.RefineWords)
+	_, targetVectors := frequentVectors(target, options.RefineWords)
+	targets := NewCandidateSet(targetVectors)
+	for range options.RefineIterations {
+		mapped := mapping.applyAll(sourceVectors)
+		forward := nearestNeighbors(mapped, targets)
+		backward := nearestNeighbors(targetVectors, NewCandidateSet(mapped))
+
+		pairs := slices.Clip(seed)
+		for i, j := range forward {
+			if j >= 0 && backward[j] == i {
+				pairs = append(pairs, vectorPair{source: sourceVectors[i], target: targetVectors[j]})
+			}
+		}
+		mapping = fitOrthogonalMapping(pairs)
+	}
+	return mapping, nil
+}
+
+// dictionaryPairs returns the unit-length vectors of the dictionary pairs in both vocabularies,
+// in sorted order so that the same dictionary always gives the same mapping
+func dictionaryPairs(source, target layerModel, dictionary map[string][]string) []vectorPair {
+	words := make([]string, 0, len(dictionary))
+	for word := range dictionary {
+		words = append(words, word)
+	}
+	slices.Sort(words)
+
+	pairs := make([]vectorPair, 0, len(words))
+	for _, word := range words {
+		sourceVector, _, exists := source.lookupVector(word)
+		if !exists {
+			continue
+		}
+		for _, translation := range dictionary[word] {
+			if targetVector, _, exists := target.lookupVector(translation); exists {
+				pairs = append(pairs, vectorPair{
+					source: NormalizeVector(sourceVector),
+					target: NormalizeVector(targetVector),
+				})
+			}
+		}
+	}
+	return pairs
+}
+
+// frequentVectors returns up to n words of the model, ranked words first, and their
+// unit-length vectors
+func frequentVectors(model VectorModel, n int) ([]string, [][]float32) {
+	words := make([]string, 0, min(n, model.VocabularySize()))
+	vectors := make([][]float32, 0, cap(words))
+	for word, vector := range model.All() {
+		if len(words) == n {
+			break
+		}
+		words = append(words, word)
+		vectors = append(vectors, NormalizeVector(vector))
+	}
+	return words, vectors
+}
+
+// nearestNeighbors returns the index of the most similar candidate of each query, or -1 if
+// there are no candidates
+func nearestNeighbors(queries [][]float32, candidates *CandidateSet) []int {
+	nearest := make([]int, len(queries))
+	newWorkerPool(0, 0).run(len(queries), mappingBlockSize, func(start, end int) {
+		for i := start; i < end; i++ {
+			best, bestScore := -1, math.Inf(-1)
+			for j, score := range candidates.Similarities(queries[i]) {
+				if score > bestScore {
+					best, bestScore = j, score
+				}
+			}
+			nearest[i] = best
+		}
+	})
+	return nearest
+}
+
+// fitOrthogonalMapping returns the orthogonal mapping that best maps the pairs' source vectors
+// onto their target vectors
+func fitOrthogonalMapping(pairs []vectorPair) *OrthogonalMapping {
+	dimension := len(pairs[0].source)
+	correlation := make([][]float64, dimension)
+	for i := range correlation {
+		correlation[i] = make([]float64, dimension)
+	}
+	for _, pair := range pairs {
+		for i, y := range pair.target {
+			row := correlation[i]
+			for j, x := range pair.source {
+				row[j] += float64(y) * float64(x)
+			}
+		}
+	}
+
+	factor := orthogonalFactor(correlation)
+	matrix := make([][]float32, dimension)
+	for i, row := range factor {
+		matrix[i] = make([]float32, dimension)
+		for j, val := range row {
+			matrix[i][j] = float32(val)
+		}
+	}
+	return &OrthogonalMapping{matrix: matrix}
+}
+
+// orthogonalFactor returns U Vᵀ for the singular value decomposition m = U S Vᵀ of a square
+// matrix. V and S² are the eigenvectors and eigenvalues of mᵀm, and the columns of U are
+// m v / s. Columns for vanishing singular values complete U to an orthonormal basis.
+func orthogonalFactor(m [][]float64) [][]float64 {
+	n := len(m)
+	gram := make([][]float64, n)
+	for i := range gram {
+		gram[i] = make([]float64, n)
+		for j := range n {
+			for k := range n {
+				gram[i][j] += m[k][i] * m[k][j]
+			}
+		}
+	}
+	eigenvalues, v := symmetricEigen(gram)
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case eigenvalues[a] > eigenvalues[b]:
+			return -1
+		case eigenvalues[a] < eigenvalues[b]:
+			return 1
+		default:
+			return 0
+		}
+	})
+	minNorm := 1e-9 * math.Sqrt(max(eigenvalues[order[0]], 0))
+
+	u := make([][]float64, 0, n) // Columns of U
+	for _, j := range order {
+		column := make([]float64, n)
+		for r := range n {
+			for c := range n {
+				column[r] += m[r][c] * v[c][j]
+			}
+		}
+		if !orthonormalize(column, u, minNorm) {
+			column = completingVector(u, n)
+		}
+		u = append(u, column)
+	}
+
+	w := make([][]float64, n)
+	for r := range w {
+		w[r] = make([]float64, n)
+		for c := range n {
+			for k, j := range order {
+				w[r][c] += u[k][r] * v[c][j]
+			}
+		}
+	}
+	return w
+}
+
+// orthonormalize removes the components of the orthonormal basis from vector (twice, for
+// stability) and scales it to unit length; it returns false if less than minNorm remains
+func orthonormalize(vector []float64, basis [][]float64, minNorm float64) bool {
+	for range 2 {
+		for _, b := range basis {
+			var dot float64
+			for i, val := range vector {
+				dot += val * b[i]
+			}
+			for i := range vector {
+				vector[i] -= dot * b[i]
+			}
+		}
+	}
+
+	var norm float64
+	for _, val := range vector {
+		norm += val * val
+	}
+	norm = math.Sqrt(norm)
+	if norm <= minNorm {
+		return false
+	}
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return true
+}
+
+// completingVector returns the unit vector orthogonal to the orthonormal basis that is left
+// of the standard basis vector with the largest component outside the basis
+func completingVector(basis [][]float64, n int) []float64 {
+	var best []float64
+	bestNorm := -1.0
+	for k := range n {
+		candidate := make([]float64, n)
+		candidate[k] = 1
+		if orthonormalize(candidate, basis, 0) {
+			// Unit length now; the removed part is the projection onto the basis
+			var norm float64
+			for _, b := range basis {
+				norm += b[k] * b[k]
+			}
+			if remaining := 1 - norm; remaining > bestNorm {
+				best, bestNorm = candidate, remaining
+			}
+		}
+	}
+	return best
+}
+
+// Dimension returns the dimension of the vectors the mapping accepts and returns
+func (m *OrthogonalMapping) Dimension() int {
+	return len(m.matrix)
+}
+
+// Apply maps vector; it returns nil if vector does not have the mapping's dimension
+func (m *OrthogonalMapping) Apply(vector []float32) []float32 {
+	if len(vector) != m.Dimension() {
+		return nil
+	}
+
+	result := make([]float32, len(m.matrix))
+	for i, row := range m.matrix {
+		result[i] = float32(DotProduct(row, vector))
+	}
+	return result
+}
+
+// applyAll maps each vector
+func (m *OrthogonalMapping) applyAll(vectors [][]float32) [][]float32 {
+	mapped := make([][]float32, len(vectors))
+	for i, vector := range vectors {
+		mapped[i] = m.Apply(vector)
+	}
+	return mapped
+}
+
+// PrecisionAtOne evaluates the mapping on a held-out dictionary: the share of source words
+// whose mapped vector is nearest to one of their translations among the searchWords most
+// frequent target words (zero uses DefaultMappingSearchWords). Only source words in the
+// source vocabulary with a translation in the target vocabulary are evaluated; their number
+// is returned with the precision.
+func (m *OrthogonalMapping) PrecisionAtOne(
+	source, target VectorModel,
+	dictionary map[string][]string,
+	searchWords int,
+) (float64, int, error) {
+	sourceModel, ok := source.(layerModel)
+	targetModel, targetOK := target.(layerModel)
+	if !ok || !targetOK || sourceModel == nil || targetModel == nil {
+		return 0, 0, fmt.Errorf("%w: evaluation needs vector models of this package", ErrInvalidConfiguration)
+	}
+	if source.Dimension() != m.Dimension() || target.Dimension() != m.Dimension() {
+		return 0, 0, ErrDimensionMismatch
+	}
+	if searchWords <= 0 {
+		searchWords = DefaultMappingSearchWords
+	}
+
+	queries := make([][]float32, 0, len(dictionary))
+	translations := make([][]string, 0, len(dictionary))
+	for word, words := range dictionary {
+		vector, _, exists := sourceModel.lookupVector(word)
+		if !exists || !slices.ContainsFunc(words, func(translation string) bool {
+			_, _, exists := targetModel.lookupVector(translation)
+			return exists
+		}) {
+			continue
+		}
+		queries = append(queries, m.Apply(vector))
+		translations = append(translations, words)
+	}
+	if len(queries) == 0 {
+		return 0, 0, fmt.Errorf("%w: no dictionary pair in both vocabularies", ErrEmptyInput)
+	}
+
+	candidates, candidateVectors := frequentVectors(target, searchWords)
+	normalizer := targetModel.keyNormalizer()
+	hits := 0
+	for i, nearest := range nearestNeighbors(queries, NewCandidateSet(candidateVectors)) {
+		if nearest < 0 {
+			continue
+		}
+		if slices.ContainsFunc(translations[i], func(translation string) bool {
+			return normalizer.Normalize(translation) == candidates[nearest]
+		}) {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(queries)), len(queries), nil
+}
+
+// Save writes the mapping to w in a binary (gob) format
+func (m *OrthogonalMapping) Save(w io.Writer) error {
+	snapshot := mappingSnapshot{Version: mappingFormatVersion, Matrix: m.matrix}
+	if err := gob.NewEncoder(w).Encode(&snapshot); err != nil {
+		return fmt.Errorf("failed to encode vector mapping: %w", err)
+	}
+	return nil
+}
+
+// SaveToFile writes the mapping to a file, replacing any existing file
+func (m *OrthogonalMapping) SaveToFile(path string) error {
+	file, err := os.Create(path) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to create vector mapping file: %w", err)
+	}
+
+	if err := m.Save(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadOrthogonalMapping reads a mapping previously written with Save and checks that its
+// matrix is orthogonal
+func LoadOrthogonalMapping(r io.Reader) (*OrthogonalMapping, error) {
+	var snapshot mappingSnapshot
+	if err := gob.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode vector mapping: %w", ErrInvalidMappingFormat, err)
+	}
+
+	if snapshot.Version != mappingFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidMappingFormat, snapshot.Version)
+	}
+	n := len(snapshot.Matrix)
+	if n == 0 {
+		return nil, fmt.Errorf("%w: empty mapping matrix", ErrInvalidMappingFormat)
+	}
+	for _, row := range snapshot.Matrix {
+		if len(row) != n {
+			return nil, fmt.Errorf("%w: mapping matrix is not square", ErrInvalidMappingFormat)
+		}
+	}
+	for i := range n {
+		for j := i; j < n; j++ {
+			expected := 0.0
+			if i == j {
+				expected = 1
+			}
+			if math.Abs(DotProduct(snapshot.Matrix[i], snapshot.Matrix[j])-expected) > mappingOrthogonalityTolerance {
+				return nil, fmt.Errorf("%w: mapping matrix is not orthogonal", ErrInvalidMappingFormat)
+			}
+		}
+	}
+
+	return &OrthogonalMapping{matrix: snapshot.Matrix}, nil
+}
+
+// LoadOrthogonalMappingFromFile reads a mapping from a file written with SaveToFile
+func LoadOrthogonalMappingFromFile(path string) (*OrthogonalMapping, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vector mapping file: %w", err)
+	}
+	defer file.Close()
+
+	return LoadOrthogonalMapping(file)
+}
+
+// loadVectorMappings loads the mappings of Config.VectorMappings, keyed by cleaned vector file path
+func loadVectorMappings(configs []VectorMappingConfig, logger Logger) (map[string]*OrthogonalMapping, error) {
+	mappings := make(map[string]*OrthogonalMapping, len(configs))
+	for _, config := range configs {
+		mapping, err := LoadOrthogonalMappingFromFile(config.Path)
+		if err != nil {
+			logger.Errorf("Failed to load vector mapping, path: %s, error: %v", config.Path, err)
+			return nil, err
+		}
+		mappings[filepath.Clean(config.VectorFile)] = mapping
+		logger.Infof("Vector mapping loaded, vector_file: %s, path: %s, dimension: %d",
+			config.VectorFile, config.Path, mapping.Dimension())
+	}
+	return mappings, nil
+}
+
+// validateVectorMappings checks that each mapping names a mapping file and a distinct vector
+// file of the configuration
+func validateVectorMappings(config *Config) error {
+	vectorFiles := make(map[string]bool)
+	for _, path := range config.VectorFilePaths {
+		vectorFiles[filepath.Clean(path)] = true
+	}
+	for _, layer := range config.VectorLayers {
+		for _, path := range layer.VectorFilePaths {
+			vectorFiles[filepath.Clean(path)] = true
+		}
+	}
+
+	mapped := make(map[string]bool, len(config.VectorMappings))
+	for _, mapping := range config.VectorMappings {
+		if mapping.Path == "" || mapping.VectorFile == "" {
+			return ErrInvalidConfiguration
+		}
+		vectorFile := filepath.Clean(mapping.VectorFile)
+		if !vectorFiles[vectorFile] || mapped[vectorFile] {
+			return ErrInvalidConfiguration
+		}
+		mapped[vectorFile] = true
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package semanticmatcher
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	procrustesTestDimension = 8
+	procrustesTestWords     = 40
+)
+
+// randomRotation returns a random orthogonal matrix, from Gram-Schmidt on Gaussian rows
+func randomRotation(rng *rand.Rand, n int) [][]float64 {
+	rotation := make([][]float64, 0, n)
+	for len(rotation) < n {
+		row := make([]float64, n)
+		for i := range row {
+			row[i] = rng.NormFloat64()
+		}
+		if orthonormalize(row, rotation, 1e-6) {
+			rotation = append(rotation, row)
+		}
+	}
+	return rotation
+}
+
+func rotate(rotation [][]float64, vector []float32) []float32 {
+	result := make([]float32, len(rotation))
+	for i, row := range rotation {
+		sum := 0.0
+		for j, value := range row {
+			sum += value * float64(vector[j])
+		}
+		result[i] = float32(sum)
+	}
+	return result
+}
+
+// procrustesTestModels returns a source model of words s0, s1, ... and a target model where
+// each translation t0, t1, ... is the rotated source vector
+func procrustesTestModels(t *testing.T) (VectorModel, VectorModel, [][]float64) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(7)) //nolint:gosec
+	rotation := randomRotation(rng, procrustesTestDimension)
+
+	source := NewVectorModel(procrustesTestDimension).(*vectorModel) //nolint:errcheck,forcetypeassert
+	target := NewVectorModel(procrustesTestDimension).(*vectorModel) //nolint:errcheck,forcetypeassert
+	for i := range procrustesTestWords {
+		vector := make([]float32, procrustesTestDimension)
+		for j := range vector {
+			vector[j] = float32(rng.NormFloat64())
+		}
+		source.AddVector(fmt.Sprintf("s%d", i), vector)
+		target.AddVector(fmt.Sprintf("t%d", i), rotate(rotation, vector))
+	}
+	return source, target, rotation
+}
+
+// procrustesTestDictionary maps s<i> to t<i+shift> for i in [start, end)
+func procrustesTestDictionary(start, end, shift int) map[string][]string {
+	dictionary := make(map[string][]string, end-start)
+	for i := start; i < end; i++ {
+		dictionary[fmt.Sprintf("s%d", i)] = []string{fmt.Sprintf("t%d", (i+shift)%procrustesTestWords)}
+	}
+	return dictionary
+}
+
+func TestLearnOrthogonalMapping(t *testing.T) {
+	source, target, rotation := procrustesTestModels(t)
+
+	mapping, err := LearnOrthogonalMapping(source, target, procrustesTestDictionary(0, 20, 0), MappingOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, procrustesTestDimension, mapping.Dimension())
+
+	// The rotation is recovered, so held-out words map onto their translations
+	for i := 20; i < procrustesTestWords; i++ {
+		vector, _ := source.GetVector(fmt.Sprintf("s%d", i))
+		assert.InDeltaSlice(t, rotate(rotation, vector), mapping.Apply(vector), 1e-4)
+	}
+
+	heldOut := procrustesTestDictionary(20, procrustesTestWords, 0)
+	precision, count, err := mapping.PrecisionAtOne(source, target, heldOut, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 20, count)
+	assert.InDelta(t, 1.0, precision, 1e-9)
+
+	// Words out of vocabulary are not evaluated
+	dictionary := procrustesTestDictionary(20, 30, 0)
+	dictionary["missing"] = []string{"t1"}
+	dictionary["s1"] = []string{"missing"}
+	_, count, err = mapping.PrecisionAtOne(source, target, dictionary, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 10, count)
+
+	assert.Nil(t, mapping.Apply([]float32{1, 2}))
+}
+
+func TestLearnOrthogonalMapping_Refinement(t *testing.T) {
+	source, target, _ := procrustesTestModels(t)
+	heldOut := procrustesTestDictionary(0, procrustesTestWords, 0)
+
+	// A seed with a third of its pairs wrong
+	seed := procrustesTestDictionary(0, 12, 0)
+	for word, translations := range procrustesTestDictionary(12, 18, 1) {
+		seed[word] = translations
+	}
+
+	mapping, err := LearnOrthogonalMapping(source, target, seed, MappingOptions{})
+	require.NoError(t, err)
+	before, _, err := mapping.PrecisionAtOne(source, target, heldOut, 0)
+	require.NoError(t, err)
+
+	refined, err := LearnOrthogonalMapping(source, target, seed, MappingOptions{RefineIterations: 3})
+	require.NoError(t, err)
+	after, _, err := refined.PrecisionAtOne(source, target, heldOut, 0)
+	require.NoError(t, err)
+
+	assert.Less(t, before, 1.0)
+	assert.Greater(t, after, before)
+}
+
+func TestLearnOrthogonalMapping_Errors(t *testing.T) {
+	source, target, _ := procrustesTestModels(t)
+	dictionary := procrustesTestDictionary(0, 10, 0)
+
+	_, err := LearnOrthogonalMapping(source, NewVectorModel(2), dictionary, MappingOptions{})
+	assert.ErrorIs(t, err, ErrDimensionMismatch)
+
+	_, err = LearnOrthogonalMapping(source, target, map[string][]string{"s1": {"missing"}}, MappingOptions{})
+	assert.ErrorIs(t, err, ErrEmptyInput)
+
+	_, err = LearnOrthogonalMapping(source, target, dictionary, MappingOptions{RefineIterations: -1})
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	mapping, err := LearnOrthogonalMapping(source, target, dictionary, MappingOptions{})
+	require.NoError(t, err)
+	_, _, err = mapping.PrecisionAtOne(source, NewVectorModel(2), dictionary, 0)
+	assert.ErrorIs(t, err, ErrDimensionMismatch)
+}
+
+func TestOrthogonalMapping_SaveLoad(t *testing.T) {
+	source, target, _ := procrustesTestModels(t)
+	mapping, err := LearnOrthogonalMapping(source, target, procrustesTestDictionary(0, 20, 0), MappingOptions{})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "mapping.bin")
+	require.NoError(t, mapping.SaveToFile(path))
+	loaded, err := LoadOrthogonalMappingFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, mapping, loaded)
+
+	_, err = LoadOrthogonalMapping(bytes.NewBufferString("not a mapping"))
+	assert.ErrorIs(t, err, ErrInvalidMappingFormat)
+
+	for _, snapshot := range []mappingSnapshot{
+		{Version: mappingFormatVersion + 1, Matrix: [][]float32{{1}}},
+		{Version: mappingFormatVersion},
+		{Version: mappingFormatVersion, Matrix: [][]float32{{1, 0}, {0}}},
+		{Version: mappingFormatVersion, Matrix: [][]float32{{2, 0}, {0, 1}}},
+		{Version: mappingFormatVersion, Matrix: [][]float32{{1, 0}, {1, 0}}},
+	} {
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(&snapshot))
+		_, err := LoadOrthogonalMapping(&buf)
+		assert.ErrorIs(t, err, ErrInvalidMappingFormat, snapshot)
+	}
+}
+
+func TestEmbeddingLoader_VectorMapping(t *testing.T) {
+	source, target, _ := procrustesTestModels(t)
+	mapping, err := LearnOrthogonalMapping(source, target, procrustesTestDictionary(0, 20, 0), MappingOptions{})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "source.vec")
+	targetPath := filepath.Join(dir, "target.vec")
+	for path, model := range map[string]VectorModel{sourcePath: source, targetPath: target} {
+		file, err := os.Create(path)
+		require.NoError(t, err)
+		_, err = model.WriteVec(file)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+	}
+
+	loader := NewEmbeddingLoader(DiscardLogger{})
+	loader.SetVectorMapping(dir+"/./source.vec", mapping)
+	merged, err := loader.LoadMultipleFiles([]string{targetPath, sourcePath})
+	require.NoError(t, err)
+
+	// Mapped source words sit next to their translations; target words are unchanged
+	mapped, _ := merged.GetVector("s25")
+	translation, _ := merged.GetVector("t25")
+	assert.InDeltaSlice(t, translation, mapped, 1e-4)
+	original, _ := target.GetVector("t25")
+	assert.Equal(t, original, translation)
+
+	// Only files loaded by path are mapped
+	file, err := os.Open(sourcePath)
+	require.NoError(t, err)
+	defer file.Close()
+	unmapped, err := loader.LoadFromReader(file)
+	require.NoError(t, err)
+	vector, _ := unmapped.GetVector("s25")
+	original, _ = source.GetVector("s25")
+	assert.InDeltaSlice(t, original, vector, 1e-6)
+
+	loader.SetVectorMapping(sourcePath, &OrthogonalMapping{matrix: [][]float32{{1}}})
+	_, err = loader.LoadFromFile(sourcePath)
+	require.ErrorIs(t, err, ErrDimensionMismatch)
+
+	loader.SetVectorMapping(sourcePath, nil)
+	model, err := loader.LoadFromFile(sourcePath)
+	require.NoError(t, err)
+	vector, _ = model.GetVector("s25")
+	assert.InDeltaSlice(t, original, vector, 1e-6)
+}
@@ -0,0 +1,532 @@
+package semanticmatcher
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Projection method names accepted in ProjectionConfig.Method
+const (
+	ProjectionPCA    = "pca"
+	ProjectionRandom = "random"
+)
+
+// DefaultPCASampleSize is the number of vectors PCA is fitted on when SampleSize is not positive
+const DefaultPCASampleSize = 20000
+
+// projectionFormatVersion is bumped whenever the serialized projection layout changes
+const projectionFormatVersion = 1
+
+// jacobiMaxSweeps bounds the cyclic Jacobi eigenvalue iteration used by PCA
+const jacobiMaxSweeps = 100
+
+// ProjectionConfig selects a dimensionality reduction applied to all vector files at load time
+//
+// If Path names an existing file, the saved projection is loaded, so models built later stay
+// compatible with earlier ones. Otherwise a projection is built with Method and Dimension and,
+// if Path is set, saved there. PCA projections must be saved.
+type ProjectionConfig struct {
+	Method     string `mapstructure:"method"`      // "pca", "random" or "" (load Path only)
+	Dimension  int    `mapstructure:"dimension"`   // Output dimension
+	Path       string `mapstructure:"path"`        // Saved projection file
+	SampleSize int    `mapstructure:"sample_size"` // PCA sample size; 0 uses DefaultPCASampleSize
+	Seed       int64  `mapstructure:"seed"`        // Seed of the random projection matrix
+}
+
+// Enabled reports whether a projection is configured
+func (c ProjectionConfig) Enabled() bool {
+	return c.Method != "" || c.Path != ""
+}
+
+// Projection is a linear map from inputDim to outputDim dimensions: y = W (x - mean)
+// It is immutable and safe for concurrent use.
+type Projection struct {
+	method string
+	mean   []float32   // Subtracted before projecting; nil for random projections
+	matrix [][]float32 // outputDim rows of inputDim values
+}
+
+// projectionSnapshot is the serialized form of a Projection
+type projectionSnapshot struct {
+	Version int
+	Method  string
+	Mean    []float32
+	Matrix  [][]float32
+}
+
+// NewRandomProjection returns a Gaussian random projection with entries drawn from
+// N(0, 1/outputDim), which approximately preserves distances and angles
+// (Johnson-Lindenstrauss). The same seed always gives the same projection.
+func NewRandomProjection(inputDim, outputDim int, seed int64) (*Projection, error) {
+	if inputDim <= 0 || outputDim <= 0 || outputDim > inputDim {
+		return nil, fmt.Errorf("%w: cannot project %d dimensions to %d",
+			ErrInvalidConfiguration, inputDim, outputDim)
+	}
+
+	rng := rand.New(rand.NewSource(seed)) //nolint:gosec // reproducibility, not security
+	scale := 1 / math.Sqrt(float64(outputDim))
+
+	matrix := make([][]float32, outputDim)
+	for i := range matrix {
+		matrix[i] = make([]float32, inputDim)
+		for j := range matrix[i] {
+			matrix[i][j] = float32(rng.NormFloat64() * scale)
+		}
+	}
+
+	return &Projection{method: ProjectionRandom, matrix: matrix}, nil
+}
+
+// FitPCAProjection fits a PCA projection onto the outputDim principal components of a
+// sample of vectors. Projected vectors are centered on the sample mean.
+func FitPCAProjection(sample [][]float32, outputDim int) (*Projection, error) {
+	if len(sample) < 2 {
+		return nil, fmt.Errorf("%w: PCA needs at least 2 sample vectors", ErrEmptyInput)
+	}
+
+	inputDim := len(sample[0])
+	if outputDim <= 0 || outputDim > inputDim {
+		return nil, fmt.Errorf("%w: cannot project %d dimensions to %d",
+			ErrInvalidConfiguration, inputDim, outputDim)
+	}
+	for _, vector := range sample {
+		if len(vector) != inputDim {
+			return nil, ErrDimensionMismatch
+		}
+	}
+
+	mean := make([]float64, inputDim)
+	for _, vector := range sample {
+		for i, val := range vector {
+			mean[i] += float64(val)
+		}
+	}
+	for i := range mean {
+		mean[i] /= float64(len(sample))
+	}
+
+	// Sample covariance matrix (upper triangle, mirrored afterwards)
+	covariance := make([][]float64, inputDim)
+	for i := range covariance {
+		covariance[i] = make([]float64, inputDim)
+	}
+	centered := make([]float64, inputDim)
+	for _, vector := range sample {
+		for i, val := range vector {
+			centered[i] = float64(val) - mean[i]
+		}
+		for i := range inputDim {
+			row := covariance[i]
+			ci := centered[i]
+			for j := i; j < inputDim; j++ {
+				row[j] += ci * centered[j]
+			}
+		}
+	}
+	for i := range inputDim {
+		for j := i; j < inputDim; j++ {
+			covariance[i][j] /= float64(len(sample) - 1)
+			covariance[j][i] = covariance[i][j]
+		}
+	}
+
+	eigenvalues, eigenvectors := symmetricEigen(covariance)
+
+	order := make([]int, inputDim)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case eigenvalues[a] > eigenvalues[b]:
+			return -1
+		case eigenvalues[a] < eigenvalues[b]:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	matrix := make([][]float32, outputDim)
+	for row := range matrix {
+		column := order[row]
+		matrix[row] = make([]float32, inputDim)
+
+		// Fix the sign so the largest component is positive, making the result deterministic
+		var largest float64
+		for k := range inputDim {
+			if math.Abs(eigenvectors[k][column]) > math.Abs(largest) {
+				largest = eigenvectors[k][column]
+			}
+		}
+		sign := 1.0
+		if largest < 0 {
+			sign = -1
+		}
+		for k := range inputDim {
+			matrix[row][k] = float32(sign * eigenvectors[k][column])
+		}
+	}
+
+	meanVector := make([]float32, inputDim)
+	for i, val := range mean {
+		meanVector[i] = float32(val)
+	}
+
+	return &Projection{method: ProjectionPCA, mean: meanVector, matrix: matrix}, nil
+}
+
+// symmetricEigen computes the eigenvalues of the symmetric matrix a and its eigenvectors,
+// stored as the columns of the returned matrix, with the cyclic Jacobi method.
+// a is overwritten.
+func symmetricEigen(a [][]float64) ([]float64, [][]float64) {
+	n := len(a)
+	v := make([][]float64, n)
+	for i := range v {
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+
+	for range jacobiMaxSweeps {
+		var offDiagonal, diagonal float64
+		for p := range n {
+			diagonal += a[p][p] * a[p][p]
+			for q := p + 1; q < n; q++ {
+				offDiagonal += a[p][q] * a[p][q]
+			}
+		}
+		if offDiagonal <= 1e-24*diagonal || offDiagonal == 0 {
+			break
+		}
+
+		for p := range n {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := range n {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := range n {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := range n {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+
+	eigenvalues := make([]float64, n)
+	for i := range n {
+		eigenvalues[i] = a[i][i]
+	}
+	return eigenvalues, v
+}
+
+// Method returns "pca" or "random"
+func (p *Projection) Method() string {
+	return p.method
+}
+
+// InputDimension returns the dimension of vectors the projection accepts
+func (p *Projection) InputDimension() int {
+	return len(p.matrix[0])
+}
+
+// OutputDimension returns the dimension of projected vectors
+func (p *Projection) OutputDimension() int {
+	return len(p.matrix)
+}
+
+// Apply projects vector; it returns nil if vector does not have the input dimension
+func (p *Projection) Apply(vector []float32) []float32 {
+	if len(vector) != p.InputDimension() {
+		return nil
+	}
+
+	input := vector
+	if p.mean != nil {
+		input = make([]float32, len(vector))
+		for i, val := range vector {
+			input[i] = val - p.mean[i]
+		}
+	}
+
+	result := make([]float32, len(p.matrix))
+	for i, row := range p.matrix {
+		result[i] = float32(DotProduct(row, input))
+	}
+	return result
+}
+
+// Save writes the projection to w in a binary (gob) format
+func (p *Projection) Save(w io.Writer) error {
+	snapshot := projectionSnapshot{
+		Version: projectionFormatVersion,
+		Method:  p.method,
+		Mean:    p.mean,
+		Matrix:  p.matrix,
+	}
+
+	if err := gob.NewEncoder(w).Encode(&snapshot); err != nil {
+		return fmt.Errorf("failed to encode projection: %w", err)
+	}
+	return nil
+}
+
+// SaveToFile writes the projection to a file, replacing any existing file
+func (p *Projection) SaveToFile(path string) error {
+	file, err := os.Create(path) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to create projection file: %w", err)
+	}
+
+	if err := p.Save(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadProjection reads a projection previously written with Save
+func LoadProjection(r io.Reader) (*Projection, error) {
+	var snapshot projectionSnapshot
+	if err := gob.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode projection: %w", ErrInvalidProjectionFormat, err)
+	}
+
+	if snapshot.Version != projectionFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidProjectionFormat, snapshot.Version)
+	}
+	if len(snapshot.Matrix) == 0 || len(snapshot.Matrix[0]) == 0 {
+		return nil, fmt.Errorf("%w: empty projection matrix", ErrInvalidProjectionFormat)
+	}
+	inputDim := len(snapshot.Matrix[0])
+	for _, row := range snapshot.Matrix {
+		if len(row) != inputDim {
+			return nil, fmt.Errorf("%w: ragged projection matrix", ErrInvalidProjectionFormat)
+		}
+	}
+	if snapshot.Mean != nil && len(snapshot.Mean) != inputDim {
+		return nil, fmt.Errorf("%w: mean has dimension %d, expected %d",
+			ErrInvalidProjectionFormat, len(snapshot.Mean), inputDim)
+	}
+
+	return &Projection{method: snapshot.Method, mean: snapshot.Mean, matrix: snapshot.Matrix}, nil
+}
+
+// LoadProjectionFromFile reads a projection from a file written with SaveToFile
+func LoadProjectionFromFile(path string) (*Projection, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open projection file: %w", err)
+	}
+	defer file.Close()
+
+	return LoadProjection(file)
+}
+
+// LoadOrFitProjection returns the projection selected by config for the given vector files:
+// the saved projection at config.Path if it exists, otherwise a new one, saved to config.Path
+// when set. PCA is fitted on the first (most frequent) vectors of each file.
+// Returns nil if no projection is configured.
+func LoadOrFitProjection(config ProjectionConfig, vectorPaths []string, logger Logger) (*Projection, error) {
+	return loadOrFitProjection(config, vectorPaths, nil, logger)
+}
+
+// loadOrFitProjection is LoadOrFitProjection that fits PCA on vectors mapped like at load
+// time, with mappings keyed by cleaned vector file path
+func loadOrFitProjection(
+	config ProjectionConfig,
+	vectorPaths []string,
+	mappings map[string]*OrthogonalMapping,
+	logger Logger,
+) (*Projection, error) {
+	if !config.Enabled() {
+		return nil, nil //nolint:nilnil // no projection configured
+	}
+
+	if config.Path != "" {
+		if _, err := os.Stat(config.Path); err == nil {
+			projection, err := LoadProjectionFromFile(config.Path)
+			if err != nil {
+				return nil, err
+			}
+			if (config.Method != "" && config.Method != projection.Method()) ||
+				(config.Dimension > 0 && config.Dimension != projection.OutputDimension()) {
+				return nil, fmt.Errorf("%w: saved projection %s is %s to %d dimensions",
+					ErrInvalidConfiguration, config.Path, projection.Method(), projection.OutputDimension())
+			}
+			logger.Infof("Projection loaded, path: %s, method: %s, dimension: %d -> %d",
+				config.Path, projection.Method(), projection.InputDimension(), projection.OutputDimension())
+			return projection, nil
+		}
+	}
+
+	if len(vectorPaths) == 0 {
+		return nil, ErrNoVectorFiles
+	}
+
+	var projection *Projection
+	switch config.Method {
+	case ProjectionRandom:
+		inputDim, err := readVecDimension(vectorPaths[0])
+		if err != nil {
+			return nil, err
+		}
+		projection, err = NewRandomProjection(inputDim, config.Dimension, config.Seed)
+		if err != nil {
+			return nil, err
+		}
+	case ProjectionPCA:
+		sampleSize := config.SampleSize
+		if sampleSize <= 0 {
+			sampleSize = DefaultPCASampleSize
+		}
+		sample, err := sampleVecFiles(vectorPaths, sampleSize, mappings)
+		if err != nil {
+			return nil, err
+		}
+		projection, err = FitPCAProjection(sample, config.Dimension)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("PCA projection fitted, sample_size: %d", len(sample))
+	default:
+		return nil, fmt.Errorf("%w: projection file %s not found and no method given",
+			ErrInvalidConfiguration, config.Path)
+	}
+
+	if config.Path != "" {
+		if err := projection.SaveToFile(config.Path); err != nil {
+			return nil, err
+		}
+		logger.Infof("Projection saved, path: %s", config.Path)
+	}
+
+	logger.Infof("Projection created, method: %s, dimension: %d -> %d",
+		projection.Method(), projection.InputDimension(), projection.OutputDimension())
+	return projection, nil
+}
+
+// SampleVecFiles reads up to sampleSize vectors, taken in equal parts from the start of each
+// file; .vec files list the most frequent words first
+func SampleVecFiles(paths []string, sampleSize int) ([][]float32, error) {
+	return sampleVecFiles(paths, sampleSize, nil)
+}
+
+// sampleVecFiles is SampleVecFiles that maps the vectors of files with a mapping, keyed by
+// cleaned path
+func sampleVecFiles(paths []string, sampleSize int, mappings map[string]*OrthogonalMapping) ([][]float32, error) {
+	if len(paths) == 0 || sampleSize <= 0 {
+		return nil, ErrEmptyInput
+	}
+
+	perFile := (sampleSize + len(paths) - 1) / len(paths)
+	sample := make([][]float32, 0, sampleSize)
+	dimension := 0
+
+	for _, path := range paths {
+		vectors, fileDimension, err := readVecHead(path, min(perFile, sampleSize-len(sample)))
+		if err != nil {
+			return nil, err
+		}
+		if dimension != 0 && fileDimension != dimension {
+			return nil, fmt.Errorf("%w: file %s has dimension %d, expected %d",
+				ErrDimensionMismatch, path, fileDimension, dimension)
+		}
+		dimension = fileDimension
+		if mapping := mappings[filepath.Clean(path)]; mapping != nil {
+			if mapping.Dimension() != fileDimension {
+				return nil, fmt.Errorf("%w: mapping of %s has dimension %d, file has %d",
+					ErrDimensionMismatch, path, mapping.Dimension(), fileDimension)
+			}
+			vectors = mapping.applyAll(vectors)
+		}
+		sample = append(sample, vectors...)
+	}
+
+	return sample, nil
+}
+
+// readVecDimension returns the dimension in the header of a .vec file
+func readVecDimension(path string) (int, error) {
+	_, dimension, err := readVecHead(path, 0)
+	return dimension, err
+}
+
+// readVecHead reads the header and the first n valid vectors of a .vec file
+func readVecHead(path string, n int) ([][]float32, int, error) {
+	file, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open vector file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	if !scanner.Scan() {
+		return nil, 0, ErrInvalidVectorFormat
+	}
+	header := strings.Fields(scanner.Text())
+	if len(header) != 2 {
+		return nil, 0, fmt.Errorf("%w: first line must contain word count and dimension", ErrInvalidVectorFormat)
+	}
+	dimension, err := strconv.Atoi(header[1])
+	if err != nil || dimension <= 0 {
+		return nil, 0, fmt.Errorf("%w: invalid dimension in first line", ErrInvalidVectorFormat)
+	}
+
+	vectors := make([][]float32, 0, n)
+	for len(vectors) < n && scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) != dimension+1 {
+			continue
+		}
+
+		vector := make([]float32, dimension)
+		valid := true
+		for i, part := range parts[1:] {
+			val, err := strconv.ParseFloat(part, 32)
+			if err != nil {
+				valid = false
+				break
+			}
+			vector[i] = float32(val)
+		}
+		if valid {
+			vectors = append(vectors, vector)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return vectors, dimension, nil
+}
@@ -0,0 +1,255 @@
+package semanticmatcher
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRandomVecFile writes a .vec file of n random vectors whose variance is concentrated
+// in the first few dimensions, and returns its path
+func writeRandomVecFile(t *testing.T, dir, name string, n, dim int, seed int64) string {
+	t.Helper()
+	rng := rand.New(rand.NewSource(seed))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d %d\n", n, dim)
+	for i := range n {
+		fmt.Fprintf(&sb, "%s%d", name, i)
+		for j := range dim {
+			scale := 1 / float64(j+1)
+			fmt.Fprintf(&sb, " %g", float32(rng.NormFloat64()*scale))
+		}
+		sb.WriteString("\n")
+	}
+
+	path := filepath.Join(dir, name+".vec")
+	require.NoError(t, os.WriteFile(path, []byte(sb.String()), 0o644))
+	return path
+}
+
+func TestSymmetricEigen(t *testing.T) {
+	a := [][]float64{
+		{4, 1, 0},
+		{1, 3, 1},
+		{0, 1, 2},
+	}
+	original := [][]float64{{4, 1, 0}, {1, 3, 1}, {0, 1, 2}}
+
+	eigenvalues, eigenvectors := symmetricEigen(a)
+
+	// A v = lambda v for every eigenpair
+	for col, lambda := range eigenvalues {
+		for row := range 3 {
+			var av float64
+			for k := range 3 {
+				av += original[row][k] * eigenvectors[k][col]
+			}
+			assert.InDelta(t, lambda*eigenvectors[row][col], av, 1e-9)
+		}
+	}
+
+	// Trace is preserved
+	assert.InDelta(t, 9.0, eigenvalues[0]+eigenvalues[1]+eigenvalues[2], 1e-9)
+}
+
+func TestFitPCAProjection(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sample := make([][]float32, 500)
+	for i := range sample {
+		major := rng.NormFloat64() * 10
+		minor := rng.NormFloat64()
+		sample[i] = []float32{
+			float32(5 + (major+minor)/math.Sqrt2),
+			float32(-3 + (major-minor)/math.Sqrt2),
+			float32(rng.NormFloat64() * 0.01),
+		}
+	}
+
+	projection, err := FitPCAProjection(sample, 2)
+	require.NoError(t, err)
+	assert.Equal(t, ProjectionPCA, projection.Method())
+	assert.Equal(t, 3, projection.InputDimension())
+	assert.Equal(t, 2, projection.OutputDimension())
+
+	// First component is the major axis, with a positive largest entry
+	assert.InDelta(t, 1/math.Sqrt2, projection.matrix[0][0], 0.01)
+	assert.InDelta(t, 1/math.Sqrt2, projection.matrix[0][1], 0.01)
+	assert.InDelta(t, 0, projection.matrix[0][2], 0.01)
+	assert.InDelta(t, 1/math.Sqrt2, math.Abs(float64(projection.matrix[1][0])), 0.01)
+
+	// Projected vectors are centered on the sample mean
+	assert.InDeltaSlice(t, []float32{0, 0}, projection.Apply(projection.mean), 1e-5)
+	assert.Nil(t, projection.Apply([]float32{1, 2}))
+
+	_, err = FitPCAProjection(sample[:1], 1)
+	require.ErrorIs(t, err, ErrEmptyInput)
+	_, err = FitPCAProjection(sample, 4)
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+	_, err = FitPCAProjection([][]float32{{1, 2}, {1}}, 1)
+	require.ErrorIs(t, err, ErrDimensionMismatch)
+}
+
+func TestNewRandomProjection(t *testing.T) {
+	projection, err := NewRandomProjection(300, 100, 7)
+	require.NoError(t, err)
+	assert.Equal(t, ProjectionRandom, projection.Method())
+
+	same, err := NewRandomProjection(300, 100, 7)
+	require.NoError(t, err)
+	assert.Equal(t, projection.matrix, same.matrix)
+
+	other, err := NewRandomProjection(300, 100, 8)
+	require.NoError(t, err)
+	assert.NotEqual(t, projection.matrix, other.matrix)
+
+	// Norms are preserved on average
+	rng := rand.New(rand.NewSource(3))
+	var ratio float64
+	for range 50 {
+		vector := make([]float32, 300)
+		for i := range vector {
+			vector[i] = float32(rng.NormFloat64())
+		}
+		ratio += VectorNorm(projection.Apply(vector)) / VectorNorm(vector)
+	}
+	assert.InDelta(t, 1.0, ratio/50, 0.1)
+
+	_, err = NewRandomProjection(100, 300, 7)
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+}
+
+func TestProjection_SaveLoad(t *testing.T) {
+	projection, err := NewRandomProjection(8, 3, 1)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, projection.Save(&buf))
+	loaded, err := LoadProjection(&buf)
+	require.NoError(t, err)
+
+	vector := []float32{1, 2, 3, 4, 5, 6, 7, 8}
+	assert.Equal(t, projection.Apply(vector), loaded.Apply(vector))
+	assert.Equal(t, projection.Method(), loaded.Method())
+
+	_, err = LoadProjection(strings.NewReader("not a projection"))
+	require.ErrorIs(t, err, ErrInvalidProjectionFormat)
+
+	_, err = LoadProjectionFromFile(filepath.Join(t.TempDir(), "missing.proj"))
+	assert.Error(t, err)
+}
+
+func TestEmbeddingLoader_Projection(t *testing.T) {
+	dir := t.TempDir()
+	first := writeRandomVecFile(t, dir, "first", 50, 8, 1)
+	second := writeRandomVecFile(t, dir, "second", 50, 8, 2)
+
+	projection, err := NewRandomProjection(8, 3, 1)
+	require.NoError(t, err)
+
+	loader := NewEmbeddingLoader(DiscardLogger{})
+	loader.SetProjection(projection)
+
+	model, err := loader.LoadMultipleFiles([]string{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, 3, model.Dimension())
+	assert.Equal(t, 100, model.VocabularySize())
+
+	// Vectors are the projection of the original vectors
+	unprojected, err := NewEmbeddingLoader(DiscardLogger{}).LoadFromFile(second)
+	require.NoError(t, err)
+	original, _ := unprojected.GetVector("second7")
+	projected, _ := model.GetVector("second7")
+	assert.Equal(t, projection.Apply(original), projected)
+
+	// Files must have the projection's input dimension
+	other := writeRandomVecFile(t, dir, "other", 5, 6, 3)
+	_, err = loader.LoadFromFile(other)
+	require.ErrorIs(t, err, ErrDimensionMismatch)
+	_, err = loader.LoadMultipleFiles([]string{first, other})
+	assert.ErrorIs(t, err, ErrDimensionMismatch)
+}
+
+func TestLoadOrFitProjection(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{
+		writeRandomVecFile(t, dir, "zh", 100, 10, 1),
+		writeRandomVecFile(t, dir, "en", 100, 10, 2),
+	}
+	path := filepath.Join(dir, "pca.proj")
+
+	config := ProjectionConfig{Method: ProjectionPCA, Dimension: 4, Path: path, SampleSize: 60}
+	projection, err := LoadOrFitProjection(config, paths, DiscardLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, projection.OutputDimension())
+	require.FileExists(t, path)
+
+	// Later models reuse the saved projection, even with different vector files
+	otherPaths := []string{writeRandomVecFile(t, dir, "later", 100, 10, 3)}
+	reused, err := LoadOrFitProjection(config, otherPaths, DiscardLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, projection.matrix, reused.matrix)
+	assert.Equal(t, projection.mean, reused.mean)
+
+	// Loading only needs the path
+	loaded, err := LoadOrFitProjection(ProjectionConfig{Path: path}, nil, DiscardLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, projection.matrix, loaded.matrix)
+
+	// A saved projection must match the configured one
+	_, err = LoadOrFitProjection(ProjectionConfig{Method: ProjectionPCA, Dimension: 5, Path: path}, paths, DiscardLogger{})
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	// Random projections without a file are rebuilt from the seed
+	random, err := LoadOrFitProjection(ProjectionConfig{Method: ProjectionRandom, Dimension: 3, Seed: 9}, paths, DiscardLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, 10, random.InputDimension())
+
+	none, err := LoadOrFitProjection(ProjectionConfig{}, paths, DiscardLogger{})
+	require.NoError(t, err)
+	assert.Nil(t, none)
+}
+
+func TestSampleVecFiles(t *testing.T) {
+	dir := t.TempDir()
+	paths := []string{
+		writeRandomVecFile(t, dir, "a", 10, 4, 1),
+		writeRandomVecFile(t, dir, "b", 10, 4, 2),
+	}
+
+	sample, err := SampleVecFiles(paths, 7)
+	require.NoError(t, err)
+	assert.Len(t, sample, 7)
+
+	sample, err = SampleVecFiles(paths, 100)
+	require.NoError(t, err)
+	assert.Len(t, sample, 20)
+
+	mismatched := append(paths, writeRandomVecFile(t, dir, "c", 10, 5, 3))
+	_, err = SampleVecFiles(mismatched, 30)
+	assert.ErrorIs(t, err, ErrDimensionMismatch)
+}
+
+func TestNewSemanticMatcherFromConfig_Projection(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{writeRandomVecFile(t, dir, "words", 200, 12, 1)}
+	config.Projection = ProjectionConfig{
+		Method:    ProjectionPCA,
+		Dimension: 5,
+		Path:      filepath.Join(dir, "words.proj"),
+	}
+
+	matcher, err := NewSemanticMatcherFromConfig(config, DiscardLogger{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, matcher.(*semanticMatcher).model.Dimension())
+	assert.FileExists(t, config.Projection.Path)
+}
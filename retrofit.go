@@ -0,0 +1,206 @@
+package semanticmatcher
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	// DefaultRetrofitIterations is the number of retrofitting updates, enough to converge in practice
+	DefaultRetrofitIterations = 10
+
+	// DefaultRetrofitAlpha is the weight of a word's original vector relative to all its neighbors
+	DefaultRetrofitAlpha = 1.0
+
+	// retrofitBatchSize is the number of vectors copied into the result per lock
+	retrofitBatchSize = 10000
+)
+
+// RetrofitOptions configures Retrofit
+type RetrofitOptions struct {
+	// Iterations is the number of updates over the lexicon; zero uses DefaultRetrofitIterations
+	Iterations int
+
+	// Alpha weighs each word's original vector against its neighbors, whose weights sum to 1.
+	// Larger values keep vectors closer to the original space. Zero uses DefaultRetrofitAlpha.
+	Alpha float64
+
+	// LexiconOnly returns only the retrofitted words instead of the whole vocabulary, e.g. to
+	// save them as an overlay and stack it over the original vectors with Config.VectorLayers
+	LexiconOnly bool
+}
+
+// LoadLexicon reads a relation lexicon for Retrofit from a file where each line holds a word
+// followed by its neighbors (synonyms or related words), separated by whitespace. Empty
+// lines and lines starting with # are ignored. The format is the same as the synonym map.
+func LoadLexicon(path string) (map[string][]string, error) {
+	return loadWordListFile(path)
+}
+
+// Retrofit pulls the vectors of related words in lexicon towards each other (Faruqui et al.,
+// "Retrofitting Word Vectors to Semantic Lexicons"). Relations are symmetric; each word
+// moves towards the mean of its neighbors while staying close to its original vector.
+// Only words in the model's vocabulary take part. Lexicon words are normalized like lookups,
+// so spellings with the same normalized form are one word. The model is not modified: a new
+// model with the whole vocabulary is returned, or only the retrofitted words with
+// LexiconOnly. Frequency ranks and the model's normalizer are kept.
+func Retrofit(model VectorModel, lexicon map[string][]string, options RetrofitOptions) (VectorModel, error) {
+	source, ok := model.(layerModel)
+	if !ok || source == nil {
+		return nil, fmt.Errorf("%w: retrofitting needs a vector model of this package", ErrInvalidConfiguration)
+	}
+	if options.Iterations < 0 || options.Alpha < 0 {
+		return nil, fmt.Errorf("%w: negative retrofitting iterations or alpha", ErrInvalidConfiguration)
+	}
+	if options.Iterations == 0 {
+		options.Iterations = DefaultRetrofitIterations
+	}
+	if options.Alpha == 0 {
+		options.Alpha = DefaultRetrofitAlpha
+	}
+
+	normalizer := source.keyNormalizer()
+	graph := newRelationGraph(source, lexicon, normalizer)
+	if len(graph.words) == 0 {
+		return nil, fmt.Errorf("%w: no related lexicon words in the vocabulary", ErrEmptyInput)
+	}
+	retrofitted := graph.retrofit(options.Iterations, options.Alpha)
+
+	result := NewVectorModel(model.Dimension()).(*vectorModel) //nolint:errcheck,forcetypeassert
+	result.SetNormalizer(normalizer)
+	if options.LexiconOnly {
+		ranks := make([]int, len(graph.words))
+		for i, word := range graph.words {
+			_, ranks[i], _ = source.lookupVector(word)
+		}
+		result.addRankedVectorsBatch(graph.words, retrofitted, ranks)
+		return result, nil
+	}
+
+	updated := make(map[string][]float32, len(graph.words))
+	for i, word := range graph.words {
+		updated[word] = retrofitted[i]
+	}
+
+	result.PreallocateCapacity(model.VocabularySize())
+	words := make([]string, 0, retrofitBatchSize)
+	vectors := make([][]float32, 0, retrofitBatchSize)
+	ranks := make([]int, 0, retrofitBatchSize)
+	flush := func() {
+		result.addRankedVectorsBatch(words, vectors, ranks)
+		words, vectors, ranks = words[:0], vectors[:0], ranks[:0]
+	}
+
+	for word, vector := range model.All() {
+		if replacement, exists := updated[word]; exists {
+			vector = replacement
+		}
+		rank, _ := model.WordRank(word)
+		words = append(words, word)
+		vectors = append(vectors, vector)
+		ranks = append(ranks, rank)
+		if len(words) == retrofitBatchSize {
+			flush()
+		}
+	}
+	flush()
+	return result, nil
+}
+
+// relationGraph is the part of a lexicon whose words are in the vocabulary
+type relationGraph struct {
+	words     []string    // Words with at least one neighbor, sorted
+	original  [][]float64 // Original vector of each word
+	neighbors [][]int     // Indexes of each word's neighbors
+}
+
+// newRelationGraph builds the symmetric relation graph of lexicon words found in the model
+// Words are keyed by their normalized form, the form of the model's vocabulary keys.
+func newRelationGraph(model layerModel, lexicon map[string][]string, normalizer *Normalizer) *relationGraph {
+	edges := make(map[string]map[string]bool)
+	link := func(a, b string) {
+		if edges[a] == nil {
+			edges[a] = make(map[string]bool)
+		}
+		edges[a][b] = true
+	}
+	inVocabulary := func(word string) bool {
+		_, _, exists := model.lookupVector(word)
+		return exists
+	}
+
+	for word, related := range lexicon {
+		word = normalizer.Normalize(word)
+		if !inVocabulary(word) {
+			continue
+		}
+		for _, neighbor := range related {
+			neighbor = normalizer.Normalize(neighbor)
+			if neighbor == word || !inVocabulary(neighbor) {
+				continue
+			}
+			link(word, neighbor)
+			link(neighbor, word)
+		}
+	}
+
+	graph := &relationGraph{words: make([]string, 0, len(edges))}
+	for word := range edges {
+		graph.words = append(graph.words, word)
+	}
+	slices.Sort(graph.words)
+
+	index := make(map[string]int, len(graph.words))
+	for i, word := range graph.words {
+		index[word] = i
+	}
+
+	graph.original = make([][]float64, len(graph.words))
+	graph.neighbors = make([][]int, len(graph.words))
+	for i, word := range graph.words {
+		vector, _, _ := model.lookupVector(word)
+		graph.original[i] = make([]float64, len(vector))
+		for j, val := range vector {
+			graph.original[i][j] = float64(val)
+		}
+
+		for neighbor := range edges[word] {
+			graph.neighbors[i] = append(graph.neighbors[i], index[neighbor])
+		}
+		slices.Sort(graph.neighbors[i])
+	}
+
+	return graph
+}
+
+// retrofit runs the iterative update q_i = (alpha*q̂_i + Σ_j q_j/deg_i) / (alpha + 1), updating
+// vectors in place so later words in an iteration see the new vectors of earlier ones
+func (g *relationGraph) retrofit(iterations int, alpha float64) [][]float32 {
+	current := make([][]float64, len(g.original))
+	for i, vector := range g.original {
+		current[i] = slices.Clone(vector)
+	}
+
+	for range iterations {
+		for i, neighbors := range g.neighbors {
+			beta := 1 / float64(len(neighbors))
+			next := current[i]
+			for d := range next {
+				sum := alpha * g.original[i][d]
+				for _, j := range neighbors {
+					sum += beta * current[j][d]
+				}
+				next[d] = sum / (alpha + 1)
+			}
+		}
+	}
+
+	vectors := make([][]float32, len(current))
+	for i, vector := range current {
+		vectors[i] = make([]float32, len(vector))
+		for d, val := range vector {
+			vectors[i][d] = float32(val)
+		}
+	}
+	return vectors
+}
@@ -0,0 +1,154 @@
+package semanticmatcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const retrofitTestVectors = `5 2
+退款 1 0
+refund 0 1
+退钱 0.8 -0.6
+weather -1 0
+天气 0 -1
+`
+
+func loadRetrofitTestModel(t *testing.T) VectorModel {
+	t.Helper()
+	model, err := NewEmbeddingLoader(DiscardLogger{}).LoadFromReader(strings.NewReader(retrofitTestVectors))
+	require.NoError(t, err)
+	return model
+}
+
+func TestRetrofit(t *testing.T) {
+	model := loadRetrofitTestModel(t)
+	calculator := NewSimilarityCalculator()
+	lexicon := map[string][]string{
+		"退款": {"退钱", "refund", "missing"},
+	}
+
+	before := func(a, b string) float64 {
+		va, _ := model.GetVector(a)
+		vb, _ := model.GetVector(b)
+		return calculator.CosineSimilarity(va, vb)
+	}
+
+	retrofitted, err := Retrofit(model, lexicon, RetrofitOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, model.VocabularySize(), retrofitted.VocabularySize())
+
+	after := func(a, b string) float64 {
+		va, _ := retrofitted.GetVector(a)
+		vb, _ := retrofitted.GetVector(b)
+		return calculator.CosineSimilarity(va, vb)
+	}
+
+	// Related words move closer, including through the symmetric relation refund-退款-退钱
+	assert.Greater(t, after("退款", "refund"), before("退款", "refund"))
+	assert.Greater(t, after("refund", "退钱"), before("refund", "退钱"))
+
+	// Unrelated words keep their vectors and ranks
+	vector, _ := retrofitted.GetVector("weather")
+	assert.Equal(t, []float32{-1, 0}, vector)
+	rank, ok := retrofitted.WordRank("天气")
+	require.True(t, ok)
+	assert.Equal(t, 5, rank)
+
+	// The input model is not modified
+	vector, _ = model.GetVector("refund")
+	assert.Equal(t, []float32{0, 1}, vector)
+}
+
+func TestRetrofit_Update(t *testing.T) {
+	model := loadRetrofitTestModel(t)
+	lexicon := map[string][]string{"退款": {"refund"}}
+
+	// One iteration with alpha 1: (q̂ + q_neighbor) / 2, with words updated in sorted order,
+	// so refund moves first and 退款 moves towards the updated refund
+	retrofitted, err := Retrofit(model, lexicon, RetrofitOptions{Iterations: 1, LexiconOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, retrofitted.VocabularySize())
+
+	first, _ := retrofitted.GetVector("refund")
+	assert.InDeltaSlice(t, []float32{0.5, 0.5}, first, 1e-6)
+	second, _ := retrofitted.GetVector("退款")
+	assert.InDeltaSlice(t, []float32{0.75, 0.25}, second, 1e-6)
+
+	// A large alpha keeps vectors close to the originals
+	conservative, err := Retrofit(model, lexicon, RetrofitOptions{Alpha: 100, LexiconOnly: true})
+	require.NoError(t, err)
+	vector, _ := conservative.GetVector("退款")
+	assert.InDelta(t, 1.0, vector[0], 0.02)
+}
+
+func TestRetrofit_Normalization(t *testing.T) {
+	model := loadRetrofitTestModel(t)
+	model.SetNormalizer(NewNormalizer(NormalizationConfig{CaseFold: true}))
+
+	// Both spellings are the vocabulary word refund and form a single node
+	lexicon := map[string][]string{"Refund": {"退款"}, "REFUND": {"退钱"}}
+
+	retrofitted, err := Retrofit(model, lexicon, RetrofitOptions{})
+	require.NoError(t, err)
+	vector, ok := retrofitted.GetVector("refund")
+	require.True(t, ok)
+	assert.NotEqual(t, []float32{0, 1}, vector, "the retrofitted vector of Refund replaces refund")
+
+	overlay, err := Retrofit(model, lexicon, RetrofitOptions{LexiconOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, 3, overlay.VocabularySize())
+	overlayVector, ok := overlay.GetVector("Refund")
+	require.True(t, ok, "the overlay keeps the model's normalizer")
+	assert.Equal(t, vector, overlayVector)
+}
+
+func TestRetrofit_Errors(t *testing.T) {
+	model := loadRetrofitTestModel(t)
+
+	_, err := Retrofit(model, map[string][]string{"unknown": {"missing"}}, RetrofitOptions{})
+	require.ErrorIs(t, err, ErrEmptyInput)
+
+	_, err = Retrofit(model, map[string][]string{"退款": {"refund"}}, RetrofitOptions{Alpha: -1})
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+}
+
+func TestRetrofit_Overlay(t *testing.T) {
+	dir := t.TempDir()
+	lexiconPath := filepath.Join(dir, "lexicon.txt")
+	require.NoError(t, os.WriteFile(lexiconPath, []byte("# refund group\n退款 退钱 refund\n"), 0o644))
+
+	lexicon, err := LoadLexicon(lexiconPath)
+	require.NoError(t, err)
+
+	model := loadRetrofitTestModel(t)
+	overlay, err := Retrofit(model, lexicon, RetrofitOptions{LexiconOnly: true})
+	require.NoError(t, err)
+
+	// The overlay is saved as a .vec file and stacked over the original vectors
+	overlayPath := filepath.Join(dir, "overlay.vec")
+	file, err := os.Create(overlayPath)
+	require.NoError(t, err)
+	_, err = overlay.WriteVec(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	loadedOverlay, err := NewEmbeddingLoader(DiscardLogger{}).LoadFromFile(overlayPath)
+	require.NoError(t, err)
+	layered, err := NewLayeredVectorModel(
+		VectorLayer{Name: "retrofitted", Model: loadedOverlay},
+		VectorLayer{Name: BaseLayerName, Model: model},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 5, layered.VocabularySize())
+
+	expected, _ := overlay.GetVector("refund")
+	vector, _ := layered.GetVector("refund")
+	assert.Equal(t, expected, vector)
+	vector, _ = layered.GetVector("weather")
+	assert.Equal(t, []float32{-1, 0}, vector)
+}
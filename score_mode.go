@@ -0,0 +1,116 @@
+package semanticmatcher
+
+import (
+	"math"
+)
+
+// Score modes of Config.ScoreMode and WithScoreMode
+const (
+	ScoreModeRaw        = "raw"        // The metric's own scores, e.g. cosine in [-1, 1]
+	ScoreModeClamped    = "clamped"    // Raw scores limited to [0, upper bound]; the default without calibrator
+	ScoreModeAffine     = "affine"     // The metric's range mapped linearly onto [0, 1], (x + 1) / 2 for cosine
+	ScoreModeCalibrated = "calibrated" // Probabilities of relevance from the calibrator; the default with one
+)
+
+// WithScoreMode maps scores of ComputeSimilarity, FindTopKeywords and SimilarityMatrix with mode
+// instead of the matcher's configured score mode. ScoreModeCalibrated without calibrator
+// (see WithCalibrator) clamps, and unknown modes are ignored with a warning.
+func WithScoreMode(mode string) MatchOption {
+	return func(o *matchOptions) {
+		if !validScoreMode(mode) {
+			o.ignoredScoreMode = mode
+			return
+		}
+		o.scoreMode = mode
+	}
+}
+
+// validScoreMode reports whether mode is a score mode or empty
+func validScoreMode(mode string) bool {
+	switch mode {
+	case "", ScoreModeRaw, ScoreModeClamped, ScoreModeAffine, ScoreModeCalibrated:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateScoreMode checks the score mode; ScoreModeCalibrated needs a calibrator and
+// excludes hybrid scoring, whose fused scores are not probabilities
+func validateScoreMode(config *Config) error {
+	if !validScoreMode(config.ScoreMode) {
+		return ErrInvalidConfiguration
+	}
+	if config.ScoreMode == ScoreModeCalibrated && config.CalibratorPath == "" {
+		return ErrInvalidConfiguration
+	}
+
+	// An empty mode calibrates if there is a calibrator
+	calibrated := config.ScoreMode == ScoreModeCalibrated || config.ScoreMode == "" && config.CalibratorPath != ""
+	if calibrated && config.Hybrid.enabled() {
+		return ErrInvalidConfiguration
+	}
+	return nil
+}
+
+// scoreMapping maps raw scores of one metric to the scores of a score mode
+type scoreMapping struct {
+	mode       string
+	metric     SimilarityMetric
+	calibrator *Calibrator // Only set in ScoreModeCalibrated
+}
+
+// scoreMapping returns the mapping of the options' score mode for raw scores of metric
+// An empty mode calibrates if there is a calibrator and clamps otherwise.
+func (o matchOptions) scoreMapping(metric SimilarityMetric) scoreMapping {
+	mode := o.scoreMode
+	if mode == "" || mode == ScoreModeCalibrated {
+		if o.calibrator != nil {
+			return scoreMapping{mode: ScoreModeCalibrated, metric: metric, calibrator: o.calibrator}
+		}
+		mode = ScoreModeClamped
+	}
+	return scoreMapping{mode: mode, metric: metric}
+}
+
+// withoutCalibration returns the mapping with calibration replaced by clamping, for scores on
+// another scale than the calibrator was fitted on
+func (m scoreMapping) withoutCalibration() scoreMapping {
+	if m.mode == ScoreModeCalibrated {
+		return scoreMapping{mode: ScoreModeClamped, metric: m.metric}
+	}
+	return m
+}
+
+// preservesOrder reports whether mapped scores rank texts the same as raw ones; calibration
+// curves of different language pairs may reorder them
+func (m scoreMapping) preservesOrder() bool {
+	return m.mode != ScoreModeCalibrated
+}
+
+// score maps the raw score of two texts, language1 being the language of the first
+// Metrics with an unbounded range (dot) keep raw scores in ScoreModeAffine.
+func (m scoreMapping) score(raw float64, language1, text2 string) float64 {
+	switch m.mode {
+	case ScoreModeRaw:
+		return raw
+	case ScoreModeAffine:
+		lower, upper := m.metric.Range()
+		if math.IsInf(lower, 0) || math.IsInf(upper, 0) || upper <= lower {
+			return raw
+		}
+		return (raw - lower) / (upper - lower)
+	case ScoreModeCalibrated:
+		return m.calibrator.Calibrate(clampScore(m.metric, raw), languagePairOf(language1, text2))
+	default:
+		return clampScore(m.metric, raw)
+	}
+}
+
+// scoreMatches maps the raw score of each keyword match
+func (m scoreMapping) scoreMatches(paragraph string, matches []KeywordMatch) {
+	paragraphLanguage := textLanguage(paragraph)
+	for i := range matches {
+		matches[i].Score = m.score(matches[i].Score, paragraphLanguage, matches[i].Keyword)
+	}
+}
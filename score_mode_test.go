@@ -0,0 +1,145 @@
+package semanticmatcher
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newScoreModeTestMatcher returns a matcher where "north" and "south" have opposite vectors
+func newScoreModeTestMatcher() SemanticMatcher {
+	model := NewVectorModel(2).(*vectorModel) //nolint:errcheck,forcetypeassert
+	model.AddVector("north", []float32{1, 0})
+	model.AddVector("south", []float32{-1, 0})
+	model.AddVector("east", []float32{0.6, 0.8})
+	return NewSemanticMatcher(NewTextProcessor(), model, NewSimilarityCalculator())
+}
+
+func TestScoreMode_ConsistentAcrossAPIs(t *testing.T) {
+	matcher := newScoreModeTestMatcher()
+
+	for _, tc := range []struct {
+		mode     string
+		opposite float64
+		left     float64
+	}{
+		{"", 0, 0.6},
+		{ScoreModeClamped, 0, 0.6},
+		{ScoreModeRaw, -1, 0.6},
+		{ScoreModeAffine, 0, 0.8},
+		{ScoreModeCalibrated, 0, 0.6}, // Clamps without calibrator
+	} {
+		opts := []MatchOption{WithScoreMode(tc.mode)}
+
+		assert.InDelta(t, tc.opposite, matcher.ComputeSimilarityWithOptions("north", "south", opts...), 1e-6, tc.mode)
+		assert.InDelta(t, tc.left, matcher.ComputeSimilarityWithOptions("north", "east", opts...), 1e-6, tc.mode)
+
+		matches := matcher.FindTopKeywordsWithOptions("north", []string{"south", "east"}, 0, opts...)
+		require.Len(t, matches, 2, tc.mode)
+		assert.Equal(t, "east", matches[0].Keyword)
+		assert.InDelta(t, tc.left, matches[0].Score, 1e-6, tc.mode)
+		assert.InDelta(t, tc.opposite, matches[1].Score, 1e-6, tc.mode)
+
+		matrix := matcher.SimilarityMatrixWithOptions([]string{"north"}, []string{"south", "east", "unknown"}, opts...)
+		assert.InDelta(t, tc.opposite, matrix.Scores[0][0], 1e-6, tc.mode)
+		assert.InDelta(t, tc.left, matrix.Scores[0][1], 1e-6, tc.mode)
+
+		// Texts without vectors keep 0 in every mode
+		assert.Zero(t, matrix.Scores[0][2], tc.mode)
+	}
+}
+
+func TestScoreMode_Calibrated(t *testing.T) {
+	matcher := newScoreModeTestMatcher()
+	calibrator := &Calibrator{
+		Method: CalibrationIsotonic,
+		Global: &CalibrationCurve{Scores: []float64{0, 1}, Probabilities: []float64{0.1, 0.9}},
+	}
+
+	// WithCalibrator selects the calibrated mode; clamped 0.6 maps to 0.58
+	assert.InDelta(t, 0.58, matcher.ComputeSimilarityWithOptions("north", "east", WithCalibrator(calibrator)), 1e-6)
+	matches := matcher.FindTopKeywordsWithOptions("north", []string{"south", "east"}, 0, WithCalibrator(calibrator))
+	require.Len(t, matches, 2)
+	assert.InDelta(t, 0.58, matches[0].Score, 1e-6)
+	assert.InDelta(t, 0.1, matches[1].Score, 1e-6)
+	matrix := matcher.SimilarityMatrixWithOptions([]string{"north"}, []string{"east"}, WithCalibrator(calibrator))
+	assert.InDelta(t, 0.58, matrix.Scores[0][0], 1e-6)
+
+	// A later score mode overrides the calibration
+	assert.InDelta(t, 0.8, matcher.ComputeSimilarityWithOptions("north", "east",
+		WithCalibrator(calibrator), WithScoreMode(ScoreModeAffine)), 1e-6)
+
+	// Token alignment scores are clamped instead of calibrated
+	aligned := matcher.FindTopKeywordsWithOptions("north", []string{"east"}, 0,
+		WithCalibrator(calibrator), WithTokenAlignment(AlignmentScoring{}))
+	require.Len(t, aligned, 1)
+	assert.InDelta(t, 0.6, aligned[0].Score, 1e-6)
+}
+
+func TestScoreMode_Hybrid(t *testing.T) {
+	matcher := newScoreModeTestMatcher()
+	keywords := []string{"north", "south", "east"}
+	lexical := 1 / (1 + DefaultBM25K1) // north is mentioned once in the paragraph
+
+	for _, tc := range []struct {
+		mode   string
+		fusion string
+		scores map[string]float64
+	}{
+		{ScoreModeRaw, FusionWeightedSum, map[string]float64{"north": 0.5 + 0.5*lexical, "east": 0.3, "south": -0.5}},
+		{ScoreModeClamped, FusionWeightedSum, map[string]float64{"north": 0.5 + 0.5*lexical, "east": 0.3, "south": 0}},
+		{ScoreModeAffine, FusionWeightedSum, map[string]float64{"north": 0.5 + 0.5*lexical, "east": 0.4, "south": 0}},
+		{ScoreModeCalibrated, FusionWeightedSum, map[string]float64{"north": 0.5 + 0.5*lexical, "east": 0.3, "south": 0}},
+		{ScoreModeRaw, FusionRRF, map[string]float64{"north": 1.0 / 61, "east": 1.0 / 62, "south": 0.5/63 + 0.5/62}},
+		{ScoreModeClamped, FusionRRF, map[string]float64{"north": 1.0 / 61, "east": 1.0 / 62, "south": 0.5/63 + 0.5/62}},
+		{ScoreModeAffine, FusionRRF, map[string]float64{"north": 1, "east": 61.0 / 62, "south": 61 * (0.5/63 + 0.5/62)}},
+		{ScoreModeCalibrated, FusionRRF, map[string]float64{"north": 1.0 / 61, "east": 1.0 / 62, "south": 0.5/63 + 0.5/62}},
+	} {
+		// A calibrator has no effect: fused scores are not probabilities, so they are clamped
+		calibrator := &Calibrator{
+			Method: CalibrationIsotonic,
+			Global: &CalibrationCurve{Scores: []float64{0, 1}, Probabilities: []float64{0.9, 0.9}},
+		}
+		matches := matcher.FindTopKeywordsWithOptions("north", keywords, 0, WithCalibrator(calibrator),
+			WithScoreMode(tc.mode), WithHybridScoring(HybridConfig{Fusion: tc.fusion}))
+		require.Len(t, matches, 3)
+		assert.Equal(t, "north", matches[0].Keyword, "%s %s", tc.fusion, tc.mode)
+		for _, match := range matches {
+			assert.InDelta(t, tc.scores[match.Keyword], match.Score, 1e-6, "%s %s %s", tc.fusion, tc.mode, match.Keyword)
+		}
+	}
+}
+
+func TestScoreMapping_Affine(t *testing.T) {
+	affine := matchOptions{scoreMode: ScoreModeAffine}
+	assert.InDelta(t, 0.75, affine.scoreMapping(CosineMetric{}).score(0.5, "", ""), 1e-9)
+	assert.InDelta(t, 0.5, affine.scoreMapping(EuclideanMetric{}).score(0.5, "", ""), 1e-9)
+
+	// Unbounded metrics keep raw scores
+	assert.InDelta(t, -3.0, affine.scoreMapping(DotProductMetric{}).score(-3, "", ""), 1e-9)
+	assert.False(t, math.IsNaN(affine.scoreMapping(DotProductMetric{}).score(2, "", "")))
+}
+
+func TestWithScoreMode_IgnoresUnknown(t *testing.T) {
+	options := matchOptions{scoreMode: ScoreModeRaw}
+	WithScoreMode("sigmoid")(&options)
+	assert.Equal(t, ScoreModeRaw, options.scoreMode)
+	assert.Equal(t, "sigmoid", options.ignoredScoreMode)
+}
+
+func TestWithScoreMode_WarnsUnknown(t *testing.T) {
+	model := newHybridTestMatcher().(*semanticMatcher).model //nolint:forcetypeassert
+	logger := &mockLogger{messages: make([]string, 0)}
+	matcher := NewSemanticMatcherWithLogger(NewTextProcessor(), model, NewSimilarityCalculator(), logger, 0.5)
+
+	// The configured mode applies and the misuse is logged
+	expected := matcher.ComputeSimilarity("docker", "cluster")
+	assert.InDelta(t, expected, matcher.ComputeSimilarityWithOptions("docker", "cluster", WithScoreMode("Affine")), 1e-9)
+	assert.True(t, slices.ContainsFunc(logger.messages, func(message string) bool {
+		return strings.Contains(message, "Ignoring unknown score mode, score_mode: Affine")
+	}))
+}
@@ -1,8 +1,12 @@
 package semanticmatcher
 
 import (
+	"context"
+	"fmt"
+	"os"
 	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -14,9 +18,24 @@ type semanticMatcher struct {
 	stats        *MatcherStats
 	logger       Logger
 	oovThreshold float64 // Threshold for logging OOV warnings (e.g., 0.5 = 50%)
+	pooling      Pooling // Default strategy for turning token vectors into a text vector
 	mtx          sync.RWMutex
+
+	weighting       TokenWeighting   // Default token weighting; nil weighs all tokens the same
+	commonComponent *CommonComponent // Fitted by FitCommonComponent, guarded by mtx
+
+	loader     EmbeddingLoader     // Loader of the vector files, reused by ReloadVectorLayer
+	layerPaths map[string][]string // Vector files of each layer of a LayeredVectorModel, by layer name
+
+	pool       workerPool   // Parallelism of keyword and text processing
+	hybrid     HybridConfig // Default fusion of lexical and semantic keyword scores
+	calibrator *Calibrator  // Default mapping of raw scores to probabilities; nil disables calibration
+	scoreMode  string       // Default mapping of raw scores, see Config.ScoreMode
 }
 
+// keywordBlockSize is the number of keywords or texts per parallel block
+const keywordBlockSize = 64
+
 // NewSemanticMatcher creates a new SemanticMatcher instance
 func NewSemanticMatcher(
 	processor TextProcessor,
@@ -29,6 +48,7 @@ func NewSemanticMatcher(
 		calculator:   calculator,
 		logger:       DiscardLogger{},
 		oovThreshold: 0.5, // Default: warn if 50% or more words are OOV
+		pooling:      MeanPooling{},
 		stats: &MatcherStats{
 			LastUpdated: time.Now(),
 		},
@@ -49,6 +69,7 @@ func NewSemanticMatcherWithLogger(
 		calculator:   calculator,
 		logger:       logger,
 		oovThreshold: oovThreshold,
+		pooling:      MeanPooling{},
 		stats: &MatcherStats{
 			LastUpdated: time.Now(),
 		},
@@ -134,8 +155,35 @@ func NewSemanticMatcherFromConfig(config *Config, logger Logger) (SemanticMatche
 		processor = NewTextProcessor()
 	}
 
+	// Normalize vocabulary keys and query text the same way
+	normalizer := NewNormalizer(config.Normalization)
+	if normalizer != nil {
+		logger.Infof("Unicode normalization enabled, nfkc: %v, full_width: %v, strip_accents: %v, case_fold: %v",
+			config.Normalization.NFKC, config.Normalization.FullWidth,
+			config.Normalization.StripAccents, config.Normalization.CaseFold)
+		processor.SetNormalizer(normalizer)
+	}
+
 	// Initialize embedding loader
 	loader := NewEmbeddingLoader(logger)
+	loader.SetNormalizer(normalizer)
+
+	// Rotate unaligned vector files into the space of the others before any projection
+	mappings, err := loadVectorMappings(config.VectorMappings, logger)
+	if err != nil {
+		return nil, err
+	}
+	for path, mapping := range mappings {
+		loader.SetVectorMapping(path, mapping)
+	}
+
+	// Reduce all vector files with the same projection
+	projection, err := loadOrFitProjection(config.Projection, config.VectorFilePaths, mappings, logger)
+	if err != nil {
+		logger.Errorf("Failed to set up projection, error: %v", err)
+		return nil, err
+	}
+	loader.SetProjection(projection)
 
 	// Load vector model from file(s)
 	logger.Infof("Loading vector model, file_count: %d, paths: %v",
@@ -150,6 +198,18 @@ func NewSemanticMatcherFromConfig(config *Config, logger Logger) (SemanticMatche
 		return nil, err
 	}
 
+	// Stack domain layers over the base model
+	layerPaths := map[string][]string{BaseLayerName: config.VectorFilePaths}
+	if len(config.VectorLayers) > 0 {
+		model, err = loadVectorLayers(loader, model, config, logger)
+		if err != nil {
+			return nil, err
+		}
+		for _, layer := range config.VectorLayers {
+			layerPaths[layer.Name] = layer.VectorFilePaths
+		}
+	}
+
 	// Log detailed information about loaded model
 	logger.Infof("Vector model loaded successfully, file_count: %d, "+
 		"vocabulary_size: %d, dimension: %d, memory_mb: %.2f",
@@ -173,8 +233,60 @@ func NewSemanticMatcherFromConfig(config *Config, logger Logger) (SemanticMatche
 			float64(memUsage)/(1024*1024), float64(config.MemoryLimit)/(1024*1024))
 	}
 
+	// Configure per-word OOV tracking
+	if config.OOVTrackerCapacity > 0 {
+		if tracker, ok := model.(interface{ SetOOVTrackerCapacity(capacity int) }); ok {
+			tracker.SetOOVTrackerCapacity(config.OOVTrackerCapacity)
+		}
+	}
+
+	// Cache fallback results of repeated OOV words
+	if config.FallbackCacheSize > 0 {
+		if cached, ok := model.(interface{ SetFallbackCacheSize(size int) }); ok {
+			cached.SetFallbackCacheSize(config.FallbackCacheSize)
+			logger.Infof("Fallback cache enabled, size: %d", config.FallbackCacheSize)
+		}
+	}
+
+	// Configure Traditional/Simplified Chinese support; explicit fallback chains below take precedence
+	configureChineseConversion(processor, model, config, logger)
+
+	// Configure per-language fallback chains
+	if err := configureFallbackChains(model, config, logger); err != nil {
+		return nil, err
+	}
+
 	// Initialize similarity calculator
-	calculator := NewSimilarityCalculator()
+	metric, err := NewSimilarityMetric(config.SimilarityMetric)
+	if err != nil {
+		return nil, err
+	}
+	calculator := NewSimilarityCalculatorWithMetric(metric)
+	logger.Infof("Similarity metric configured, metric: %s", metric.Name())
+
+	// Spread keywords and candidate vectors of large requests over a bounded number of goroutines
+	pool := newWorkerPool(config.Workers, config.ParallelThreshold)
+	if parallel, ok := calculator.(interface{ SetParallelism(workers, threshold int) }); ok {
+		parallel.SetParallelism(config.Workers, config.ParallelThreshold)
+	}
+	logger.Infof("Parallelism configured, workers: %d, parallel_threshold: %d", pool.maxWorkers(), pool.minWork())
+
+	pooling, err := NewPooling(config.Pooling)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Pooling strategy configured, pooling: %s", pooling.Name())
+
+	weighting, err := newTokenWeightingFromConfig(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Token weighting configured, weighting: %s", tokenWeightingName(weighting))
+
+	calibrator, err := loadCalibratorFromConfig(config, metric, logger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Determine OOV threshold (use default if not specified)
 	oovThreshold := 0.5
@@ -190,11 +302,25 @@ func NewSemanticMatcherFromConfig(config *Config, logger Logger) (SemanticMatche
 		calculator:   calculator,
 		logger:       logger,
 		oovThreshold: oovThreshold,
+		pooling:      pooling,
+		weighting:    weighting,
+		loader:       loader,
+		layerPaths:   layerPaths,
+		pool:         pool,
+		hybrid:       config.Hybrid,
+		calibrator:   calibrator,
+		scoreMode:    config.ScoreMode,
 		stats: &MatcherStats{
 			LastUpdated: time.Now(),
 		},
 	}
 
+	if config.CommonComponentSamplePath != "" {
+		if err := fitCommonComponentFromFile(matcher, config.CommonComponentSamplePath, logger); err != nil {
+			return nil, err
+		}
+	}
+
 	logger.Infof(
 		"SemanticMatcher initialized successfully, file_count: %d, vector_dimension: %d, vocabulary_size: %d, "+
 			"memory_usage_mb: %.2f, supported_languages: %v",
@@ -231,9 +357,54 @@ func validateConfig(config *Config) error {
 		return ErrInvalidConfiguration
 	}
 
+	if config.OOVTrackerCapacity < 0 || config.FallbackCacheSize < 0 {
+		return ErrInvalidConfiguration
+	}
+
+	if config.Workers < 0 || config.ParallelThreshold < 0 {
+		return ErrInvalidConfiguration
+	}
+
+	if err := validateHybrid(config.Hybrid); err != nil {
+		return err
+	}
+
+	if err := validateScoreMode(config); err != nil {
+		return err
+	}
+
+	if err := validateFallbackChains(config); err != nil {
+		return err
+	}
+
+	if _, err := NewPooling(config.Pooling); err != nil {
+		return ErrInvalidConfiguration
+	}
+
+	if _, err := NewSimilarityMetric(config.SimilarityMetric); err != nil {
+		return ErrInvalidConfiguration
+	}
+
+	if err := validateTokenWeighting(config); err != nil {
+		return err
+	}
+
+	if err := validateProjection(config.Projection); err != nil {
+		return err
+	}
+
+	if err := validateVectorLayers(config.VectorLayers); err != nil {
+		return err
+	}
+
+	if err := validateVectorMappings(config); err != nil {
+		return err
+	}
+
 	// Validate supported languages
 	for _, lang := range config.SupportedLanguages {
-		if lang != "zh" && lang != "en" {
+		if lang != LanguageChinese && lang != LanguageEnglish &&
+			lang != LanguageSimplifiedChinese && lang != LanguageTraditionalChinese {
 			return ErrUnsupportedLanguage
 		}
 	}
@@ -241,24 +412,175 @@ func validateConfig(config *Config) error {
 	return nil
 }
 
+// loadVectorLayers loads Config.VectorLayers with the loader of the base model and stacks
+// them over it
+func loadVectorLayers(loader EmbeddingLoader, base VectorModel, config *Config, logger Logger) (VectorModel, error) {
+	layers := make([]VectorLayer, 0, len(config.VectorLayers)+1)
+	for _, layer := range config.VectorLayers {
+		model, err := loader.LoadMultipleFiles(layer.VectorFilePaths)
+		if err != nil {
+			logger.Errorf("Failed to load vector layer, layer: %s, error: %v, paths: %v",
+				layer.Name, err, layer.VectorFilePaths)
+			return nil, err
+		}
+		logger.Infof("Vector layer loaded, layer: %s, vocabulary_size: %d, paths: %v",
+			layer.Name, model.VocabularySize(), layer.VectorFilePaths)
+		layers = append(layers, VectorLayer{Name: layer.Name, Model: model})
+	}
+	layers = append(layers, VectorLayer{Name: BaseLayerName, Model: base})
+
+	return NewLayeredVectorModel(layers...)
+}
+
+// newTokenWeightingFromConfig returns the token weighting selected by Config.TokenWeighting,
+// loading the IDF model for "tfidf"
+func newTokenWeightingFromConfig(config *Config, logger Logger) (TokenWeighting, error) {
+	if config.TokenWeighting != WeightingTFIDF {
+		return NewTokenWeighting(config.TokenWeighting, config.SIFParameter)
+	}
+
+	idf, err := LoadIDFModel(config.IDFModelPath)
+	if err != nil {
+		logger.Errorf("Failed to load IDF model, path: %s, error: %v", config.IDFModelPath, err)
+		return nil, err
+	}
+	logger.Infof("IDF model loaded, path: %s, documents: %d, terms: %d",
+		config.IDFModelPath, idf.DocumentCount(), idf.Len())
+
+	return idf.WithDefaultIDF(config.IDFDefault), nil
+}
+
+// loadCalibratorFromConfig loads the calibrator of Config.CalibratorPath, if set, and checks
+// that it was fitted with the configured metric
+func loadCalibratorFromConfig(config *Config, metric SimilarityMetric, logger Logger) (*Calibrator, error) {
+	if config.CalibratorPath == "" {
+		return nil, nil //nolint:nilnil // no calibration configured
+	}
+
+	calibrator, err := LoadCalibrator(config.CalibratorPath)
+	if err != nil {
+		logger.Errorf("Failed to load calibrator, path: %s, error: %v", config.CalibratorPath, err)
+		return nil, err
+	}
+	if calibrator.Metric != "" && calibrator.Metric != metric.Name() {
+		logger.Errorf("Calibrator metric mismatch, path: %s, calibrator_metric: %s, metric: %s",
+			config.CalibratorPath, calibrator.Metric, metric.Name())
+		return nil, fmt.Errorf("%w: calibrator fitted with metric %q, configured metric is %q",
+			ErrInvalidConfiguration, calibrator.Metric, metric.Name())
+	}
+	logger.Infof("Calibrator loaded, path: %s, method: %s, pairs: %d, language_pairs: %d",
+		config.CalibratorPath, calibrator.Method, calibrator.Global.Pairs, len(calibrator.LanguagePairs))
+
+	return calibrator, nil
+}
+
+// similarityMetricName returns the name of the calculator's metric, recorded by FitCalibrator
+func (sm *semanticMatcher) similarityMetricName() string {
+	return sm.calculator.Metric().Name()
+}
+
+// configureFallbackChains applies Config.FallbackChains to the model
+func configureFallbackChains(model VectorModel, config *Config, logger Logger) error {
+	if len(config.FallbackChains) == 0 {
+		return nil
+	}
+
+	var synonyms *SynonymFallback
+	if config.SynonymMapPath != "" {
+		var err error
+		synonyms, err = LoadSynonymFallback(config.SynonymMapPath)
+		if err != nil {
+			logger.Errorf("Failed to load synonym map, path: %s, error: %v", config.SynonymMapPath, err)
+			return err
+		}
+	}
+
+	for language, names := range config.FallbackChains {
+		chain, err := NewFallbackChain(names, synonyms)
+		if err != nil {
+			return err
+		}
+
+		target := language
+		if language == DefaultFallbackChainKey {
+			target = ""
+		}
+		model.SetFallbackChain(target, chain...)
+		logger.Infof("Fallback chain configured, language: %s, strategies: %v", language, names)
+	}
+
+	return nil
+}
+
+// configureChineseConversion enables Traditional/Simplified Chinese support when
+// Config.SupportedLanguages lists "zh-Hant" or "zh-Hans": preprocessing converts input to
+// the vocabulary's script, and Chinese OOV words also try their converted forms
+func configureChineseConversion(processor TextProcessor, model VectorModel, config *Config, logger Logger) {
+	if !slices.Contains(config.SupportedLanguages, LanguageSimplifiedChinese) &&
+		!slices.Contains(config.SupportedLanguages, LanguageTraditionalChinese) {
+		return
+	}
+
+	if direction, ok := chineseConversionForLanguages(config.SupportedLanguages); ok {
+		processor.SetChineseConverter(NewChineseConverter(direction))
+		logger.Infof("Chinese script conversion enabled for preprocessing, target: %s", direction)
+	}
+
+	model.SetFallbackChain(LanguageChinese, ChineseScriptFallback{}, CharacterFallback{CJKOnly: true})
+	logger.Infof("Chinese script fallback enabled, strategies: %v",
+		[]string{FallbackChineseScript, FallbackCharAverageCJK})
+}
+
 // FindTopKeywords finds most similar keywords to paragraph
 // Returns at most k results sorted by similarity score in descending order
 // If k <= 0, returns all results
+// Scores are mapped by the score mode (see Config.ScoreMode) like those of ComputeSimilarity.
 func (sm *semanticMatcher) FindTopKeywords(
 	paragraph string,
 	keywords []string,
 	k int,
 ) []KeywordMatch {
-	sm.logger.Debugf("FindTopKeywords called, paragraph_length: %d, keywords_count: %d, k: %d",
-		len(paragraph), len(keywords), k)
+	return sm.FindTopKeywordsWithOptions(paragraph, keywords, k)
+}
+
+// FindTopKeywordsWithOptions is FindTopKeywords with per-call overrides
+func (sm *semanticMatcher) FindTopKeywordsWithOptions(
+	paragraph string,
+	keywords []string,
+	k int,
+	opts ...MatchOption,
+) []KeywordMatch {
+	matches, _ := sm.FindTopKeywordsContext(context.Background(), paragraph, keywords, k, opts...) //nolint:errcheck
+	return matches
+}
+
+// FindTopKeywordsContext is FindTopKeywordsWithOptions that stops between keyword batches once
+// ctx is done, returning the best of the keywords scored so far together with ctx.Err()
+//
+//nolint:funlen
+func (sm *semanticMatcher) FindTopKeywordsContext(
+	ctx context.Context,
+	paragraph string,
+	keywords []string,
+	k int,
+	opts ...MatchOption,
+) ([]KeywordMatch, error) {
+	options := sm.resolveOptions(opts)
+
+	sm.logger.Debugf("FindTopKeywords called, paragraph_length: %d, keywords_count: %d, k: %d, pooling: %s",
+		len(paragraph), len(keywords), k, options.pooling.Name())
 
-	// Handle empty inputs
 	startTime := time.Now()
+	if err := sm.checkCanceled(ctx, "FindTopKeywords", startTime); err != nil {
+		return []KeywordMatch{}, err
+	}
+
+	// Handle empty inputs
 	if paragraph == "" || len(keywords) == 0 {
 		sm.updateStats(time.Since(startTime), 0, 0)
 		sm.logger.Warnf("Empty input provided, paragraph_empty: %v, keywords_empty: %v",
 			paragraph == "", len(keywords) == 0)
-		return []KeywordMatch{}
+		return []KeywordMatch{}, nil
 	}
 
 	// Preprocess paragraph
@@ -270,26 +592,35 @@ func (sm *semanticMatcher) FindTopKeywords(
 	if len(paragraphTokens) == 0 {
 		sm.updateStats(time.Since(startTime), 0, 0)
 		sm.logger.Warnf("No valid tokens after preprocessing paragraph")
-		return []KeywordMatch{}
+		return []KeywordMatch{}, nil
+	}
+
+	// Token alignment replaces the metric for this call
+	if options.alignment != nil {
+		return sm.findTopKeywordsByAlignment(ctx, paragraphTokens, keywords, k, options, startTime)
+	}
+
+	// Token metrics compare token sets and need no vectors
+	if metric, ok := sm.calculator.Metric().(TokenSimilarityMetric); ok {
+		return sm.findTopKeywordsByTokens(ctx, paragraph, paragraphTokens, keywords, k, metric,
+			options.scoreMapping(metric), startTime)
+	}
+
+	// Word vector metrics align word vectors instead of pooling them
+	if metric, ok := sm.calculator.Metric().(WordVectorSimilarityMetric); ok {
+		return sm.findTopKeywordsByWordVectors(ctx, paragraph, paragraphTokens, keywords, k, metric,
+			options.scoreMapping(metric), startTime)
 	}
 
-	// Get paragraph vector using mean pooling
+	// Get paragraph vector using the selected pooling
 	vectorizeStart := time.Now()
-	paragraphVector, ok := sm.model.GetAverageVector(paragraphTokens)
+	paragraphVector, paragraphOOVCount, ok := sm.textVector(paragraphTokens, options)
 	vectorizeDuration := time.Since(vectorizeStart)
 	if !ok {
 		// All words are OOV
 		sm.updateStats(time.Since(startTime), len(paragraphTokens), len(paragraphTokens))
 		sm.logger.Warnf("All paragraph words are OOV, token_count: %d", len(paragraphTokens))
-		return make([]KeywordMatch, 0)
-	}
-
-	// Count OOV words in paragraph
-	paragraphOOVCount := 0
-	for _, token := range paragraphTokens {
-		if _, exists := sm.model.GetVector(token); !exists {
-			paragraphOOVCount++
-		}
+		return make([]KeywordMatch, 0), nil
 	}
 
 	paragraphOOVRate := float64(paragraphOOVCount) / float64(len(paragraphTokens))
@@ -310,61 +641,63 @@ func (sm *semanticMatcher) FindTopKeywords(
 		)
 	}
 
-	// Process each keyword and compute similarity
+	// Preprocess and vectorize keywords, in parallel blocks for many keywords; similarities are
+	// computed in one batch afterwards, for the keywords analyzed before a cancellation
+	similarityStart := time.Now()
+	analyzed, processed, canceled := sm.analyzeTextsContext(ctx, keywords, options, sm.calculator.Metric())
+
 	matches := make([]KeywordMatch, 0, len(keywords))
+	keywordTokens := make([][]string, 0, len(keywords))
 	totalKeywordTokens := 0
 	totalKeywordOOV := 0
 
-	similarityStart := time.Now()
-	for _, keyword := range keywords {
-		// Preprocess keyword
-		keywordTokens := sm.processor.Preprocess(keyword)
-		if len(keywordTokens) == 0 {
-			// Empty keyword after preprocessing
-			matches = append(matches, KeywordMatch{
-				Keyword:   keyword,
-				Score:     0.0,
-				WordCount: 0,
-				OOVCount:  0,
-			})
+	keywordVectors := make([][]float32, 0, len(keywords))
+	vectorMatchIndexes := make([]int, 0, len(keywords))
+
+	for i, keyword := range keywords {
+		if !processed[i] {
 			continue
 		}
-
-		totalKeywordTokens += len(keywordTokens)
-
-		// Get keyword vector using mean pooling
-		var score float64
-		var oovCount int
-		keywordVector, ok := sm.model.GetAverageVector(keywordTokens)
-		if !ok {
-			// All words are OOV
-			score = 0.0
-			oovCount = len(keywordTokens)
-			totalKeywordOOV += oovCount
-		} else {
-			// Compute cosine similarity
-			score = sm.calculator.CosineSimilarity(paragraphVector, keywordVector)
-
-			// Count OOV words
-			for _, token := range keywordTokens {
-				if _, exists := sm.model.GetVector(token); !exists {
-					oovCount++
-				}
-			}
-			totalKeywordOOV += oovCount
+		text := analyzed[i]
+		totalKeywordTokens += text.info.WordCount
+		totalKeywordOOV += text.info.OOVCount
+		if text.vector != nil {
+			keywordVectors = append(keywordVectors, text.vector)
+			vectorMatchIndexes = append(vectorMatchIndexes, len(matches))
 		}
 
 		matches = append(matches, KeywordMatch{
 			Keyword:   keyword,
-			Score:     score,
-			WordCount: len(keywordTokens),
-			OOVCount:  oovCount,
+			Score:     0.0,
+			WordCount: text.info.WordCount,
+			OOVCount:  text.info.OOVCount,
 		})
+		keywordTokens = append(keywordTokens, text.tokens)
+	}
+
+	// Compute similarities with the calculator's metric
+	scores := sm.calculator.BatchSimilarity(paragraphVector, keywordVectors)
+
+	// Map scores to the score mode before fusion; keywords without vectors keep 0
+	// Fused scores are not probabilities, so hybrid scoring clamps instead of calibrating.
+	mapping := options.scoreMapping(sm.calculator.Metric())
+	if options.hybrid.enabled() {
+		mapping = mapping.withoutCalibration()
+	}
+	paragraphLanguage := textLanguage(paragraph)
+	for i, score := range scores {
+		idx := vectorMatchIndexes[i]
+		matches[idx].Score = mapping.score(score, paragraphLanguage, matches[idx].Keyword)
+	}
+
+	// Fuse in exact mentions of keyword tokens, also for keywords without vectors
+	if options.hybrid.enabled() {
+		fuseScores(matches, keywordTokens, paragraphTokens, options.hybrid, options.weighting, mapping)
 	}
 	similarityDuration := time.Since(similarityStart)
 
-	// Sort by similarity score in descending order
-	sort.Slice(matches, func(i, j int) bool {
+	// Sort by similarity score in descending order, keeping tied keywords in input order
+	sort.SliceStable(matches, func(i, j int) bool {
 		return matches[i].Score > matches[j].Score
 	})
 
@@ -377,6 +710,14 @@ func (sm *semanticMatcher) FindTopKeywords(
 	totalTokens := len(paragraphTokens) + totalKeywordTokens
 	totalOOV := paragraphOOVCount + totalKeywordOOV
 
+	if canceled != nil {
+		sm.updateCanceledStats(totalDuration)
+		sm.logger.Warnf("FindTopKeywords canceled, total_duration_ms: %d, keywords_processed: %d, "+
+			"keywords_total: %d, results_returned: %d, error: %v",
+			totalDuration.Milliseconds(), len(keywordTokens), len(keywords), len(matches), canceled)
+		return matches, canceled
+	}
+
 	sm.updateStats(totalDuration, totalTokens, totalOOV)
 
 	overallOOVRate := 0.0
@@ -428,23 +769,193 @@ func (sm *semanticMatcher) FindTopKeywords(
 			overallOOVRate, totalOOV, totalTokens)
 	}
 
-	return matches
+	return matches, nil
+}
+
+// findTopKeywordsByTokens ranks keywords by a token metric on their token sets
+func (sm *semanticMatcher) findTopKeywordsByTokens(
+	ctx context.Context,
+	paragraph string,
+	paragraphTokens []string,
+	keywords []string,
+	k int,
+	metric TokenSimilarityMetric,
+	mapping scoreMapping,
+	startTime time.Time,
+) ([]KeywordMatch, error) {
+	analyzed, processed, canceled := sm.analyzeTextsContext(ctx, keywords, matchOptions{}, metric)
+
+	matches := make([]KeywordMatch, 0, len(keywords))
+	totalTokens := len(paragraphTokens)
+	for i, keyword := range keywords {
+		if !processed[i] {
+			continue
+		}
+		totalTokens += analyzed[i].info.WordCount
+		matches = append(matches, KeywordMatch{
+			Keyword:   keyword,
+			Score:     metric.TokenSimilarity(paragraphTokens, analyzed[i].tokens),
+			WordCount: analyzed[i].info.WordCount,
+		})
+	}
+	mapping.scoreMatches(paragraph, matches)
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if k > 0 && k < len(matches) {
+		matches = matches[:k]
+	}
+
+	totalDuration := time.Since(startTime)
+	if canceled != nil {
+		sm.updateCanceledStats(totalDuration)
+		sm.logger.Warnf("FindTopKeywords canceled, metric: %s, total_duration_ms: %d, keywords_total: %d, "+
+			"results_returned: %d, error: %v", metric.Name(), totalDuration.Milliseconds(), len(keywords), len(matches),
+			canceled)
+		return matches, canceled
+	}
+
+	sm.updateStats(totalDuration, totalTokens, 0)
+	sm.logger.Infof("FindTopKeywords completed, metric: %s, total_duration_ms: %d, keywords_processed: %d, "+
+		"results_returned: %d, total_tokens: %d",
+		metric.Name(), totalDuration.Milliseconds(), len(keywords), len(matches), totalTokens)
+
+	return matches, nil
+}
+
+// findTopKeywordsByWordVectors ranks keywords by a word vector metric such as WMD
+// With WMD and k > 0, keywords that cannot be among the k best are pruned by lower bounds,
+// unless the score mode may reorder them (calibration).
+// Keywords are scored after all are analyzed, so a cancellation during analysis scores none.
+func (sm *semanticMatcher) findTopKeywordsByWordVectors(
+	ctx context.Context,
+	paragraph string,
+	paragraphTokens []string,
+	keywords []string,
+	k int,
+	metric WordVectorSimilarityMetric,
+	mapping scoreMapping,
+	startTime time.Time,
+) ([]KeywordMatch, error) {
+	paragraphWords, paragraphOOV := sm.wordVectors(paragraphTokens)
+	if len(paragraphWords) == 0 {
+		sm.updateStats(time.Since(startTime), len(paragraphTokens), paragraphOOV)
+		sm.logger.Warnf("All paragraph words are OOV, token_count: %d", len(paragraphTokens))
+		return make([]KeywordMatch, 0), nil
+	}
+
+	analyzed, processed, canceled := sm.analyzeTextsContext(ctx, keywords, matchOptions{}, metric)
+	keywordWords := make([][][]float32, len(keywords))
+	for i := range analyzed {
+		keywordWords[i] = analyzed[i].words
+	}
+
+	// Keywords pruned by WMD bounds or skipped by a cancellation have no score
+	var scores []float64
+	var pruned []bool
+	if _, exact := metric.(WMDMetric); exact && k > 0 && mapping.preservesOrder() && canceled == nil {
+		scores, pruned, canceled = topWMDSimilarities(ctx, paragraphWords, keywordWords, k)
+	} else {
+		scores, pruned = make([]float64, len(keywords)), make([]bool, len(keywords))
+		for i := range pruned {
+			pruned[i] = true
+		}
+		if canceled == nil {
+			canceled = sm.pool.runContext(ctx, len(keywords), keywordBlockSize, func(start, end int) {
+				for i := start; i < end; i++ {
+					scores[i] = metric.WordVectorSimilarity(paragraphWords, keywordWords[i])
+					pruned[i] = false
+				}
+			})
+		}
+	}
+
+	matches := make([]KeywordMatch, 0, len(keywords))
+	totalTokens, totalOOV, prunedCount := len(paragraphTokens), paragraphOOV, 0
+	for i, keyword := range keywords {
+		if !processed[i] {
+			continue
+		}
+		totalTokens += analyzed[i].info.WordCount
+		totalOOV += analyzed[i].info.OOVCount
+		if pruned[i] {
+			prunedCount++
+			continue
+		}
+		matches = append(matches, KeywordMatch{
+			Keyword:   keyword,
+			Score:     scores[i],
+			WordCount: analyzed[i].info.WordCount,
+			OOVCount:  analyzed[i].info.OOVCount,
+		})
+	}
+	mapping.scoreMatches(paragraph, matches)
+
+	// Stable sorting keeps ties in keyword order, so pruning never changes the top k
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if k > 0 && k < len(matches) {
+		matches = matches[:k]
+	}
+
+	totalDuration := time.Since(startTime)
+	if canceled != nil {
+		sm.updateCanceledStats(totalDuration)
+		sm.logger.Warnf("FindTopKeywords canceled, metric: %s, total_duration_ms: %d, keywords_total: %d, "+
+			"results_returned: %d, error: %v", metric.Name(), totalDuration.Milliseconds(), len(keywords), len(matches),
+			canceled)
+		return matches, canceled
+	}
+
+	sm.updateStats(totalDuration, totalTokens, totalOOV)
+	sm.logger.Infof("FindTopKeywords completed, metric: %s, total_duration_ms: %d, keywords_processed: %d, "+
+		"keywords_pruned: %d, results_returned: %d, total_tokens: %d, total_oov: %d",
+		metric.Name(), totalDuration.Milliseconds(), len(keywords), prunedCount, len(matches), totalTokens, totalOOV)
+
+	return matches, nil
 }
 
 // ComputeSimilarity computes similarity between two texts
-// Returns a value between 0.0 and 1.0 (cosine similarity is normalized to [0, 1])
+// Returns the score of the calculator's metric mapped by the score mode (see Config.ScoreMode),
+// by default limited to [0, upper bound]: [0, 1] for all metrics except dot, whose scores are
+// in [0, ∞). Negative similarity counts as none. Texts without vectors score 0 in every mode.
 func (sm *semanticMatcher) ComputeSimilarity(text1, text2 string) float64 {
+	return sm.ComputeSimilarityWithOptions(text1, text2)
+}
+
+// ComputeSimilarityWithOptions is ComputeSimilarity with per-call overrides
+func (sm *semanticMatcher) ComputeSimilarityWithOptions(text1, text2 string, opts ...MatchOption) float64 {
+	similarity, _ := sm.ComputeSimilarityContext(context.Background(), text1, text2, opts...) //nolint:errcheck
+	return similarity
+}
+
+// ComputeSimilarityContext is ComputeSimilarityWithOptions that returns 0 and ctx.Err() if ctx
+// is done before the texts are preprocessed or vectorized
+//
+//nolint:funlen
+func (sm *semanticMatcher) ComputeSimilarityContext(
+	ctx context.Context,
+	text1, text2 string,
+	opts ...MatchOption,
+) (float64, error) {
 	startTime := time.Now()
+	options := sm.resolveOptions(opts)
 
-	sm.logger.Debugf("ComputeSimilarity called, text1_length: %d, text2_length: %d",
-		len(text1), len(text2))
+	sm.logger.Debugf("ComputeSimilarity called, text1_length: %d, text2_length: %d, pooling: %s",
+		len(text1), len(text2), options.pooling.Name())
+
+	if err := sm.checkCanceled(ctx, "ComputeSimilarity", startTime); err != nil {
+		return 0.0, err
+	}
 
 	// Handle empty inputs
 	if text1 == "" || text2 == "" {
 		sm.updateStats(time.Since(startTime), 0, 0)
 		sm.logger.Debugf("Empty input provided, text1_empty: %v, text2_empty: %v",
 			text1 == "", text2 == "")
-		return 0.0
+		return 0.0, nil
 	}
 
 	// Preprocess both texts
@@ -457,28 +968,41 @@ func (sm *semanticMatcher) ComputeSimilarity(text1, text2 string) float64 {
 		sm.updateStats(time.Since(startTime), 0, 0)
 		sm.logger.Warnf("No valid tokens after preprocessing, tokens1_count: %d, tokens2_count: %d",
 			len(tokens1), len(tokens2))
-		return 0.0
+		return 0.0, nil
 	}
 
-	// Get vectors using mean pooling
-	vectorizeStart := time.Now()
-	vector1, ok1 := sm.model.GetAverageVector(tokens1)
-	vector2, ok2 := sm.model.GetAverageVector(tokens2)
-	vectorizeDuration := time.Since(vectorizeStart)
+	if err := sm.checkCanceled(ctx, "ComputeSimilarity", startTime); err != nil {
+		return 0.0, err
+	}
 
-	// Count OOV words
-	oov1, oov2 := 0, 0
-	for _, token := range tokens1 {
-		if _, exists := sm.model.GetVector(token); !exists {
-			oov1++
-		}
+	// Token metrics compare token sets and need no vectors
+	if metric, ok := sm.calculator.Metric().(TokenSimilarityMetric); ok {
+		similarity := options.scoreMapping(metric).score(metric.TokenSimilarity(tokens1, tokens2),
+			textLanguage(text1), text2)
+		sm.updateStats(time.Since(startTime), len(tokens1)+len(tokens2), 0)
+		sm.logger.Debugf("ComputeSimilarity completed, metric: %s, similarity_score: %.4f",
+			metric.Name(), similarity)
+		return similarity, nil
 	}
-	for _, token := range tokens2 {
-		if _, exists := sm.model.GetVector(token); !exists {
-			oov2++
-		}
+
+	// Word vector metrics align word vectors instead of pooling them
+	if metric, ok := sm.calculator.Metric().(WordVectorSimilarityMetric); ok {
+		words1, oov1 := sm.wordVectors(tokens1)
+		words2, oov2 := sm.wordVectors(tokens2)
+		similarity := options.scoreMapping(metric).score(metric.WordVectorSimilarity(words1, words2),
+			textLanguage(text1), text2)
+		sm.updateStats(time.Since(startTime), len(tokens1)+len(tokens2), oov1+oov2)
+		sm.logger.Debugf("ComputeSimilarity completed, metric: %s, oov1_count: %d, oov2_count: %d, "+
+			"similarity_score: %.4f", metric.Name(), oov1, oov2, similarity)
+		return similarity, nil
 	}
 
+	// Get vectors using the selected pooling
+	vectorizeStart := time.Now()
+	vector1, oov1, ok1 := sm.textVector(tokens1, options)
+	vector2, oov2, ok2 := sm.textVector(tokens2, options)
+	vectorizeDuration := time.Since(vectorizeStart)
+
 	totalTokens := len(tokens1) + len(tokens2)
 	totalOOV := oov1 + oov2
 
@@ -490,19 +1014,17 @@ func (sm *semanticMatcher) ComputeSimilarity(text1, text2 string) float64 {
 			!ok1,
 			!ok2,
 		)
-		return 0.0
+		return 0.0, nil
 	}
 
-	// Compute cosine similarity
+	// Compute similarity with the calculator's metric
 	similarityStart := time.Now()
-	similarity := sm.calculator.CosineSimilarity(vector1, vector2)
+	similarity := sm.calculator.Similarity(vector1, vector2)
 	similarityDuration := time.Since(similarityStart)
 
-	// Normalize to [0, 1] range (cosine similarity is in [-1, 1])
-	// For semantic matching, we typically care about positive similarity
-	if similarity < 0 {
-		similarity = 0
-	}
+	// Map to the score mode, by default [0, upper bound] (e.g. cosine similarity is in [-1, 1]);
+	// for semantic matching, we typically care about positive similarity
+	similarity = options.scoreMapping(sm.calculator.Metric()).score(similarity, textLanguage(text1), text2)
 
 	totalDuration := time.Since(startTime)
 	sm.updateStats(totalDuration, totalTokens, totalOOV)
@@ -553,34 +1075,375 @@ func (sm *semanticMatcher) ComputeSimilarity(text1, text2 string) float64 {
 			oovRate, totalOOV, totalTokens)
 	}
 
-	return similarity
+	return similarity, nil
+}
+
+// SimilarityMatrix computes the similarity of every text in a (rows) to every text in b (columns)
+// Scores are those of ComputeSimilarity. Each distinct text is preprocessed and vectorized once,
+// and texts and scores are computed in parallel.
+func (sm *semanticMatcher) SimilarityMatrix(a, b []string) SimilarityMatrix {
+	return sm.SimilarityMatrixWithOptions(a, b)
+}
+
+// SimilarityMatrixWithOptions is SimilarityMatrix with per-call overrides
+func (sm *semanticMatcher) SimilarityMatrixWithOptions(a, b []string, opts ...MatchOption) SimilarityMatrix {
+	matrix, _ := sm.SimilarityMatrixContext(context.Background(), a, b, opts...) //nolint:errcheck
+	return matrix
+}
+
+// SimilarityMatrixContext is SimilarityMatrixWithOptions that stops between blocks of texts or
+// rows once ctx is done, returning an empty matrix and ctx.Err()
+//
+//nolint:funlen
+func (sm *semanticMatcher) SimilarityMatrixContext(
+	ctx context.Context,
+	a, b []string,
+	opts ...MatchOption,
+) (SimilarityMatrix, error) {
+	startTime := time.Now()
+	options := sm.resolveOptions(opts)
+	metric := sm.calculator.Metric()
+
+	sm.logger.Debugf("SimilarityMatrix called, rows: %d, columns: %d, pooling: %s",
+		len(a), len(b), options.pooling.Name())
+
+	// Analyze each distinct text once, even if it occurs in both sets
+	index := make(map[string]int, len(a)+len(b))
+	distinct := make([]string, 0, len(a)+len(b))
+	positions := func(texts []string) []int {
+		indexes := make([]int, len(texts))
+		for i, text := range texts {
+			idx, exists := index[text]
+			if !exists {
+				idx = len(distinct)
+				index[text] = idx
+				distinct = append(distinct, text)
+			}
+			indexes[i] = idx
+		}
+		return indexes
+	}
+	rowIndexes, columnIndexes := positions(a), positions(b)
+
+	vectorizeStart := time.Now()
+	analyzed, _, err := sm.analyzeTextsContext(ctx, distinct, options, metric)
+	vectorizeDuration := time.Since(vectorizeStart)
+	if err != nil {
+		return SimilarityMatrix{}, sm.matrixCanceled(startTime, len(a), len(b), err)
+	}
+
+	totalTokens, totalOOV := 0, 0
+	for _, text := range analyzed {
+		totalTokens += text.info.WordCount
+		totalOOV += text.info.OOVCount
+	}
+
+	result := SimilarityMatrix{
+		Rows:    make([]TextInfo, len(a)),
+		Columns: make([]TextInfo, len(b)),
+	}
+	rowVectors := make([][]float32, len(a))
+	columnVectors := make([][]float32, len(b))
+	for i, idx := range rowIndexes {
+		result.Rows[i] = analyzed[idx].info
+		rowVectors[i] = analyzed[idx].vector
+	}
+	for j, idx := range columnIndexes {
+		result.Columns[j] = analyzed[idx].info
+		columnVectors[j] = analyzed[idx].vector
+	}
+
+	// Token and word vector metrics score each pair of texts; vector metrics use the calculator
+	var pairScore func(row, column analyzedText) float64
+	switch metric := metric.(type) {
+	case TokenSimilarityMetric:
+		pairScore = func(row, column analyzedText) float64 {
+			return metric.TokenSimilarity(row.tokens, column.tokens)
+		}
+	case WordVectorSimilarityMetric:
+		pairScore = func(row, column analyzedText) float64 {
+			return metric.WordVectorSimilarity(row.words, column.words)
+		}
+	}
+
+	mapping := options.scoreMapping(metric)
+	similarityStart := time.Now()
+	if pairScore != nil {
+		result.Scores = newDenseMatrix(len(a), len(b))
+		err = sm.pool.runWorkContext(ctx, len(a)*len(b), len(a), matrixBlockSize, func(start, end int) {
+			for i := start; i < end; i++ {
+				language := textLanguage(a[i])
+				for j, idx := range columnIndexes {
+					result.Scores[i][j] = mapping.score(pairScore(analyzed[rowIndexes[i]], analyzed[idx]), language, b[j])
+				}
+			}
+		})
+		if err != nil {
+			return SimilarityMatrix{}, sm.matrixCanceled(startTime, len(a), len(b), err)
+		}
+	} else {
+		// Texts without a vector have nil vectors, which keep 0
+		result.Scores = sm.calculator.SimilarityMatrix(rowVectors, columnVectors)
+		for i, row := range result.Scores {
+			language := textLanguage(a[i])
+			for j, score := range row {
+				if rowVectors[i] != nil && columnVectors[j] != nil {
+					row[j] = mapping.score(score, language, b[j])
+				}
+			}
+		}
+	}
+	similarityDuration := time.Since(similarityStart)
+
+	totalDuration := time.Since(startTime)
+	sm.updateStats(totalDuration, totalTokens, totalOOV)
+
+	sm.logger.Infof(
+		"SimilarityMatrix completed, total_duration_ms: %d, vectorize_duration_ms: %d, "+
+			"similarity_duration_ms: %d, rows: %d, columns: %d, distinct_texts: %d, total_tokens: %d, total_oov: %d",
+		totalDuration.Milliseconds(),
+		vectorizeDuration.Milliseconds(),
+		similarityDuration.Milliseconds(),
+		len(a),
+		len(b),
+		len(distinct),
+		totalTokens,
+		totalOOV,
+	)
+
+	return result, nil
+}
+
+// matrixCanceled records a canceled SimilarityMatrix call and returns err
+func (sm *semanticMatcher) matrixCanceled(startTime time.Time, rows, columns int, err error) error {
+	totalDuration := time.Since(startTime)
+	sm.updateCanceledStats(totalDuration)
+	sm.logger.Warnf("SimilarityMatrix canceled, total_duration_ms: %d, rows: %d, columns: %d, error: %v",
+		totalDuration.Milliseconds(), rows, columns, err)
+	return err
+}
+
+// analyzedText is a preprocessed keyword or text to score
+type analyzedText struct {
+	info   TextInfo
+	tokens []string
+	vector []float32   // nil if the text has no vector
+	words  [][]float32 // Word vectors, only for word vector metrics
+}
+
+// analyzeTexts analyzes texts in parallel blocks if there are many; results are in input order
+func (sm *semanticMatcher) analyzeTexts(texts []string, options matchOptions, metric SimilarityMetric) []analyzedText {
+	analyzed, _, _ := sm.analyzeTextsContext(context.Background(), texts, options, metric) //nolint:dogsled
+	return analyzed
+}
+
+// analyzeTextsContext is analyzeTexts that starts no further blocks once ctx is done; processed
+// marks the texts analyzed, and the error is ctx.Err() if any were skipped
+func (sm *semanticMatcher) analyzeTextsContext(
+	ctx context.Context,
+	texts []string,
+	options matchOptions,
+	metric SimilarityMetric,
+) ([]analyzedText, []bool, error) {
+	analyzed := make([]analyzedText, len(texts))
+	processed := make([]bool, len(texts))
+	err := sm.pool.runContext(ctx, len(texts), keywordBlockSize, func(start, end int) {
+		for i := start; i < end; i++ {
+			analyzed[i] = sm.analyzeText(texts[i], options, metric)
+			processed[i] = true
+		}
+	})
+	return analyzed, processed, err
+}
+
+// analyzeText preprocesses a text and computes what metric needs: nothing more for token
+// metrics, the word vectors for word vector metrics, and the pooled vector otherwise, with the
+// OOV count in both latter cases
+func (sm *semanticMatcher) analyzeText(text string, options matchOptions, metric SimilarityMetric) analyzedText {
+	tokens := sm.processor.Preprocess(text)
+	result := analyzedText{
+		info:   TextInfo{Text: text, WordCount: len(tokens), Scored: len(tokens) > 0},
+		tokens: tokens,
+	}
+	if len(tokens) == 0 {
+		return result
+	}
+
+	switch metric.(type) {
+	case TokenSimilarityMetric:
+		return result
+	case WordVectorSimilarityMetric:
+		result.words, result.info.OOVCount = sm.wordVectors(tokens)
+		result.info.Scored = len(result.words) > 0
+		return result
+	}
+
+	vector, oovCount, ok := sm.textVector(tokens, options)
+	result.info.OOVCount = oovCount
+	if !ok {
+		result.info.Scored = false
+		return result
+	}
+	result.vector = vector
+	return result
+}
+
+// wordVectors returns the vectors of the tokens found in the model, in token order, and the
+// number of OOV tokens
+func (sm *semanticMatcher) wordVectors(tokens []string) ([][]float32, int) {
+	words := make([][]float32, 0, len(tokens))
+	for _, token := range tokens {
+		if vector, exists := sm.model.GetVector(token); exists {
+			words = append(words, vector)
+		}
+	}
+	return words, len(tokens) - len(words)
+}
+
+// VectorizeText preprocesses a text and returns its pooled vector
+// Returns false if the text has no valid tokens or all tokens are OOV
+func (sm *semanticMatcher) VectorizeText(text string) ([]float32, bool) {
+	return sm.VectorizeTextWithOptions(text)
+}
+
+// VectorizeTextWithOptions is VectorizeText with per-call overrides
+func (sm *semanticMatcher) VectorizeTextWithOptions(text string, opts ...MatchOption) ([]float32, bool) {
+	options := sm.resolveOptions(opts)
+
+	tokens := sm.processor.Preprocess(text)
+	if len(tokens) == 0 {
+		return nil, false
+	}
+
+	vector, _, ok := sm.textVector(tokens, options)
+	return vector, ok
+}
+
+// textVector pools the token vectors with the call's pooling and weighting, then removes
+// the common component if one applies. It also returns the number of OOV tokens, counted
+// during the pooling lookups for models of this package so each token is looked up once.
+func (sm *semanticMatcher) textVector(tokens []string, options matchOptions) ([]float32, int, bool) {
+	var vector []float32
+	var oovCount int
+	var ok bool
+	if model, isLayerModel := sm.model.(layerModel); isLayerModel {
+		vector, oovCount, ok = model.pooledVector(tokens, options.pooling, options.weighting)
+	} else if vector, ok = sm.model.GetWeightedPooledVector(tokens, options.pooling, options.weighting); ok {
+		for _, token := range tokens {
+			if _, exists := sm.model.GetVector(token); !exists {
+				oovCount++
+			}
+		}
+	}
+	if !ok {
+		return nil, len(tokens), false
+	}
+	return options.commonComponent.Remove(vector), oovCount, true
+}
+
+// FitCommonComponent fits the common component of the sample texts' vectors, computed with
+// the matcher's pooling and weighting or the given overrides, and removes it from text
+// vectors computed with the same pooling and weighting from now on
+// Returns ErrEmptyInput if no sample text has a vector.
+func (sm *semanticMatcher) FitCommonComponent(sampleTexts []string, opts ...MatchOption) error {
+	options := sm.resolveOptions(opts)
+	options.commonComponent = nil
+
+	vectors := make([][]float32, 0, len(sampleTexts))
+	for _, text := range sampleTexts {
+		tokens := sm.processor.Preprocess(text)
+		if len(tokens) == 0 {
+			continue
+		}
+		if vector, _, ok := sm.textVector(tokens, options); ok {
+			vectors = append(vectors, vector)
+		}
+	}
+
+	component, err := FitCommonComponent(vectors)
+	if err != nil {
+		return err
+	}
+	component.pooling = options.pooling.Name()
+	component.weighting = tokenWeightingName(options.weighting)
+
+	sm.mtx.Lock()
+	sm.commonComponent = component
+	sm.mtx.Unlock()
+
+	sm.logger.Infof("Common component fitted, sample_texts: %d, vectorized: %d, pooling: %s, weighting: %s",
+		len(sampleTexts), len(vectors), component.pooling, component.weighting)
+	return nil
+}
+
+// fitCommonComponentFromFile fits the matcher's common component on the lines of a sample file
+func fitCommonComponentFromFile(matcher *semanticMatcher, path string, logger Logger) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Errorf("Failed to read common component sample, path: %s, error: %v", path, err)
+		return err
+	}
+
+	if err := matcher.FitCommonComponent(strings.Split(string(data), "\n")); err != nil {
+		logger.Errorf("Failed to fit common component, path: %s, error: %v", path, err)
+		return err
+	}
+	return nil
+}
+
+// ReloadVectorLayer reloads the vector files of a layer from Config.VectorLayers, or of the
+// "base" layer, and swaps them in while the matcher keeps serving requests
+// A fitted common component is kept; call FitCommonComponent again if the vectors changed much.
+func (sm *semanticMatcher) ReloadVectorLayer(name string) error {
+	layered, ok := sm.model.(*LayeredVectorModel)
+	paths, configured := sm.layerPaths[name]
+	if !ok || !configured || sm.loader == nil {
+		return fmt.Errorf("%w: %s", ErrLayerNotFound, name)
+	}
+
+	sm.logger.Infof("Reloading vector layer, layer: %s, paths: %v", name, paths)
+	model, err := sm.loader.LoadMultipleFiles(paths)
+	if err != nil {
+		sm.logger.Errorf("Failed to reload vector layer, layer: %s, error: %v", name, err)
+		return err
+	}
+	if err := layered.ReplaceLayer(name, model); err != nil {
+		return err
+	}
+
+	sm.logger.Infof("Vector layer reloaded, layer: %s, vocabulary_size: %d", name, model.VocabularySize())
+	return nil
 }
 
 // GetStats returns performance and usage statistics
 func (sm *semanticMatcher) GetStats() MatcherStats {
+	// Copy the request counters under the read lock; concurrent calls must not write sm.stats
 	sm.mtx.RLock()
-	defer sm.mtx.RUnlock()
-
-	// Update stats with current model statistics
-	sm.stats.OOVRate = sm.model.GetOOVRate()
-	sm.stats.VectorHitRate = sm.model.GetVectorHitRate()
-	sm.stats.MemoryUsage = sm.model.MemoryUsage()
-	sm.stats.LastUpdated = time.Now()
+	stats := MatcherStats{
+		TotalRequests:     sm.stats.TotalRequests,
+		CompletedRequests: sm.stats.CompletedRequests,
+		CanceledRequests:  sm.stats.CanceledRequests,
+		AverageLatency:    sm.stats.AverageLatency,
+	}
+	sm.mtx.RUnlock()
+
+	// Add the current model statistics, which the model synchronizes itself
+	stats.OOVRate = sm.model.GetOOVRate()
+	stats.VectorHitRate = sm.model.GetVectorHitRate()
+	stats.MemoryUsage = sm.model.MemoryUsage()
+	stats.TopOOVWords = sm.model.TopOOVWords(DefaultTopOOVReportSize)
+	stats.FallbackCache = sm.model.GetFallbackCacheStats()
+	if layered, ok := sm.model.(*LayeredVectorModel); ok {
+		stats.Layers = layered.LayerStats()
+	}
+	stats.LastUpdated = time.Now()
 
 	sm.logger.Debugf("Statistics retrieved, total_requests: %d, average_latency_ms: %d, "+
 		"oov_rate: %.4f, vector_hit_rate: %.4f, memory_usage_mb: %.2f",
-		sm.stats.TotalRequests, sm.stats.AverageLatency.Milliseconds(),
-		sm.stats.OOVRate, sm.stats.VectorHitRate, float64(sm.stats.MemoryUsage)/(1024*1024))
+		stats.TotalRequests, stats.AverageLatency.Milliseconds(),
+		stats.OOVRate, stats.VectorHitRate, float64(stats.MemoryUsage)/(1024*1024))
 
-	// Return a copy to prevent external modification
-	return MatcherStats{
-		TotalRequests:  sm.stats.TotalRequests,
-		AverageLatency: sm.stats.AverageLatency,
-		OOVRate:        sm.stats.OOVRate,
-		VectorHitRate:  sm.stats.VectorHitRate,
-		MemoryUsage:    sm.stats.MemoryUsage,
-		LastUpdated:    sm.stats.LastUpdated,
-	}
+	return stats
 }
 
 // updateStats updates the internal statistics
@@ -591,14 +1454,15 @@ func (sm *semanticMatcher) updateStats(latency time.Duration, totalTokens, oovTo
 	sm.logger.Debugf("Total tokens, total_tokens: %d, oov_tokens: %d", totalTokens, oovTokens)
 
 	sm.stats.TotalRequests++
+	sm.stats.CompletedRequests++
 
-	// Update average latency using incremental average formula
+	// Update average latency of completed requests using incremental average formula
 	// new_avg = old_avg + (new_value - old_avg) / count
-	if sm.stats.TotalRequests == 1 {
+	if sm.stats.CompletedRequests == 1 {
 		sm.stats.AverageLatency = latency
 	} else {
 		delta := latency - sm.stats.AverageLatency
-		sm.stats.AverageLatency += delta / time.Duration(sm.stats.TotalRequests)
+		sm.stats.AverageLatency += delta / time.Duration(sm.stats.CompletedRequests)
 	}
 
 	// Log performance metrics if logger is available
@@ -611,3 +1475,24 @@ func (sm *semanticMatcher) updateStats(latency time.Duration, totalTokens, oovTo
 			float64(sm.model.MemoryUsage())/(1024*1024))
 	}
 }
+
+// updateCanceledStats counts a request stopped by its context; its latency is not averaged
+func (sm *semanticMatcher) updateCanceledStats(latency time.Duration) {
+	sm.mtx.Lock()
+	defer sm.mtx.Unlock()
+
+	sm.stats.TotalRequests++
+	sm.stats.CanceledRequests++
+	sm.logger.Debugf("Request canceled, latency_ms: %d, canceled_requests: %d",
+		latency.Milliseconds(), sm.stats.CanceledRequests)
+}
+
+// checkCanceled records a canceled call of method and returns ctx.Err() if ctx is done
+func (sm *semanticMatcher) checkCanceled(ctx context.Context, method string, startTime time.Time) error {
+	err := ctx.Err()
+	if err != nil {
+		sm.updateCanceledStats(time.Since(startTime))
+		sm.logger.Warnf("%s canceled, error: %v", method, err)
+	}
+	return err
+}
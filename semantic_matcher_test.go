@@ -1,6 +1,9 @@
 package semanticmatcher
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -1073,3 +1076,241 @@ func TestFallbackWithEmptyResults(t *testing.T) {
 		t.Error("Expected OOV warning to be logged")
 	}
 }
+
+func TestSemanticMatcher_SimilarityMatrix(t *testing.T) {
+	matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(), NewSimilarityCalculator())
+
+	rows := []string{"测试", "这是一个测试段落", "", "测试"}
+	columns := []string{"文本", "测试", "qqq"}
+	matrix := matcher.SimilarityMatrix(rows, columns)
+
+	if len(matrix.Rows) != len(rows) || len(matrix.Columns) != len(columns) || len(matrix.Scores) != len(rows) {
+		t.Fatalf("Expected a %dx%d matrix, got %d rows, %d columns, %d score rows",
+			len(rows), len(columns), len(matrix.Rows), len(matrix.Columns), len(matrix.Scores))
+	}
+
+	for i, row := range rows {
+		if matrix.Rows[i].Text != row {
+			t.Errorf("Expected row %d to be %q, got %q", i, row, matrix.Rows[i].Text)
+		}
+		for j, column := range columns {
+			expected := matcher.ComputeSimilarity(row, column)
+			if diff := matrix.Scores[i][j] - expected; diff > 1e-6 || diff < -1e-6 {
+				t.Errorf("Score of %q and %q: expected %f, got %f", row, column, expected, matrix.Scores[i][j])
+			}
+		}
+	}
+
+	if matrix.Rows[2].Scored || matrix.Rows[2].WordCount != 0 {
+		t.Errorf("Expected the empty text to be unscored without words, got %+v", matrix.Rows[2])
+	}
+	if info := matrix.Columns[2]; info.Scored || info.WordCount == 0 || info.OOVCount != info.WordCount {
+		t.Errorf("Expected the OOV text to be unscored with all words OOV, got %+v", info)
+	}
+	if info := matrix.Rows[1]; !info.Scored || info.WordCount == 0 || info.OOVCount != 0 {
+		t.Errorf("Expected the paragraph to be scored without OOV words, got %+v", info)
+	}
+
+	empty := matcher.SimilarityMatrix(nil, columns)
+	if len(empty.Scores) != 0 || len(empty.Columns) != len(columns) {
+		t.Errorf("Expected no score rows for no row texts, got %+v", empty)
+	}
+
+	jaccard := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(),
+		NewSimilarityCalculatorWithMetric(JaccardMetric{}))
+	tokenMatrix := jaccard.SimilarityMatrix([]string{"测试 段落"}, []string{"段落", "qqq"})
+	if tokenMatrix.Scores[0][0] != 0.5 || tokenMatrix.Scores[0][1] != 0 || !tokenMatrix.Columns[1].Scored {
+		t.Errorf("Expected Jaccard scores [0.5 0] with token-scored texts, got %v %+v",
+			tokenMatrix.Scores, tokenMatrix.Columns)
+	}
+}
+
+func TestSemanticMatcher_ParallelMatchesSerial(t *testing.T) {
+	words := []string{"这", "是", "一个", "测试", "段落", "文本", "关键词", "第一个", "第二个", "qqq"}
+	keywords := make([]string, 3000)
+	for i := range keywords {
+		keywords[i] = words[i%len(words)] + " " + words[(i*7)%len(words)] + " " + words[(i*3)%len(words)]
+	}
+	paragraph := "这是一个测试段落"
+
+	newMatcher := func(workers, threshold int) *semanticMatcher {
+		calculator := NewSimilarityCalculator()
+		calculator.(*similarityCalculator).SetParallelism(workers, threshold)
+		matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(), calculator).(*semanticMatcher)
+		matcher.pool = newWorkerPool(workers, threshold)
+		return matcher
+	}
+	serial := newMatcher(1, 0)
+	parallel := newMatcher(4, 1)
+
+	expected := serial.FindTopKeywords(paragraph, keywords, 0)
+	actual := parallel.FindTopKeywords(paragraph, keywords, 0)
+	if len(actual) != len(expected) {
+		t.Fatalf("Expected %d matches, got %d", len(expected), len(actual))
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("Match %d differs: serial %+v, parallel %+v", i, expected[i], actual[i])
+		}
+	}
+
+	expectedMatrix := serial.SimilarityMatrix(keywords[:100], keywords[100:200])
+	actualMatrix := parallel.SimilarityMatrix(keywords[:100], keywords[100:200])
+	for i := range expectedMatrix.Scores {
+		for j := range expectedMatrix.Scores[i] {
+			if actualMatrix.Scores[i][j] != expectedMatrix.Scores[i][j] {
+				t.Errorf("Matrix score (%d, %d) differs: serial %f, parallel %f",
+					i, j, expectedMatrix.Scores[i][j], actualMatrix.Scores[i][j])
+			}
+		}
+	}
+}
+
+// cancelingProcessor cancels a context once it preprocesses the text stop
+type cancelingProcessor struct {
+	TextProcessor
+	stop   string
+	cancel context.CancelFunc
+}
+
+func (p *cancelingProcessor) Preprocess(text string) []string {
+	if text == p.stop {
+		p.cancel()
+	}
+	return p.TextProcessor.Preprocess(text)
+}
+
+func TestFindTopKeywordsContext_Canceled(t *testing.T) {
+	matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(), NewSimilarityCalculator())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := matcher.FindTopKeywordsContext(ctx, "这是一个测试段落", []string{"测试", "段落"}, 2)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+
+	stats := matcher.GetStats()
+	if stats.TotalRequests != 1 || stats.CanceledRequests != 1 || stats.CompletedRequests != 0 {
+		t.Errorf("Expected 1 canceled request, got %+v", stats)
+	}
+}
+
+func TestFindTopKeywordsContext_PartialResults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processor := &cancelingProcessor{TextProcessor: NewTextProcessor(), stop: "关键词", cancel: cancel}
+	matcher := NewSemanticMatcher(processor, createTestVectorModel(), NewSimilarityCalculator())
+
+	// The first block of keywords is scored; the context is canceled while it is analyzed
+	keywords := make([]string, 3*keywordBlockSize)
+	for i := range keywords {
+		keywords[i] = "测试"
+	}
+	keywords[1] = "关键词"
+
+	results, err := matcher.FindTopKeywordsContext(ctx, "这是一个测试段落", keywords, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(results) != keywordBlockSize {
+		t.Errorf("Expected %d partial results, got %d", keywordBlockSize, len(results))
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("Partial results not sorted at %d", i)
+		}
+	}
+
+	if stats := matcher.GetStats(); stats.CanceledRequests != 1 {
+		t.Errorf("Expected 1 canceled request, got %d", stats.CanceledRequests)
+	}
+}
+
+func TestFindTopKeywords_TiesKeepInputOrder(t *testing.T) {
+	model := NewVectorModel(2).(*vectorModel)
+	model.AddVector("paragraph", []float32{1, 1})
+	// Alternate two scores, so that sorting moves keywords past each other
+	keywords := make([]string, 3*keywordBlockSize)
+	var near, far []string
+	for i := range keywords {
+		keywords[i] = fmt.Sprintf("keyword%d", i)
+		if i%2 == 0 {
+			model.AddVector(keywords[i], []float32{1, 0})
+			far = append(far, keywords[i])
+		} else {
+			model.AddVector(keywords[i], []float32{1, 1})
+			near = append(near, keywords[i])
+		}
+	}
+	matcher := NewSemanticMatcher(NewTextProcessor(), model, NewSimilarityCalculator())
+
+	results := matcher.FindTopKeywords("paragraph", keywords, 0)
+	expected := append(near, far...)
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+	for i, result := range results {
+		if result.Keyword != expected[i] {
+			t.Errorf("Expected tied keyword %s at %d, got %s", expected[i], i, result.Keyword)
+		}
+	}
+}
+
+func TestComputeSimilarityContext(t *testing.T) {
+	matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(), NewSimilarityCalculator())
+
+	similarity, err := matcher.ComputeSimilarityContext(context.Background(), "测试", "段落")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := matcher.ComputeSimilarity("测试", "段落"); similarity != expected {
+		t.Errorf("Expected %f, got %f", expected, similarity)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	similarity, err = matcher.ComputeSimilarityContext(ctx, "测试", "段落")
+	if !errors.Is(err, context.Canceled) || similarity != 0 {
+		t.Errorf("Expected 0 and context.Canceled, got %f and %v", similarity, err)
+	}
+
+	stats := matcher.GetStats()
+	if stats.TotalRequests != 3 || stats.CompletedRequests != 2 || stats.CanceledRequests != 1 {
+		t.Errorf("Expected 2 completed and 1 canceled request, got %+v", stats)
+	}
+}
+
+func TestSimilarityMatrixContext_Canceled(t *testing.T) {
+	matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(), NewSimilarityCalculator())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	matrix, err := matcher.SimilarityMatrixContext(ctx, []string{"测试"}, []string{"段落", "文本"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(matrix.Scores) != 0 {
+		t.Errorf("Expected an empty matrix, got %d rows", len(matrix.Scores))
+	}
+	if stats := matcher.GetStats(); stats.CanceledRequests != 1 {
+		t.Errorf("Expected 1 canceled request, got %d", stats.CanceledRequests)
+	}
+}
+
+func BenchmarkFindTopKeywords_ManyKeywords(b *testing.B) {
+	words := []string{"这", "是", "一个", "测试", "段落", "文本", "关键词", "第一个", "第二个"}
+	keywords := make([]string, 20000)
+	for i := range keywords {
+		keywords[i] = words[i%len(words)] + words[(i*7)%len(words)]
+	}
+	matcher := NewSemanticMatcher(NewTextProcessor(), createTestVectorModel(), NewSimilarityCalculator())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matcher.FindTopKeywords("这是一个测试段落", keywords, 10)
+	}
+}
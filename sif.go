@@ -0,0 +1,209 @@
+package semanticmatcher
+
+import (
+	"fmt"
+	"math"
+)
+
+// Token weighting names accepted in Config.TokenWeighting
+const (
+	WeightingNone = "none"
+	WeightingSIF  = "sif"
+)
+
+// DefaultSIFParameter is the smoothing parameter a used by SIFWeighting when A is not positive
+const DefaultSIFParameter = 1e-3
+
+// commonComponentIterations bounds the power iteration used to fit a common component
+const commonComponentIterations = 100
+
+// TokenWeighting assigns each token a weight; token vectors are scaled by their weight
+// before pooling, so frequent, uninformative tokens contribute less to the text vector
+type TokenWeighting interface {
+	// Name identifies the weighting in configuration and logs
+	Name() string
+
+	// Weight returns the weight of a normalized token. rank is the token's 1-based frequency
+	// rank in the vector file, or 0 if unknown, and rankedWords is the largest rank loaded.
+	Weight(token string, rank, rankedWords int) float64
+}
+
+// SIFWeighting implements Smooth Inverse Frequency weighting, a / (a + p(w)), where the
+// unigram probability p(w) is estimated from the word's rank in the vector file with
+// Zipf's law: p(w) = 1 / (rank * H(N)), with H(N) the N-th harmonic number.
+// Words without a rank are treated as the rarest word. A zero or negative A uses
+// DefaultSIFParameter.
+type SIFWeighting struct {
+	A float64
+}
+
+// Name returns the weighting name
+func (SIFWeighting) Name() string {
+	return WeightingSIF
+}
+
+// Weight returns a / (a + p(w)); all words weigh 1 if no ranks were loaded
+func (s SIFWeighting) Weight(_ string, rank, rankedWords int) float64 {
+	if rankedWords <= 0 {
+		return 1
+	}
+	if rank <= 0 || rank > rankedWords {
+		rank = rankedWords
+	}
+
+	a := s.A
+	if a <= 0 {
+		a = DefaultSIFParameter
+	}
+
+	probability := 1 / (float64(rank) * harmonicNumber(rankedWords))
+	return a / (a + probability)
+}
+
+// harmonicNumber approximates the n-th harmonic number, the normalizer of Zipf's law
+func harmonicNumber(n int) float64 {
+	const eulerGamma = 0.5772156649015329
+	if n <= 0 {
+		return 0
+	}
+	if n < 100 {
+		var sum float64
+		for i := 1; i <= n; i++ {
+			sum += 1 / float64(i)
+		}
+		return sum
+	}
+	return math.Log(float64(n)) + eulerGamma + 1/(2*float64(n))
+}
+
+// NewTokenWeighting returns the weighting for a Config.TokenWeighting name
+// An empty name or "none" returns nil, meaning all tokens weigh the same.
+// sifParameter is the a parameter of SIF weighting.
+func NewTokenWeighting(name string, sifParameter float64) (TokenWeighting, error) {
+	switch name {
+	case "", WeightingNone:
+		return nil, nil //nolint:nilnil // nil weighting means uniform weights
+	case WeightingSIF:
+		return SIFWeighting{A: sifParameter}, nil
+	case WeightingTFIDF:
+		return nil, fmt.Errorf("%w: %s weighting needs an IDF model, see LoadIDFModel",
+			ErrInvalidConfiguration, WeightingTFIDF)
+	default:
+		return nil, fmt.Errorf("%w: unknown token weighting %q", ErrInvalidConfiguration, name)
+	}
+}
+
+// tokenWeightingName returns the name of weighting, or "none" for nil
+func tokenWeightingName(weighting TokenWeighting) string {
+	if weighting == nil {
+		return WeightingNone
+	}
+	return weighting.Name()
+}
+
+// CommonComponent is the first principal component of a sample of text vectors.
+// Removing it from text vectors drops the direction shared by all texts (mostly syntax
+// and very frequent words), the second step of SIF embeddings.
+type CommonComponent struct {
+	direction []float32 // Unit vector
+	pooling   string    // Pooling the sample was vectorized with
+	weighting string    // Token weighting the sample was vectorized with
+}
+
+// FitCommonComponent computes the first (uncentered) principal component of vectors by
+// power iteration. All vectors must have the same, non-zero dimension.
+func FitCommonComponent(vectors [][]float32) (*CommonComponent, error) {
+	if len(vectors) == 0 || len(vectors[0]) == 0 {
+		return nil, ErrEmptyInput
+	}
+
+	dim := len(vectors[0])
+	for _, vector := range vectors {
+		if len(vector) != dim {
+			return nil, ErrDimensionMismatch
+		}
+	}
+
+	// Start from the mean direction, which is close to the component for typical embeddings
+	direction := make([]float64, dim)
+	for _, vector := range vectors {
+		for i, val := range vector {
+			direction[i] += float64(val)
+		}
+	}
+	if !normalizeFloat64(direction) {
+		for i := range direction {
+			direction[i] = 1
+		}
+		normalizeFloat64(direction)
+	}
+
+	next := make([]float64, dim)
+	for range commonComponentIterations {
+		clear(next)
+		for _, vector := range vectors {
+			var projection float64
+			for i, val := range vector {
+				projection += float64(val) * direction[i]
+			}
+			for i, val := range vector {
+				next[i] += projection * float64(val)
+			}
+		}
+		if !normalizeFloat64(next) {
+			// All vectors are orthogonal to the current direction, so it carries no variance
+			break
+		}
+
+		var delta float64
+		for i := range next {
+			delta = max(delta, math.Abs(next[i]-direction[i]))
+		}
+		direction, next = next, direction
+		if delta < 1e-9 {
+			break
+		}
+	}
+
+	component := &CommonComponent{direction: make([]float32, dim)}
+	for i, val := range direction {
+		component.direction[i] = float32(val)
+	}
+	return component, nil
+}
+
+// Direction returns a copy of the component's unit vector
+func (c *CommonComponent) Direction() []float32 {
+	return copyVector(c.direction)
+}
+
+// Remove returns vector minus its projection onto the component
+// Vectors of a different dimension are returned unchanged.
+func (c *CommonComponent) Remove(vector []float32) []float32 {
+	if c == nil || len(vector) != len(c.direction) {
+		return vector
+	}
+
+	projection := float32(DotProduct(vector, c.direction))
+	result := make([]float32, len(vector))
+	for i, val := range vector {
+		result[i] = val - projection*c.direction[i]
+	}
+	return result
+}
+
+// normalizeFloat64 scales v to unit length in place; returns false if v is zero
+func normalizeFloat64(v []float64) bool {
+	var norm float64
+	for _, val := range v {
+		norm += val * val
+	}
+	if norm == 0 {
+		return false
+	}
+	norm = math.Sqrt(norm)
+	for i := range v {
+		v[i] /= norm
+	}
+	return true
+}
@@ -0,0 +1,233 @@
+package semanticmatcher
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sifTestVectors is a small .vec file; lines are ordered by (pretend) corpus frequency
+const sifTestVectors = `5 3
+common 1 1 0
+market 1 0 0
+stock 0.9 0.1 0
+weather 0 0 1
+rain 0 0.1 0.9
+`
+
+func loadSIFTestModel(t *testing.T) *vectorModel {
+	t.Helper()
+	model, err := NewEmbeddingLoader(DiscardLogger{}).LoadFromReader(strings.NewReader(sifTestVectors))
+	require.NoError(t, err)
+	return model.(*vectorModel)
+}
+
+func TestSIFWeighting_Weight(t *testing.T) {
+	sif := SIFWeighting{A: 1e-3}
+
+	frequent := sif.Weight("the", 1, 100000)
+	rare := sif.Weight("zymurgy", 90000, 100000)
+	assert.Less(t, frequent, 0.1)
+	assert.Greater(t, rare, 0.9)
+	assert.Less(t, rare, 1.0)
+
+	// Words without a rank weigh like the rarest word
+	assert.InDelta(t, sif.Weight("x", 100000, 100000), sif.Weight("x", 0, 100000), 1e-12)
+
+	// Without ranks, SIF is uniform
+	assert.InDelta(t, 1.0, sif.Weight("x", 0, 0), 1e-12)
+
+	// Default parameter
+	assert.InDelta(t, SIFWeighting{A: DefaultSIFParameter}.Weight("x", 10, 1000),
+		SIFWeighting{}.Weight("x", 10, 1000), 1e-12)
+
+	// p(w) = 1 / (rank * H(N))
+	expected := 1e-3 / (1e-3 + 1/(2*harmonicNumber(5)))
+	assert.InDelta(t, expected, sif.Weight("x", 2, 5), 1e-12)
+}
+
+func TestHarmonicNumber(t *testing.T) {
+	assert.InDelta(t, 1.0, harmonicNumber(1), 1e-12)
+	assert.InDelta(t, 1.5, harmonicNumber(2), 1e-12)
+
+	// The asymptotic expansion agrees with the exact sum
+	var exact float64
+	for i := 1; i <= 1000; i++ {
+		exact += 1 / float64(i)
+	}
+	assert.InDelta(t, exact, harmonicNumber(1000), 1e-6)
+}
+
+func TestNewTokenWeighting(t *testing.T) {
+	weighting, err := NewTokenWeighting("", 0)
+	require.NoError(t, err)
+	assert.Nil(t, weighting)
+	assert.Equal(t, WeightingNone, tokenWeightingName(weighting))
+
+	weighting, err = NewTokenWeighting(WeightingSIF, 1e-4)
+	require.NoError(t, err)
+	assert.Equal(t, SIFWeighting{A: 1e-4}, weighting)
+
+	_, err = NewTokenWeighting("tfidf", 0)
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+}
+
+func TestEmbeddingLoader_RecordsWordRanks(t *testing.T) {
+	model := loadSIFTestModel(t)
+
+	rank, ok := model.WordRank("common")
+	require.True(t, ok)
+	assert.Equal(t, 1, rank)
+
+	rank, ok = model.WordRank("rain")
+	require.True(t, ok)
+	assert.Equal(t, 5, rank)
+
+	_, ok = model.WordRank("missing")
+	assert.False(t, ok)
+
+	// Words added directly have no rank
+	model.AddVector("manual", []float32{1, 0, 0})
+	_, ok = model.WordRank("manual")
+	assert.False(t, ok)
+
+	// Ranks follow keys when the vocabulary is re-normalized
+	model.SetNormalizer(NewNormalizer(NormalizationConfig{CaseFold: true}))
+	rank, ok = model.WordRank("WEATHER")
+	require.True(t, ok)
+	assert.Equal(t, 4, rank)
+}
+
+func TestEmbeddingLoader_LaterFileRankWins(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.vec")
+	second := filepath.Join(dir, "second.vec")
+	require.NoError(t, os.WriteFile(first, []byte("2 2\na 1 0\nb 0 1\n"), 0o644))
+	require.NoError(t, os.WriteFile(second, []byte("1 2\nb 1 1\n"), 0o644))
+
+	model, err := NewEmbeddingLoader(DiscardLogger{}).LoadMultipleFiles([]string{first, second})
+	require.NoError(t, err)
+
+	rank, ok := model.WordRank("b")
+	require.True(t, ok)
+	assert.Equal(t, 1, rank)
+}
+
+func TestVectorModel_GetWeightedPooledVector(t *testing.T) {
+	model := loadSIFTestModel(t)
+	sif := SIFWeighting{A: 0.1}
+
+	// The frequent word contributes less than the rare one
+	vector, ok := model.GetWeightedPooledVector([]string{"common", "weather"}, MeanPooling{}, sif)
+	require.True(t, ok)
+	commonWeight := sif.Weight("common", 1, 5)
+	weatherWeight := sif.Weight("weather", 4, 5)
+	assert.InDeltaSlice(t, []float32{
+		float32(commonWeight / 2),
+		float32(commonWeight / 2),
+		float32(weatherWeight / 2),
+	}, vector, 1e-6)
+
+	// A nil weighting is plain pooling
+	weighted, ok := model.GetWeightedPooledVector([]string{"common", "weather"}, MeanPooling{}, nil)
+	require.True(t, ok)
+	plain, _ := model.GetPooledVector([]string{"common", "weather"}, MeanPooling{})
+	assert.Equal(t, plain, weighted)
+
+	// Stored vectors are not modified by weighting
+	stored, _ := model.GetVector("common")
+	assert.Equal(t, []float32{1, 1, 0}, stored)
+}
+
+func TestFitCommonComponent(t *testing.T) {
+	vectors := [][]float32{
+		{2, 2, 0.1},
+		{1.5, 1.4, -0.1},
+		{3, 3.1, 0},
+		{-1, -1, 0.2},
+	}
+
+	component, err := FitCommonComponent(vectors)
+	require.NoError(t, err)
+
+	direction := component.Direction()
+	assert.InDelta(t, 1.0, VectorNorm(direction), 1e-6)
+	assert.InDelta(t, 1/math.Sqrt2, math.Abs(float64(direction[0])), 0.02)
+	assert.InDelta(t, 1/math.Sqrt2, math.Abs(float64(direction[1])), 0.02)
+
+	// The projection onto the component is removed
+	removed := component.Remove([]float32{1, 1, 1})
+	assert.InDelta(t, 0, DotProduct(removed, direction), 1e-6)
+
+	// Other dimensions and a nil component leave vectors unchanged
+	assert.Equal(t, []float32{1, 2}, component.Remove([]float32{1, 2}))
+	var none *CommonComponent
+	assert.Equal(t, []float32{1, 2, 3}, none.Remove([]float32{1, 2, 3}))
+
+	_, err = FitCommonComponent(nil)
+	assert.ErrorIs(t, err, ErrEmptyInput)
+	_, err = FitCommonComponent([][]float32{{1, 2}, {1}})
+	assert.ErrorIs(t, err, ErrDimensionMismatch)
+}
+
+func TestSemanticMatcher_SIF(t *testing.T) {
+	matcher := NewSemanticMatcher(NewTextProcessor(), loadSIFTestModel(t), NewSimilarityCalculator())
+
+	// SIF down-weights the frequent word shared by both texts
+	plain := matcher.ComputeSimilarity("common market", "common weather")
+	weighted := matcher.ComputeSimilarityWithOptions("common market", "common weather",
+		WithTokenWeighting(SIFWeighting{A: 0.1}))
+	assert.Less(t, weighted, plain)
+
+	require.NoError(t, matcher.FitCommonComponent(
+		[]string{"common market", "common stock", "common weather", "common rain"},
+		WithTokenWeighting(SIFWeighting{A: 0.1})))
+
+	// The component is removed only with the weighting it was fitted with
+	sifVector, ok := matcher.VectorizeTextWithOptions("common market", WithTokenWeighting(SIFWeighting{A: 0.1}))
+	require.True(t, ok)
+	plainVector, ok := matcher.VectorizeText("common market")
+	require.True(t, ok)
+
+	direction := matcher.(*semanticMatcher).commonComponent.Direction()
+	assert.InDelta(t, 0, DotProduct(sifVector, direction), 1e-6)
+	assert.NotZero(t, DotProduct(plainVector, direction))
+
+	assert.ErrorIs(t, matcher.FitCommonComponent([]string{"", "xyzzy"}), ErrEmptyInput)
+}
+
+func TestNewSemanticMatcherFromConfig_SIF(t *testing.T) {
+	dir := t.TempDir()
+	vectorFile := filepath.Join(dir, "test.vec")
+	sampleFile := filepath.Join(dir, "sample.txt")
+	require.NoError(t, os.WriteFile(vectorFile, []byte(sifTestVectors), 0o644))
+	require.NoError(t, os.WriteFile(sampleFile, []byte("common market\ncommon weather\n\ncommon rain\n"), 0o644))
+
+	config := DefaultConfig()
+	config.VectorFilePaths = []string{vectorFile}
+	config.Pooling = PoolingNormalizedSum
+	config.TokenWeighting = WeightingSIF
+	config.SIFParameter = 0.1
+	config.CommonComponentSamplePath = sampleFile
+
+	matcher, err := NewSemanticMatcherFromConfig(config, DiscardLogger{})
+	require.NoError(t, err)
+
+	sm := matcher.(*semanticMatcher)
+	assert.Equal(t, PoolingNormalizedSum, sm.pooling.Name())
+	assert.Equal(t, SIFWeighting{A: 0.1}, sm.weighting)
+	require.NotNil(t, sm.commonComponent)
+
+	vector, ok := matcher.VectorizeText("stock market")
+	require.True(t, ok)
+	assert.InDelta(t, 0, DotProduct(vector, sm.commonComponent.Direction()), 1e-6)
+
+	config.TokenWeighting = "tfidf"
+	_, err = NewSemanticMatcherFromConfig(config, DiscardLogger{})
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+}
@@ -1,15 +1,68 @@
 package semanticmatcher
 
-import "math"
+const (
+	// matrixBlockSize is the number of rows and columns per block of SimilarityMatrix
+	matrixBlockSize = 64
+
+	// batchBlockSize is the number of candidates per parallel block of BatchSimilarity
+	batchBlockSize = 256
+)
 
 // similarityCalculator implements the SimilarityCalculator interface
-type similarityCalculator struct{}
+type similarityCalculator struct {
+	metric SimilarityMetric // Metric of Similarity and BatchSimilarity; nil means cosine
+	pool   workerPool       // Parallelism of BatchSimilarity and SimilarityMatrix
+}
 
-// NewSimilarityCalculator creates a new SimilarityCalculator instance
+// NewSimilarityCalculator creates a new SimilarityCalculator instance using cosine similarity
 func NewSimilarityCalculator() SimilarityCalculator {
 	return &similarityCalculator{}
 }
 
+// NewSimilarityCalculatorWithMetric creates a SimilarityCalculator whose Similarity and
+// BatchSimilarity use metric. Token and word vector metrics are applied by the matcher to token
+// sets and word vectors; their calculator scores vectors by cosine. A nil metric means cosine.
+func NewSimilarityCalculatorWithMetric(metric SimilarityMetric) SimilarityCalculator {
+	return &similarityCalculator{metric: metric}
+}
+
+// SetParallelism sets the maximum goroutines of BatchSimilarity and SimilarityMatrix per call
+// (0 uses GOMAXPROCS, 1 runs serially) and the number of candidates or matrix cells below which
+// they run serially (0 uses DefaultParallelThreshold)
+// Results are identical to serial execution. Not safe to call concurrently with other methods.
+func (c *similarityCalculator) SetParallelism(workers, threshold int) {
+	c.pool = newWorkerPool(workers, threshold)
+}
+
+// Metric returns the similarity metric of the calculator
+func (c *similarityCalculator) Metric() SimilarityMetric {
+	if c.metric == nil {
+		return CosineMetric{}
+	}
+	return c.metric
+}
+
+// vectorMetric returns the metric used for vectors, or nil for cosine
+func (c *similarityCalculator) vectorMetric() VectorSimilarityMetric {
+	metric, ok := c.metric.(VectorSimilarityMetric)
+	if !ok {
+		return nil
+	}
+	if _, cosine := metric.(CosineMetric); cosine {
+		return nil
+	}
+	return metric
+}
+
+// Similarity computes the similarity of two vectors with the calculator's metric
+// Returns 0.0 for invalid inputs
+func (c *similarityCalculator) Similarity(v1, v2 []float32) float64 {
+	if metric := c.vectorMetric(); metric != nil {
+		return metric.Similarity(v1, v2)
+	}
+	return c.CosineSimilarity(v1, v2)
+}
+
 // isZeroVector checks if a vector is a zero vector (all elements are zero)
 func isZeroVector(v []float32) bool {
 	for _, val := range v {
@@ -33,42 +86,21 @@ func isValidVector(v []float32) bool {
 // Returns 0.0 for invalid inputs (empty, nil, mismatched dimensions, or zero vectors)
 // Formula: cos(θ) = (v1 · v2) / (||v1|| * ||v2||)
 func (*similarityCalculator) CosineSimilarity(v1, v2 []float32) float64 {
-	// Check Validate input vectors and dimension mismatch
-	if !isValidVector(v1) || !isValidVector(v2) || len(v1) != len(v2) {
+	if len(v1) == 0 || len(v1) != len(v2) {
 		return 0.0
 	}
 
-	var dotProduct, norm1, norm2 float64
-
 	// Compute dot product and norms in a single pass for efficiency
-	for i := range v1 {
-		dotProduct += float64(v1[i]) * float64(v2[i])
-		norm1 += float64(v1[i]) * float64(v1[i])
-		norm2 += float64(v2[i]) * float64(v2[i])
-	}
-
-	// Handle zero vectors - return 0.0 for undefined similarity
-	if norm1 == 0.0 || norm2 == 0.0 {
-		return 0.0
-	}
-
-	// Compute cosine similarity
-	similarity := dotProduct / (math.Sqrt(norm1) * math.Sqrt(norm2))
-
-	// Clamp result to [-1, 1] to handle floating point precision issues
-	if similarity > 1.0 {
-		similarity = 1.0
-	} else if similarity < -1.0 {
-		similarity = -1.0
-	}
-
-	return similarity
+	dot, norm1, norm2 := dotAndNormsFloat32(v1, v2)
+	return cosineFromParts(float64(dot), float64(norm1), float64(norm2))
 }
 
 // BatchSimilarity computes similarities between one query vector and multiple candidate vectors
-// This is optimized for computing multiple similarities at once
+// with the calculator's metric
+// This is optimized for computing multiple similarities at once, in parallel for many
+// candidates; for candidates scored repeatedly, CandidateSet also caches their norms
 // Returns empty slice for invalid query, and 0.0 for invalid candidates
-func (*similarityCalculator) BatchSimilarity(query []float32, candidates [][]float32) []float64 {
+func (c *similarityCalculator) BatchSimilarity(query []float32, candidates [][]float32) []float64 {
 	// Validate query vector
 	if len(query) == 0 {
 		return []float64{}
@@ -81,53 +113,81 @@ func (*similarityCalculator) BatchSimilarity(query []float32, candidates [][]flo
 
 	results := make([]float64, len(candidates))
 
-	// Pre-compute query norm once for all comparisons
-	var queryNorm float64
-	for i := range query {
-		queryNorm += float64(query[i]) * float64(query[i])
+	if metric := c.vectorMetric(); metric != nil {
+		c.pool.run(len(candidates), batchBlockSize, func(start, end int) {
+			for idx := start; idx < end; idx++ {
+				results[idx] = metric.Similarity(query, candidates[idx])
+			}
+		})
+		return results
 	}
 
+	// Pre-compute query norm once for all comparisons
+	queryNorm := float64(squaredNormFloat32(query))
+
 	// Handle zero query vector - return all zeros
 	if queryNorm == 0.0 {
 		return results // All zeros
 	}
 
-	querySqrtNorm := math.Sqrt(queryNorm)
-
-	// Compute similarity for each candidate
-	for idx, candidate := range candidates {
-		// Validate candidate vector
-		if len(candidate) == 0 || len(candidate) != len(query) {
-			results[idx] = 0.0
-			continue
-		}
+	// Compute similarity for each candidate, in parallel blocks for many candidates
+	c.pool.run(len(candidates), batchBlockSize, func(start, end int) {
+		for idx := start; idx < end; idx++ {
+			candidate := candidates[idx]
 
-		var dotProduct, candidateNorm float64
+			// Validate candidate vector
+			if len(candidate) != len(query) {
+				continue
+			}
 
-		// Compute dot product and candidate norm
-		for i := range query {
-			dotProduct += float64(query[i]) * float64(candidate[i])
-			candidateNorm += float64(candidate[i]) * float64(candidate[i])
+			dot, _, candidateNorm := dotAndNormsFloat32(query, candidate)
+			results[idx] = cosineFromParts(float64(dot), queryNorm, float64(candidateNorm))
 		}
+	})
 
-		// Handle zero candidate vector
-		if candidateNorm == 0.0 {
-			results[idx] = 0.0
-			continue
-		}
+	return results
+}
 
-		// Compute cosine similarity
-		similarity := dotProduct / (querySqrtNorm * math.Sqrt(candidateNorm))
+// SimilarityMatrix computes the similarity of every row vector to every column vector with the
+// calculator's metric, in parallel blocks of rows for large matrices
+// Norms are computed once per vector for cosine. Returns a len(rows) × len(columns) matrix
+// backed by one array, with 0.0 for invalid vectors.
+func (c *similarityCalculator) SimilarityMatrix(rows, columns [][]float32) [][]float64 {
+	matrix := newDenseMatrix(len(rows), len(columns))
+	if len(rows) == 0 || len(columns) == 0 {
+		return matrix
+	}
 
-		// Clamp result to [-1, 1] to handle floating point precision issues
-		if similarity > 1.0 {
-			similarity = 1.0
-		} else if similarity < -1.0 {
-			similarity = -1.0
+	if metric := c.vectorMetric(); metric != nil {
+		c.pool.runWork(len(rows)*len(columns), len(rows), matrixBlockSize, func(start, end int) {
+			for i := start; i < end; i++ {
+				for j, column := range columns {
+					matrix[i][j] = metric.Similarity(rows[i], column)
+				}
+			}
+		})
+		return matrix
+	}
+
+	rowSet, columnSet := NewCandidateSet(rows), NewCandidateSet(columns)
+	c.pool.runWork(len(rows)*len(columns), len(rows), matrixBlockSize, func(start, end int) {
+		// Columns in tiles keep a tile of column vectors in cache across the block's rows
+		for tile := 0; tile < len(columns); tile += matrixBlockSize {
+			tileEnd := min(tile+matrixBlockSize, len(columns))
+			for i := start; i < end; i++ {
+				columnSet.similaritiesInto(rows[i], float64(rowSet.norms[i]), tile, tileEnd, matrix[i])
+			}
 		}
+	})
+	return matrix
+}
 
-		results[idx] = similarity
+// newDenseMatrix returns a zero rows × columns matrix whose rows share one backing array
+func newDenseMatrix(rows, columns int) [][]float64 {
+	scores := make([]float64, rows*columns)
+	matrix := make([][]float64, rows)
+	for i := range matrix {
+		matrix[i] = scores[i*columns : (i+1)*columns : (i+1)*columns]
 	}
-
-	return results
+	return matrix
 }
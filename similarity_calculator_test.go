@@ -208,6 +208,45 @@ func TestBatchSimilarity(t *testing.T) {
 	}
 }
 
+func TestSimilarityMatrix(t *testing.T) {
+	rows := make([][]float32, 150)
+	columns := make([][]float32, 70)
+	for i := range rows {
+		rows[i] = []float32{float32(i), 1, float32(i % 7)}
+	}
+	for j := range columns {
+		columns[j] = []float32{1, float32(j), -float32(j % 5)}
+	}
+	rows[3] = []float32{0, 0, 0} // Zero vector
+	columns[5] = nil             // Missing vector
+	columns[6] = []float32{1, 2} // Mismatched dimension
+
+	for _, calc := range []SimilarityCalculator{
+		NewSimilarityCalculator(),
+		NewSimilarityCalculatorWithMetric(EuclideanMetric{}),
+	} {
+		matrix := calc.SimilarityMatrix(rows, columns)
+		if len(matrix) != len(rows) {
+			t.Fatalf("%s: expected %d rows, got %d", calc.Metric().Name(), len(rows), len(matrix))
+		}
+		for i, row := range rows {
+			if len(matrix[i]) != len(columns) {
+				t.Fatalf("%s: expected %d columns in row %d, got %d", calc.Metric().Name(), len(columns), i, len(matrix[i]))
+			}
+			for j, column := range columns {
+				expected := calc.Similarity(row, column)
+				if math.Abs(matrix[i][j]-expected) > 1e-6 {
+					t.Errorf("%s: score (%d, %d) expected %f, got %f", calc.Metric().Name(), i, j, expected, matrix[i][j])
+				}
+			}
+		}
+	}
+
+	if matrix := NewSimilarityCalculator().SimilarityMatrix(rows, nil); len(matrix) != len(rows) || len(matrix[0]) != 0 {
+		t.Errorf("Expected empty rows without columns, got %v", matrix)
+	}
+}
+
 func BenchmarkCosineSimilarity(b *testing.B) {
 	calc := NewSimilarityCalculator()
 	v1 := make([]float32, 300)
@@ -245,3 +284,117 @@ func BenchmarkBatchSimilarity(b *testing.B) {
 		calc.BatchSimilarity(query, candidates)
 	}
 }
+
+func BenchmarkCosineSimilarityReference(b *testing.B) {
+	v1 := make([]float32, 300)
+	v2 := make([]float32, 300)
+
+	for i := range v1 {
+		v1[i] = float32(i) * 0.01
+		v2[i] = float32(i) * 0.02
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		referenceCosine(v1, v2)
+	}
+}
+
+func BenchmarkDotKernels(b *testing.B) {
+	v1 := make([]float32, 300)
+	v2 := make([]float32, 300)
+
+	for i := range v1 {
+		v1[i] = float32(i) * 0.01
+		v2[i] = float32(i) * 0.02
+	}
+
+	b.Run("generic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			dotGeneric(v1, v2)
+		}
+	})
+	b.Run("dispatch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			dotFloat32(v1, v2)
+		}
+	})
+}
+
+func BenchmarkSimilarityMatrix(b *testing.B) {
+	rows := make([][]float32, 200)
+	columns := make([][]float32, 1000)
+	for i := range rows {
+		rows[i] = make([]float32, 300)
+		for j := range rows[i] {
+			rows[i][j] = float32((i+j)%17) * 0.01
+		}
+	}
+	for i := range columns {
+		columns[i] = make([]float32, 300)
+		for j := range columns[i] {
+			columns[i][j] = float32((i*j)%13) * 0.01
+		}
+	}
+	calc := NewSimilarityCalculator()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calc.SimilarityMatrix(rows, columns)
+	}
+}
+
+func BenchmarkCandidateSetSimilarities(b *testing.B) {
+	query := make([]float32, 300)
+	candidates := make([][]float32, 100)
+
+	for i := range query {
+		query[i] = float32(i) * 0.01
+	}
+
+	for i := range candidates {
+		candidates[i] = make([]float32, 300)
+		for j := range candidates[i] {
+			candidates[i][j] = float32(j) * 0.01 * float32(i+1)
+		}
+	}
+	set := NewCandidateSet(candidates)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Similarities(query)
+	}
+}
+
+func TestSimilarityCalculatorWithMetric(t *testing.T) {
+	v1 := []float32{1, 0}
+	v2 := []float32{0, 1}
+
+	calculator := NewSimilarityCalculator()
+	if calculator.Metric().Name() != MetricCosine {
+		t.Errorf("Expected cosine metric, got %s", calculator.Metric().Name())
+	}
+
+	manhattan := NewSimilarityCalculatorWithMetric(ManhattanMetric{})
+	if got := manhattan.Similarity(v1, v2); math.Abs(got-1.0/3.0) > 1e-9 {
+		t.Errorf("Expected Manhattan similarity 1/3, got %f", got)
+	}
+	scores := manhattan.BatchSimilarity(v1, [][]float32{v1, v2, {1}})
+	expected := []float64{1, 1.0 / 3.0, 0}
+	for i := range expected {
+		if math.Abs(scores[i]-expected[i]) > 1e-9 {
+			t.Errorf("Expected score %f at %d, got %f", expected[i], i, scores[i])
+		}
+	}
+
+	// Cosine similarity stays available on every calculator
+	if got := manhattan.CosineSimilarity(v1, v2); got != 0 {
+		t.Errorf("Expected cosine similarity 0, got %f", got)
+	}
+
+	// Token metrics score vectors by cosine
+	jaccard := NewSimilarityCalculatorWithMetric(JaccardMetric{})
+	if got := jaccard.Similarity(v1, v1); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Expected cosine similarity 1 for a token metric, got %f", got)
+	}
+}
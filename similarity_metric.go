@@ -0,0 +1,246 @@
+package semanticmatcher
+
+import (
+	"fmt"
+	"math"
+)
+
+// Similarity metric names accepted by NewSimilarityMetric and Config.SimilarityMetric
+const (
+	MetricCosine    = "cosine"
+	MetricDot       = "dot"
+	MetricEuclidean = "euclidean"
+	MetricManhattan = "manhattan"
+	MetricAngular   = "angular"
+	MetricJaccard   = "jaccard"
+	MetricOverlap   = "overlap"
+	MetricWMD       = "wmd"
+	MetricRWMD      = "rwmd"
+)
+
+// SimilarityMetric scores how similar two texts are; higher scores mean more similar
+// Vector metrics implement VectorSimilarityMetric, token metrics TokenSimilarityMetric and
+// word alignment metrics WordVectorSimilarityMetric.
+type SimilarityMetric interface {
+	// Name returns the metric name used in configuration
+	Name() string
+
+	// Range returns the lowest and highest possible score; infinite for unbounded metrics
+	Range() (lower, upper float64)
+}
+
+// VectorSimilarityMetric scores two text vectors
+type VectorSimilarityMetric interface {
+	SimilarityMetric
+
+	// Similarity scores two vectors of the same dimension
+	// Returns 0.0 for invalid inputs (empty, nil or mismatched dimensions)
+	Similarity(v1, v2 []float32) float64
+}
+
+// TokenSimilarityMetric scores the token sets of two texts, without vectors
+type TokenSimilarityMetric interface {
+	SimilarityMetric
+
+	// TokenSimilarity scores two token lists, ignoring duplicates and order
+	TokenSimilarity(tokens1, tokens2 []string) float64
+}
+
+// WordVectorSimilarityMetric scores two texts by aligning the vectors of their words instead of
+// comparing pooled text vectors
+type WordVectorSimilarityMetric interface {
+	SimilarityMetric
+
+	// WordVectorSimilarity scores two texts given one vector per in-vocabulary token
+	// Returns 0.0 if either text has no vectors
+	WordVectorSimilarity(words1, words2 [][]float32) float64
+}
+
+// CosineMetric is the cosine of the angle between two vectors, in [-1, 1]
+type CosineMetric struct{}
+
+// Name returns the metric name
+func (CosineMetric) Name() string { return MetricCosine }
+
+// Range returns [-1, 1]
+func (CosineMetric) Range() (float64, float64) { return -1, 1 }
+
+// Similarity returns the cosine similarity, 0.0 for zero vectors
+func (CosineMetric) Similarity(v1, v2 []float32) float64 {
+	return (&similarityCalculator{}).CosineSimilarity(v1, v2)
+}
+
+// DotProductMetric is the dot product of two vectors, unbounded
+// It equals cosine for unit vectors and favors longer vectors otherwise.
+type DotProductMetric struct{}
+
+// Name returns the metric name
+func (DotProductMetric) Name() string { return MetricDot }
+
+// Range returns (-∞, ∞)
+func (DotProductMetric) Range() (float64, float64) { return math.Inf(-1), math.Inf(1) }
+
+// Similarity returns the dot product
+func (DotProductMetric) Similarity(v1, v2 []float32) float64 {
+	return DotProduct(v1, v2)
+}
+
+// EuclideanMetric converts the Euclidean distance d to the similarity 1 / (1 + d), in (0, 1]
+type EuclideanMetric struct{}
+
+// Name returns the metric name
+func (EuclideanMetric) Name() string { return MetricEuclidean }
+
+// Range returns (0, 1]
+func (EuclideanMetric) Range() (float64, float64) { return 0, 1 }
+
+// Similarity returns 1 / (1 + ||v1 - v2||)
+func (EuclideanMetric) Similarity(v1, v2 []float32) float64 {
+	if len(v1) == 0 || len(v1) != len(v2) {
+		return 0.0
+	}
+
+	var sum float64
+	for i := range v1 {
+		diff := float64(v1[i]) - float64(v2[i])
+		sum += diff * diff
+	}
+	return 1 / (1 + math.Sqrt(sum))
+}
+
+// ManhattanMetric converts the Manhattan (L1) distance d to the similarity 1 / (1 + d), in (0, 1]
+type ManhattanMetric struct{}
+
+// Name returns the metric name
+func (ManhattanMetric) Name() string { return MetricManhattan }
+
+// Range returns (0, 1]
+func (ManhattanMetric) Range() (float64, float64) { return 0, 1 }
+
+// Similarity returns 1 / (1 + Σ|v1_i - v2_i|)
+func (ManhattanMetric) Similarity(v1, v2 []float32) float64 {
+	if len(v1) == 0 || len(v1) != len(v2) {
+		return 0.0
+	}
+
+	var sum float64
+	for i := range v1 {
+		sum += math.Abs(float64(v1[i]) - float64(v2[i]))
+	}
+	return 1 / (1 + sum)
+}
+
+// AngularMetric is 1 - θ/π for the angle θ between two vectors, in [0, 1]
+// Unlike cosine, equal differences in angle give equal differences in score.
+type AngularMetric struct{}
+
+// Name returns the metric name
+func (AngularMetric) Name() string { return MetricAngular }
+
+// Range returns [0, 1]
+func (AngularMetric) Range() (float64, float64) { return 0, 1 }
+
+// Similarity returns 1 - arccos(cos(v1, v2)) / π, 0.0 for zero vectors
+func (AngularMetric) Similarity(v1, v2 []float32) float64 {
+	if !isValidVector(v1) || !isValidVector(v2) || len(v1) != len(v2) {
+		return 0.0
+	}
+	return 1 - math.Acos(CosineMetric{}.Similarity(v1, v2))/math.Pi
+}
+
+// JaccardMetric is the Jaccard index |A ∩ B| / |A ∪ B| of two token sets, in [0, 1]
+type JaccardMetric struct{}
+
+// Name returns the metric name
+func (JaccardMetric) Name() string { return MetricJaccard }
+
+// Range returns [0, 1]
+func (JaccardMetric) Range() (float64, float64) { return 0, 1 }
+
+// TokenSimilarity returns the Jaccard index, 0.0 if both lists are empty
+func (JaccardMetric) TokenSimilarity(tokens1, tokens2 []string) float64 {
+	set1, set2 := tokenSet(tokens1), tokenSet(tokens2)
+	shared := sharedTokens(set1, set2)
+	union := len(set1) + len(set2) - shared
+	if union == 0 {
+		return 0.0
+	}
+	return float64(shared) / float64(union)
+}
+
+// OverlapMetric is the overlap coefficient |A ∩ B| / min(|A|, |B|) of two token sets, in [0, 1]
+// A short keyword whose tokens all occur in a long paragraph scores 1.
+type OverlapMetric struct{}
+
+// Name returns the metric name
+func (OverlapMetric) Name() string { return MetricOverlap }
+
+// Range returns [0, 1]
+func (OverlapMetric) Range() (float64, float64) { return 0, 1 }
+
+// TokenSimilarity returns the overlap coefficient, 0.0 if either list is empty
+func (OverlapMetric) TokenSimilarity(tokens1, tokens2 []string) float64 {
+	set1, set2 := tokenSet(tokens1), tokenSet(tokens2)
+	smaller := min(len(set1), len(set2))
+	if smaller == 0 {
+		return 0.0
+	}
+	return float64(sharedTokens(set1, set2)) / float64(smaller)
+}
+
+// tokenSet returns the distinct tokens
+func tokenSet(tokens []string) map[string]Empty {
+	set := make(map[string]Empty, len(tokens))
+	for _, token := range tokens {
+		set[token] = Empty{}
+	}
+	return set
+}
+
+// sharedTokens counts the tokens in both sets
+func sharedTokens(set1, set2 map[string]Empty) int {
+	if len(set1) > len(set2) {
+		set1, set2 = set2, set1
+	}
+	shared := 0
+	for token := range set1 {
+		if _, exists := set2[token]; exists {
+			shared++
+		}
+	}
+	return shared
+}
+
+// NewSimilarityMetric returns the metric for a Config.SimilarityMetric name
+// An empty name returns CosineMetric
+func NewSimilarityMetric(name string) (SimilarityMetric, error) {
+	switch name {
+	case "", MetricCosine:
+		return CosineMetric{}, nil
+	case MetricDot:
+		return DotProductMetric{}, nil
+	case MetricEuclidean:
+		return EuclideanMetric{}, nil
+	case MetricManhattan:
+		return ManhattanMetric{}, nil
+	case MetricAngular:
+		return AngularMetric{}, nil
+	case MetricJaccard:
+		return JaccardMetric{}, nil
+	case MetricOverlap:
+		return OverlapMetric{}, nil
+	case MetricWMD:
+		return WMDMetric{}, nil
+	case MetricRWMD:
+		return RelaxedWMDMetric{}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown similarity metric %q", ErrInvalidConfiguration, name)
+	}
+}
+
+// clampScore limits a score to [max(lower, 0), upper] of the metric, as returned by
+// ComputeSimilarity, where negative similarity counts as no similarity
+func clampScore(metric SimilarityMetric, score float64) float64 {
+	lower, upper := metric.Range()
+	return math.Min(math.Max(score, math.Max(lower, 0)), upper)
+}
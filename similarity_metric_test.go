@@ -0,0 +1,97 @@
+package semanticmatcher
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorSimilarityMetrics(t *testing.T) {
+	a := []float32{1, 0}
+	b := []float32{0, 2}
+	opposite := []float32{-3, 0}
+
+	tests := []struct {
+		metric   VectorSimilarityMetric
+		same     float64
+		ab       float64
+		opposite float64
+	}{
+		{CosineMetric{}, 1, 0, -1},
+		{DotProductMetric{}, 1, 0, -3},
+		{EuclideanMetric{}, 1, 1 / (1 + math.Sqrt(5)), 0.2},
+		{ManhattanMetric{}, 1, 0.25, 0.2},
+		{AngularMetric{}, 1, 0.5, 0},
+	}
+
+	for _, tt := range tests {
+		assert.InDelta(t, tt.same, tt.metric.Similarity(a, a), 1e-9, tt.metric.Name())
+		assert.InDelta(t, tt.ab, tt.metric.Similarity(a, b), 1e-9, tt.metric.Name())
+		assert.InDelta(t, tt.opposite, tt.metric.Similarity(a, opposite), 1e-9, tt.metric.Name())
+
+		// Invalid inputs score 0
+		assert.Zero(t, tt.metric.Similarity(a, []float32{1, 2, 3}), tt.metric.Name())
+		assert.Zero(t, tt.metric.Similarity(nil, nil), tt.metric.Name())
+
+		lower, upper := tt.metric.Range()
+		assert.LessOrEqual(t, lower, tt.metric.Similarity(a, opposite), tt.metric.Name())
+		assert.GreaterOrEqual(t, upper, tt.metric.Similarity(a, a), tt.metric.Name())
+	}
+}
+
+func TestTokenSimilarityMetrics(t *testing.T) {
+	paragraph := []string{"退款", "申请", "流程", "退款"}
+	keyword := []string{"退款", "流程"}
+
+	assert.InDelta(t, 2.0/3.0, JaccardMetric{}.TokenSimilarity(paragraph, keyword), 1e-9)
+	assert.InDelta(t, 1.0, OverlapMetric{}.TokenSimilarity(paragraph, keyword), 1e-9)
+	assert.InDelta(t, 0.0, JaccardMetric{}.TokenSimilarity(paragraph, []string{"天气"}), 1e-9)
+	assert.Zero(t, JaccardMetric{}.TokenSimilarity(nil, nil))
+	assert.Zero(t, OverlapMetric{}.TokenSimilarity(paragraph, nil))
+}
+
+func TestNewSimilarityMetric(t *testing.T) {
+	for _, name := range []string{
+		MetricCosine, MetricDot, MetricEuclidean, MetricManhattan, MetricAngular, MetricJaccard, MetricOverlap,
+		MetricWMD, MetricRWMD,
+	} {
+		metric, err := NewSimilarityMetric(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, metric.Name())
+	}
+
+	metric, err := NewSimilarityMetric("")
+	require.NoError(t, err)
+	assert.Equal(t, MetricCosine, metric.Name())
+
+	_, err = NewSimilarityMetric("hamming")
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+}
+
+func TestClampScore(t *testing.T) {
+	assert.Zero(t, clampScore(CosineMetric{}, -0.4))
+	assert.InDelta(t, 0.4, clampScore(CosineMetric{}, 0.4), 1e-9)
+	assert.InDelta(t, 12.0, clampScore(DotProductMetric{}, 12), 1e-9)
+	assert.Zero(t, clampScore(DotProductMetric{}, -12))
+}
+
+func TestSemanticMatcher_SimilarityMetric(t *testing.T) {
+	model := createTestVectorModel()
+
+	euclidean := NewSemanticMatcher(NewTextProcessor(), model, NewSimilarityCalculatorWithMetric(EuclideanMetric{}))
+	score := euclidean.ComputeSimilarity("测试", "文本")
+	assert.InDelta(t, 1/(1+math.Sqrt(0.12)), score, 1e-6)
+
+	matches := euclidean.FindTopKeywords("测试", []string{"文本", "段落"}, 0)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "段落", matches[0].Keyword)
+
+	jaccard := NewSemanticMatcher(NewTextProcessor(), model, NewSimilarityCalculatorWithMetric(JaccardMetric{}))
+	assert.InDelta(t, 1.0, jaccard.ComputeSimilarity("测试", "测试"), 1e-9)
+	matches = jaccard.FindTopKeywords("测试 段落", []string{"段落", "未知词"}, 1)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "段落", matches[0].Keyword)
+	assert.InDelta(t, 0.5, matches[0].Score, 1e-9)
+}
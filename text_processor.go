@@ -89,6 +89,8 @@ type textProcessor struct {
 	englishStops map[string]Empty
 
 	englishTokenizer *regexp.Regexp
+	normalizer       *Normalizer       // Unicode normalization applied before tokenization
+	chineseConverter *ChineseConverter // Traditional/Simplified conversion applied after normalization
 	mtx              sync.RWMutex
 }
 
@@ -251,6 +253,22 @@ func NewTextProcessorWithDictPathsAndStopWords(
 	return processor, nil
 }
 
+// SetNormalizer sets the Unicode normalization applied to text before tokenization
+func (tp *textProcessor) SetNormalizer(normalizer *Normalizer) {
+	tp.mtx.Lock()
+	defer tp.mtx.Unlock()
+
+	tp.normalizer = normalizer
+}
+
+// SetChineseConverter sets the Traditional/Simplified conversion applied after normalization
+func (tp *textProcessor) SetChineseConverter(converter *ChineseConverter) {
+	tp.mtx.Lock()
+	defer tp.mtx.Unlock()
+
+	tp.chineseConverter = converter
+}
+
 // Preprocess segments Chinese text and filters stop words
 func (tp *textProcessor) Preprocess(text string) []string {
 	tp.mtx.RLock()
@@ -260,6 +278,8 @@ func (tp *textProcessor) Preprocess(text string) []string {
 		return []string{}
 	}
 
+	text = tp.chineseConverter.Convert(tp.normalizer.Normalize(text))
+
 	// Detect if text contains Chinese characters
 	hasChinese := tp.containsChinese(text)
 	hasEnglish := tp.containsEnglish(text)
@@ -300,6 +320,8 @@ func (tp *textProcessor) preprocessInternal(text string) []string {
 		return []string{}
 	}
 
+	text = tp.chineseConverter.Convert(tp.normalizer.Normalize(text))
+
 	// Detect if text contains Chinese characters
 	hasChinese := tp.containsChinese(text)
 	hasEnglish := tp.containsEnglish(text)
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	sm "github.com/kydenul/semantic-matcher"
+)
+
+// stdLogger adapts the standard logger to sm.Logger
+type stdLogger struct{}
+
+func (stdLogger) Debug(...any)                        {}
+func (stdLogger) Info(args ...any)                    { log.Print(args...) }
+func (stdLogger) Warn(args ...any)                    { log.Print(args...) }
+func (stdLogger) Error(args ...any)                   { log.Print(args...) }
+func (stdLogger) Debugf(string, ...any)               {}
+func (stdLogger) Infof(template string, args ...any)  { log.Printf(template, args...) }
+func (stdLogger) Warnf(template string, args ...any)  { log.Printf(template, args...) }
+func (stdLogger) Errorf(template string, args ...any) { log.Printf(template, args...) }
+
+func main() {
+	sourcePath := flag.String("source", "", "Source .vec file to map, e.g. cc.zh.300.vec")
+	targetPath := flag.String("target", "", "Target .vec file, e.g. cc.en.300.vec")
+	dictionaryPath := flag.String("dictionary", "", "Seed dictionary of \"source translation1 ...\" lines")
+	testPath := flag.String("test", "", "Held-out dictionary to report precision@1 on")
+	output := flag.String("output", "", "Output mapping file, for vector_mappings")
+	refine := flag.Int("refine", 0, "Number of refinement iterations with mutual nearest neighbors")
+	refineWords := flag.Int("refine-words", sm.DefaultMappingRefineWords, "Most frequent words searched when refining")
+	searchWords := flag.Int("search-words", sm.DefaultMappingSearchWords, "Most frequent target words searched by -test")
+	flag.Parse()
+
+	if *sourcePath == "" || *targetPath == "" || *dictionaryPath == "" || *output == "" {
+		log.Fatal("Usage: align_vec -source <zh.vec> -target <en.vec> -dictionary <file> -output <file> " +
+			"[-test <file>] [-refine 0] [-refine-words 5000] [-search-words 200000]")
+	}
+
+	dictionary, err := sm.LoadBilingualDictionary(*dictionaryPath)
+	if err != nil {
+		log.Fatalf("Failed to load dictionary: %v", err)
+	}
+
+	loader := sm.NewEmbeddingLoader(stdLogger{})
+	source, err := loader.LoadFromFile(*sourcePath)
+	if err != nil {
+		log.Fatalf("Failed to load source vectors: %v", err)
+	}
+	target, err := loader.LoadFromFile(*targetPath)
+	if err != nil {
+		log.Fatalf("Failed to load target vectors: %v", err)
+	}
+
+	mapping, err := sm.LearnOrthogonalMapping(source, target, dictionary, sm.MappingOptions{
+		RefineIterations: *refine,
+		RefineWords:      *refineWords,
+	})
+	if err != nil {
+		log.Fatalf("Failed to learn mapping: %v", err)
+	}
+
+	if err := mapping.SaveToFile(*output); err != nil {
+		log.Fatalf("Failed to write %s: %v", *output, err)
+	}
+	fmt.Printf("%s: %d dimensions\n", *output, mapping.Dimension())
+
+	if *testPath == "" {
+		return
+	}
+	test, err := sm.LoadBilingualDictionary(*testPath)
+	if err != nil {
+		log.Fatalf("Failed to load test dictionary: %v", err)
+	}
+	precision, count, err := mapping.PrecisionAtOne(source, target, test, *searchWords)
+	if err != nil {
+		log.Fatalf("Failed to evaluate mapping: %v", err)
+	}
+	fmt.Printf("precision@1: %.4f on %d words\n", precision, count)
+}
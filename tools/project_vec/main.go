@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sm "github.com/kydenul/semantic-matcher"
+)
+
+// stdLogger adapts the standard logger to sm.Logger
+type stdLogger struct{}
+
+func (stdLogger) Debug(...any)                        {}
+func (stdLogger) Info(args ...any)                    { log.Print(args...) }
+func (stdLogger) Warn(args ...any)                    { log.Print(args...) }
+func (stdLogger) Error(args ...any)                   { log.Print(args...) }
+func (stdLogger) Debugf(string, ...any)               {}
+func (stdLogger) Infof(template string, args ...any)  { log.Printf(template, args...) }
+func (stdLogger) Warnf(template string, args ...any)  { log.Printf(template, args...) }
+func (stdLogger) Errorf(template string, args ...any) { log.Printf(template, args...) }
+
+func main() {
+	inputFiles := flag.String("input", "", "Comma-separated input .vec files")
+	outputDir := flag.String("output-dir", "", "Directory for the reduced .vec files (same file names)")
+	projectionPath := flag.String("projection", "", "Projection file; loaded if it exists, otherwise fitted and saved")
+	method := flag.String("method", sm.ProjectionPCA, "Projection method: pca or random")
+	dimension := flag.Int("dim", 100, "Output dimension")
+	sampleSize := flag.Int("sample", sm.DefaultPCASampleSize, "Number of vectors PCA is fitted on")
+	seed := flag.Int64("seed", 1, "Seed of the random projection")
+	flag.Parse()
+
+	if *inputFiles == "" || *outputDir == "" || *projectionPath == "" {
+		log.Fatal("Usage: project_vec -input <a.vec,b.vec> -output-dir <dir> -projection <file> " +
+			"[-method pca|random] [-dim 100] [-sample 20000] [-seed 1]")
+	}
+
+	paths := strings.Split(*inputFiles, ",")
+	config := sm.ProjectionConfig{
+		Method:     *method,
+		Dimension:  *dimension,
+		Path:       *projectionPath,
+		SampleSize: *sampleSize,
+		Seed:       *seed,
+	}
+
+	// All files go through the same projection, so they stay aligned
+	projection, err := sm.LoadOrFitProjection(config, paths, stdLogger{})
+	if err != nil {
+		log.Fatalf("Failed to set up projection: %v", err)
+	}
+
+	if err := os.MkdirAll(*outputDir, 0o755); err != nil {
+		log.Fatalf("Failed to create output directory: %v", err)
+	}
+
+	loader := sm.NewEmbeddingLoader(stdLogger{})
+	loader.SetProjection(projection)
+
+	for _, path := range paths {
+		if err := reduceFile(loader, path, filepath.Join(*outputDir, filepath.Base(path))); err != nil {
+			log.Fatalf("Failed to reduce %s: %v", path, err)
+		}
+	}
+}
+
+// reduceFile loads path through the loader's projection and writes the result to output
+func reduceFile(loader sm.EmbeddingLoader, path, output string) error {
+	model, err := loader.LoadFromFile(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(output) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	written, err := model.WriteVec(file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s -> %s: %d words, %d dimensions, %.1f MB\n",
+		path, output, model.VocabularySize(), model.Dimension(), float64(written)/(1024*1024))
+	return nil
+}
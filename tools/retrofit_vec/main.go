@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	sm "github.com/kydenul/semantic-matcher"
+)
+
+// stdLogger adapts the standard logger to sm.Logger
+type stdLogger struct{}
+
+func (stdLogger) Debug(...any)                        {}
+func (stdLogger) Info(args ...any)                    { log.Print(args...) }
+func (stdLogger) Warn(args ...any)                    { log.Print(args...) }
+func (stdLogger) Error(args ...any)                   { log.Print(args...) }
+func (stdLogger) Debugf(string, ...any)               {}
+func (stdLogger) Infof(template string, args ...any)  { log.Printf(template, args...) }
+func (stdLogger) Warnf(template string, args ...any)  { log.Printf(template, args...) }
+func (stdLogger) Errorf(template string, args ...any) { log.Printf(template, args...) }
+
+func main() {
+	inputFiles := flag.String("input", "", "Comma-separated input .vec files, merged like vector_file_paths")
+	lexiconPath := flag.String("lexicon", "", "Lexicon file of \"word neighbor1 neighbor2 ...\" lines")
+	output := flag.String("output", "", "Output .vec file")
+	iterations := flag.Int("iterations", sm.DefaultRetrofitIterations, "Number of retrofitting iterations")
+	alpha := flag.Float64("alpha", sm.DefaultRetrofitAlpha, "Weight of the original vectors")
+	lexiconOnly := flag.Bool("lexicon-only", false, "Write only the retrofitted words, for use as a vector layer")
+	flag.Parse()
+
+	if *inputFiles == "" || *lexiconPath == "" || *output == "" {
+		log.Fatal("Usage: retrofit_vec -input <a.vec,b.vec> -lexicon <file> -output <file> " +
+			"[-iterations 10] [-alpha 1] [-lexicon-only]")
+	}
+
+	lexicon, err := sm.LoadLexicon(*lexiconPath)
+	if err != nil {
+		log.Fatalf("Failed to load lexicon: %v", err)
+	}
+
+	model, err := sm.NewEmbeddingLoader(stdLogger{}).LoadMultipleFiles(strings.Split(*inputFiles, ","))
+	if err != nil {
+		log.Fatalf("Failed to load vectors: %v", err)
+	}
+
+	retrofitted, err := sm.Retrofit(model, lexicon, sm.RetrofitOptions{
+		Iterations:  *iterations,
+		Alpha:       *alpha,
+		LexiconOnly: *lexiconOnly,
+	})
+	if err != nil {
+		log.Fatalf("Failed to retrofit vectors: %v", err)
+	}
+
+	file, err := os.Create(*output) //nolint:gosec
+	if err != nil {
+		log.Fatalf("Failed to create output: %v", err)
+	}
+
+	written, err := retrofitted.WriteVec(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("Failed to write %s: %v", *output, err)
+	}
+
+	fmt.Printf("%s: %d words, %d dimensions, %.1f MB\n",
+		*output, retrofitted.VocabularySize(), retrofitted.Dimension(), float64(written)/(1024*1024))
+}
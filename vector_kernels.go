@@ -0,0 +1,90 @@
+package semanticmatcher
+
+import "math"
+
+// Float32 kernels for the hot loops of cosine similarity. They accumulate in float32 with
+// four independent accumulators, so the loops pipeline and vectorize, and use AVX2 with FMA
+// on amd64 where available (build with the purego tag to disable assembly). Results differ
+// from float64 accumulation by a relative error of about 1e-6 for typical dimensions.
+
+// dotFloat32 returns the dot product of a and b; b must be at least as long as a
+func dotFloat32(a, b []float32) float32 {
+	if useAVX2 && len(a) >= avx2BlockSize {
+		blocks := len(a) &^ (avx2BlockSize - 1)
+		sum := dotAVX2(a[:blocks], b[:blocks])
+		for i := blocks; i < len(a); i++ {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+	return dotGeneric(a, b)
+}
+
+// dotAndNormsFloat32 returns a · b, a · a and b · b in a single pass; b must be at least as long as a
+func dotAndNormsFloat32(a, b []float32) (dot, normA, normB float32) {
+	if useAVX2 && len(a) >= avx2BlockSize {
+		blocks := len(a) &^ (avx2BlockSize - 1)
+		dot, normA, normB = dotAndNormsAVX2(a[:blocks], b[:blocks])
+		for i := blocks; i < len(a); i++ {
+			dot += a[i] * b[i]
+			normA += a[i] * a[i]
+			normB += b[i] * b[i]
+		}
+		return dot, normA, normB
+	}
+	return dotAndNormsGeneric(a, b)
+}
+
+// squaredNormFloat32 returns v · v
+func squaredNormFloat32(v []float32) float32 {
+	return dotFloat32(v, v)
+}
+
+// dotGeneric is the portable dot product, unrolled by four
+func dotGeneric(a, b []float32) float32 {
+	b = b[:len(a)] // Bounds check elimination
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+// dotAndNormsGeneric is the portable single-pass dot product and squared norms, unrolled by two
+func dotAndNormsGeneric(a, b []float32) (dot, normA, normB float32) {
+	b = b[:len(a)] // Bounds check elimination
+	var d0, d1, a0, a1, b0, b1 float32
+	i := 0
+	for ; i+2 <= len(a); i += 2 {
+		x0, x1 := a[i], a[i+1]
+		y0, y1 := b[i], b[i+1]
+		d0 += x0 * y0
+		d1 += x1 * y1
+		a0 += x0 * x0
+		a1 += x1 * x1
+		b0 += y0 * y0
+		b1 += y1 * y1
+	}
+	if i < len(a) {
+		x, y := a[i], b[i]
+		d0 += x * y
+		a0 += x * x
+		b0 += y * y
+	}
+	return d0 + d1, a0 + a1, b0 + b1
+}
+
+// cosineFromParts returns dot / (sqrt(normA) * sqrt(normB)) clamped to [-1, 1], 0.0 if a norm is zero
+func cosineFromParts(dot, normA, normB float64) float64 {
+	if normA == 0.0 || normB == 0.0 {
+		return 0.0
+	}
+	return max(-1.0, min(1.0, dot/(math.Sqrt(normA)*math.Sqrt(normB))))
+}
@@ -0,0 +1,21 @@
+//go:build amd64 && !purego
+
+package semanticmatcher
+
+import "golang.org/x/sys/cpu"
+
+// avx2BlockSize is the number of floats the assembly kernels process per step
+const avx2BlockSize = 8
+
+// useAVX2 selects the assembly kernels
+var useAVX2 = cpu.X86.HasAVX2 && cpu.X86.HasFMA
+
+// dotAVX2 returns the dot product of a and b; len(a) must be a multiple of 8 and b as long
+//
+//go:noescape
+func dotAVX2(a, b []float32) float32
+
+// dotAndNormsAVX2 returns a · b, a · a and b · b; len(a) must be a multiple of 8 and b as long
+//
+//go:noescape
+func dotAndNormsAVX2(a, b []float32) (dot, normA, normB float32)
@@ -0,0 +1,16 @@
+//go:build !amd64 || purego
+
+package semanticmatcher
+
+const avx2BlockSize = 8
+
+// useAVX2 is always false without the amd64 assembly kernels
+var useAVX2 = false
+
+func dotAVX2(a, b []float32) float32 {
+	return dotGeneric(a, b)
+}
+
+func dotAndNormsAVX2(a, b []float32) (dot, normA, normB float32) {
+	return dotAndNormsGeneric(a, b)
+}
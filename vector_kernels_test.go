@@ -0,0 +1,121 @@
+package semanticmatcher
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// kernelTolerance is the allowed relative error of the float32 kernels against float64
+const kernelTolerance = 1e-5
+
+func randomVector(rng *rand.Rand, dimension int) []float32 {
+	vector := make([]float32, dimension)
+	for i := range vector {
+		vector[i] = rng.Float32()*2 - 1
+	}
+	return vector
+}
+
+func referenceDot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// referenceCosine is the float64 cosine similarity the kernels must match
+func referenceCosine(a, b []float32) float64 {
+	normA, normB := referenceDot(a, a), referenceDot(b, b)
+	if len(a) == 0 || len(a) != len(b) || normA == 0 || normB == 0 {
+		return 0.0
+	}
+	return referenceDot(a, b) / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func assertClose(t *testing.T, expected, actual float64, scale float64, msgAndArgs ...any) {
+	t.Helper()
+	assert.InDelta(t, expected, actual, kernelTolerance*max(scale, 1), msgAndArgs...)
+}
+
+func TestVectorKernels_MatchReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(42)) //nolint:gosec
+
+	// Lengths around the unroll and block sizes exercise every tail path
+	for _, dimension := range []int{1, 2, 3, 4, 5, 7, 8, 9, 15, 16, 17, 31, 32, 33, 63, 64, 65, 100, 300, 301} {
+		a, b := randomVector(rng, dimension), randomVector(rng, dimension)
+		scale := math.Sqrt(referenceDot(a, a) * referenceDot(b, b))
+
+		assertClose(t, referenceDot(a, b), float64(dotFloat32(a, b)), scale, "dot, dimension %d", dimension)
+		assertClose(t, referenceDot(a, b), float64(dotGeneric(a, b)), scale, "generic dot, dimension %d", dimension)
+		assertClose(t, referenceDot(a, a), float64(squaredNormFloat32(a)), referenceDot(a, a), "norm, dimension %d", dimension)
+
+		for name, kernel := range map[string]func(a, b []float32) (float32, float32, float32){
+			"dispatch": dotAndNormsFloat32,
+			"generic":  dotAndNormsGeneric,
+		} {
+			dot, normA, normB := kernel(a, b)
+			assertClose(t, referenceDot(a, b), float64(dot), scale, "%s dot, dimension %d", name, dimension)
+			assertClose(t, referenceDot(a, a), float64(normA), referenceDot(a, a), "%s norm a, dimension %d", name, dimension)
+			assertClose(t, referenceDot(b, b), float64(normB), referenceDot(b, b), "%s norm b, dimension %d", name, dimension)
+		}
+
+		assertClose(t, referenceCosine(a, b), NewSimilarityCalculator().CosineSimilarity(a, b), 1,
+			"cosine, dimension %d", dimension)
+	}
+}
+
+func TestVectorKernels_AssemblyMatchesGeneric(t *testing.T) {
+	if !useAVX2 {
+		t.Skip("assembly kernels not available")
+	}
+
+	rng := rand.New(rand.NewSource(7)) //nolint:gosec
+	for _, dimension := range []int{8, 16, 24, 32, 40, 296, 1024} {
+		a, b := randomVector(rng, dimension), randomVector(rng, dimension)
+		scale := math.Sqrt(referenceDot(a, a) * referenceDot(b, b))
+
+		assertClose(t, float64(dotGeneric(a, b)), float64(dotAVX2(a, b)), scale, "dimension %d", dimension)
+
+		dot, normA, normB := dotAndNormsAVX2(a, b)
+		genericDot, genericA, genericB := dotAndNormsGeneric(a, b)
+		assertClose(t, float64(genericDot), float64(dot), scale, "dimension %d", dimension)
+		assertClose(t, float64(genericA), float64(normA), float64(genericA), "dimension %d", dimension)
+		assertClose(t, float64(genericB), float64(normB), float64(genericB), "dimension %d", dimension)
+	}
+}
+
+func TestCandidateSet(t *testing.T) {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec
+	query := randomVector(rng, 300)
+	candidates := [][]float32{
+		randomVector(rng, 300),
+		query,
+		make([]float32, 300),  // Zero vector
+		randomVector(rng, 10), // Mismatched dimension
+		nil,
+	}
+
+	set := NewCandidateSet(candidates)
+	assert.Equal(t, len(candidates), set.Len())
+
+	results := set.Similarities(query)
+	assert.Len(t, results, len(candidates))
+	for i, candidate := range candidates {
+		assertClose(t, referenceCosine(query, candidate), results[i], 1, "candidate %d", i)
+	}
+	assert.InDelta(t, 1.0, results[1], kernelTolerance)
+
+	batch := NewSimilarityCalculator().BatchSimilarity(query, candidates)
+	assert.InDeltaSlice(t, batch, results, kernelTolerance)
+
+	assert.Equal(t, 5, set.Add(query))
+	assert.InDelta(t, 1.0, set.Similarities(query)[5], kernelTolerance)
+
+	assert.Empty(t, set.Similarities(nil))
+	assert.Equal(t, make([]float64, set.Len()), set.Similarities(make([]float32, 300)))
+	assert.Empty(t, NewCandidateSet(nil).Similarities(query))
+}
@@ -0,0 +1,113 @@
+package semanticmatcher
+
+import "math"
+
+// AddVectors returns the element-wise sum a + b
+// Returns nil if the vectors are empty or have mismatched dimensions
+func AddVectors(a, b []float32) []float32 {
+	if len(a) == 0 || len(a) != len(b) {
+		return nil
+	}
+
+	result := make([]float32, len(a))
+	for i := range a {
+		result[i] = a[i] + b[i]
+	}
+	return result
+}
+
+// SubtractVectors returns the element-wise difference a - b
+// Returns nil if the vectors are empty or have mismatched dimensions
+func SubtractVectors(a, b []float32) []float32 {
+	if len(a) == 0 || len(a) != len(b) {
+		return nil
+	}
+
+	result := make([]float32, len(a))
+	for i := range a {
+		result[i] = a[i] - b[i]
+	}
+	return result
+}
+
+// ScaleVector returns a copy of v with every component multiplied by factor
+func ScaleVector(v []float32, factor float32) []float32 {
+	if len(v) == 0 {
+		return nil
+	}
+
+	result := make([]float32, len(v))
+	for i, val := range v {
+		result[i] = val * factor
+	}
+	return result
+}
+
+// NormalizeVector returns a unit-length copy of v
+// A zero vector is returned unchanged (as a copy) since it has no direction
+func NormalizeVector(v []float32) []float32 {
+	if len(v) == 0 {
+		return nil
+	}
+
+	result := make([]float32, len(v))
+	copy(result, v)
+
+	norm := VectorNorm(v)
+	if norm == 0.0 {
+		return result
+	}
+
+	for i := range result {
+		result[i] = float32(float64(result[i]) / norm)
+	}
+	return result
+}
+
+// VectorNorm returns the Euclidean (L2) norm of v
+func VectorNorm(v []float32) float64 {
+	var sum float64
+	for _, val := range v {
+		sum += float64(val) * float64(val)
+	}
+	return math.Sqrt(sum)
+}
+
+// DotProduct returns the dot product of a and b
+// Returns 0.0 if the vectors are empty or have mismatched dimensions
+func DotProduct(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0.0
+	}
+
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+// MeanVector returns the element-wise mean of the given vectors
+// Returns nil if no vectors are given or if their dimensions differ
+func MeanVector(vectors [][]float32) []float32 {
+	if len(vectors) == 0 || len(vectors[0]) == 0 {
+		return nil
+	}
+
+	dimension := len(vectors[0])
+	sum := make([]float64, dimension)
+	for _, v := range vectors {
+		if len(v) != dimension {
+			return nil
+		}
+		for i, val := range v {
+			sum[i] += float64(val)
+		}
+	}
+
+	result := make([]float32, dimension)
+	for i := range sum {
+		result[i] = float32(sum[i] / float64(len(vectors)))
+	}
+	return result
+}
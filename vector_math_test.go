@@ -0,0 +1,56 @@
+package semanticmatcher
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVectorMath_AddSubtractScale(t *testing.T) {
+	a := []float32{1.0, 2.0, 3.0}
+	b := []float32{4.0, 5.0, 6.0}
+
+	assert.Equal(t, []float32{5.0, 7.0, 9.0}, AddVectors(a, b))
+	assert.Equal(t, []float32{-3.0, -3.0, -3.0}, SubtractVectors(a, b))
+	assert.Equal(t, []float32{2.0, 4.0, 6.0}, ScaleVector(a, 2.0))
+
+	// Inputs must not be modified
+	assert.Equal(t, []float32{1.0, 2.0, 3.0}, a)
+
+	// Invalid inputs
+	assert.Nil(t, AddVectors(a, []float32{1.0}))
+	assert.Nil(t, SubtractVectors(nil, nil))
+	assert.Nil(t, ScaleVector(nil, 2.0))
+}
+
+func TestVectorMath_NormalizeVector(t *testing.T) {
+	normalized := NormalizeVector([]float32{3.0, 4.0})
+	assert.InDelta(t, 0.6, normalized[0], 1e-6)
+	assert.InDelta(t, 0.8, normalized[1], 1e-6)
+	assert.InDelta(t, 1.0, VectorNorm(normalized), 1e-6)
+
+	// Zero vectors have no direction and are returned unchanged
+	assert.Equal(t, []float32{0.0, 0.0}, NormalizeVector([]float32{0.0, 0.0}))
+	assert.Nil(t, NormalizeVector(nil))
+}
+
+func TestVectorMath_DotProduct(t *testing.T) {
+	assert.InDelta(t, 32.0, DotProduct([]float32{1, 2, 3}, []float32{4, 5, 6}), 1e-9)
+	assert.Equal(t, 0.0, DotProduct([]float32{1, 2}, []float32{1, 2, 3}))
+	assert.Equal(t, 0.0, DotProduct(nil, nil))
+}
+
+func TestVectorMath_MeanVector(t *testing.T) {
+	mean := MeanVector([][]float32{{1, 2}, {3, 4}, {5, 6}})
+	assert.Equal(t, []float32{3, 4}, mean)
+
+	assert.Nil(t, MeanVector(nil))
+	assert.Nil(t, MeanVector([][]float32{{1, 2}, {3}}))
+}
+
+func TestVectorMath_VectorNorm(t *testing.T) {
+	assert.InDelta(t, 5.0, VectorNorm([]float32{3, 4}), 1e-9)
+	assert.InDelta(t, math.Sqrt(3), VectorNorm([]float32{1, 1, 1}), 1e-9)
+	assert.Equal(t, 0.0, VectorNorm(nil))
+}
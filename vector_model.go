@@ -1,6 +1,8 @@
 package semanticmatcher
 
 import (
+	"math"
+	"slices"
 	"sync"
 	"unsafe"
 )
@@ -13,15 +15,13 @@ type vectorModel struct {
 	stringIntern map[string]string    // String interning for memory optimization
 	memoryUsage  int64                // Cached memory usage in bytes
 
-	// Statistics tracking
-	totalLookups int64 // Total number of vector lookups
-	oovLookups   int64 // Number of OOV (out-of-vocabulary) lookups
-	hitLookups   int64 // Number of successful lookups
+	lookupState // Lookup statistics, OOV tracking and fallback chains, guarded by mtx
 
-	// Fallback statistics
-	fallbackAttempts  int64 // Number of character-level fallback attempts
-	fallbackSuccesses int64 // Number of successful fallback operations
-	fallbackFailures  int64 // Number of failed fallback operations
+	normalizer *Normalizer // Unicode normalization of keys and lookups; nil disables it
+
+	// Frequency ranks from the vector files, which list words by descending corpus frequency
+	ranks   map[string]int32 // 1-based position of each word in the file it was loaded from
+	maxRank int              // Largest rank recorded, used as the vocabulary size for Zipf estimates
 }
 
 // NewVectorModel creates a new VectorModel instance
@@ -30,35 +30,35 @@ func NewVectorModel(dimension int) VectorModel {
 		vectors:      make(map[string][]float32),
 		dimension:    dimension,
 		stringIntern: make(map[string]string),
+		ranks:        make(map[string]int32),
 		memoryUsage:  0,
+		lookupState:  newLookupState(),
 	}
 }
 
 // GetVector retrieves vector for a single word
 // Returns the vector and a boolean indicating if the word was found
-// If the word is not found (OOV), attempts character-level fallback
+// If the word is not found (OOV), attempts the fallback chain for the word's language
 func (vm *vectorModel) GetVector(word string) ([]float32, bool) {
-	vm.mtx.Lock()
-	defer vm.mtx.Unlock()
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
 
-	vm.totalLookups++
+	word = vm.normalizer.Normalize(word)
 
 	// First, try direct lookup from vocabulary
 	vector, exists := vm.vectors[word]
 	if exists {
-		vm.hitLookups++
+		vm.recordHit()
 		// Return a copy to prevent external modification
 		result := make([]float32, len(vector))
 		copy(result, vector)
 		return result, true
 	}
 
-	// Word not found - mark as OOV
-	vm.oovLookups++
-
-	// Attempt character-level fallback for OOV words
-	vm.fallbackAttempts++
-	fallbackVector, success := vm.characterLevelFallback(word)
+	// Word not found - mark as OOV and attempt fallback
+	vm.recordMiss()
+	fallbackVector, success := vm.fallback(word)
+	vm.recordOOV(word, success)
 	if success {
 		// Return a copy to prevent external modification
 		result := make([]float32, len(fallbackVector))
@@ -72,69 +72,90 @@ func (vm *vectorModel) GetVector(word string) ([]float32, bool) {
 
 // GetAverageVector computes mean pooling for multiple words
 // Returns the averaged vector and a boolean indicating if any words were found
-// For OOV words, automatically attempts character-level fallback
+// For OOV words, automatically attempts the fallback chain
 func (vm *vectorModel) GetAverageVector(words []string) ([]float32, bool) {
+	return vm.GetPooledVector(words, MeanPooling{})
+}
+
+// GetPooledVector combines the vectors of multiple words with the given pooling strategy
+// OOV words go through the fallback chain and are skipped if it fails
+// Returns false if no word has a vector
+func (vm *vectorModel) GetPooledVector(words []string, pooling Pooling) ([]float32, bool) {
+	return vm.GetWeightedPooledVector(words, pooling, nil)
+}
+
+// GetWeightedPooledVector is GetPooledVector with each word's vector scaled by its weight
+// A nil weighting weighs all words the same.
+func (vm *vectorModel) GetWeightedPooledVector(
+	words []string,
+	pooling Pooling,
+	weighting TokenWeighting,
+) ([]float32, bool) {
+	vector, _, ok := vm.pooledVector(words, pooling, weighting)
+	return vector, ok
+}
+
+// pooledVector is GetWeightedPooledVector that also returns the number of words without a vector
+func (vm *vectorModel) pooledVector(
+	words []string,
+	pooling Pooling,
+	weighting TokenWeighting,
+) ([]float32, int, bool) {
 	if len(words) == 0 {
-		return nil, false
+		return nil, 0, false
 	}
 
-	vm.mtx.Lock()
-	defer vm.mtx.Unlock()
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
 
-	var sum []float32
-	validWords := 0
+	vectors := vm.collectVectors(words, weighting)
+
+	// Return false if no valid words were found (all OOV and all fallbacks failed)
+	if len(vectors) == 0 {
+		return nil, len(words), false
+	}
+
+	return pooling.Pool(vectors), len(words) - len(vectors), true
+}
+
+// collectVectors looks up each word, using the fallback chain for OOV words, and returns
+// the vectors found in word order, scaled by weighting if it is not nil. Unscaled
+// vocabulary vectors are returned without copying.
+// This method is called with at least the read lock held.
+func (vm *vectorModel) collectVectors(words []string, weighting TokenWeighting) [][]float32 {
+	vectors := make([][]float32, 0, len(words))
 
 	for _, word := range words {
-		vm.totalLookups++
+		word = vm.normalizer.Normalize(word)
 
 		// First, try direct lookup from vocabulary
 		if vector, exists := vm.vectors[word]; exists {
-			vm.hitLookups++
-			if sum == nil {
-				// Initialize sum vector with the dimension
-				sum = make([]float32, vm.dimension)
-			}
-
-			// Add vector components to sum
-			for i, val := range vector {
-				sum[i] += val
-			}
-			validWords++
-		} else {
-			// Word not found - mark as OOV
-			vm.oovLookups++
-
-			// Attempt character-level fallback for OOV words
-			vm.fallbackAttempts++
-			fallbackVector, success := vm.characterLevelFallback(word)
-			if success {
-				if sum == nil {
-					// Initialize sum vector with the dimension
-					sum = make([]float32, vm.dimension)
-				}
-
-				// Add fallback vector components to sum
-				for i, val := range fallbackVector {
-					sum[i] += val
-				}
-				validWords++
-			}
-			// If fallback fails, the word is simply skipped (no vector to add)
+			vm.recordHit()
+			vectors = append(vectors, vm.weightVector(word, vector, weighting))
+			continue
 		}
-	}
 
-	// Return false if no valid words were found (all OOV and all fallbacks failed)
-	if validWords == 0 {
-		return nil, false
+		// Word not found - mark as OOV and attempt fallback
+		vm.recordMiss()
+		fallbackVector, success := vm.fallback(word)
+		vm.recordOOV(word, success)
+		if success {
+			vectors = append(vectors, vm.weightVector(word, fallbackVector, weighting))
+		}
+		// If fallback fails, the word is simply skipped (no vector to add)
 	}
 
-	// Compute mean by dividing by number of valid words
-	result := make([]float32, vm.dimension)
-	for i := range sum {
-		result[i] = sum[i] / float32(validWords)
-	}
+	return vectors
+}
 
-	return result, true
+// weightVector returns vector scaled by the weight of word, or vector itself for a nil weighting
+// This method is called with the lock already held.
+func (vm *vectorModel) weightVector(word string, vector []float32, weighting TokenWeighting) []float32 {
+	if weighting == nil {
+		return vector
+	}
+	weight := weighting.Weight(word, int(vm.ranks[word]), vm.maxRank)
+	return ScaleVector(vector, float32(weight))
 }
 
 // Dimension returns the vector dimension
@@ -162,23 +183,20 @@ func (vm *vectorModel) AddVector(word string, vector []float32) {
 		return // Silently ignore vectors with wrong dimension
 	}
 
-	// Use string interning to reduce memory usage for duplicate strings
-	internedWord := vm.internString(word)
-
-	// Store a copy to prevent external modification
-	vectorCopy := make([]float32, len(vector))
-	copy(vectorCopy, vector)
-	vm.vectors[internedWord] = vectorCopy
-
-	// Update memory usage estimate
-	vm.updateMemoryUsage(internedWord, vectorCopy)
+	vm.storeVector(word, vector)
 }
 
 // AddVectorsBatch adds multiple word-vector pairs in a single lock operation
 // This is much more efficient than calling AddVector repeatedly
 // Returns the number of vectors successfully added
 func (vm *vectorModel) AddVectorsBatch(words []string, vectors [][]float32) int {
-	if len(words) != len(vectors) {
+	return vm.addRankedVectorsBatch(words, vectors, nil)
+}
+
+// addRankedVectorsBatch is AddVectorsBatch that also records each word's frequency rank
+// (its 1-based position in the vector file); ranks may be nil
+func (vm *vectorModel) addRankedVectorsBatch(words []string, vectors [][]float32, ranks []int) int {
+	if len(words) != len(vectors) || (ranks != nil && len(ranks) != len(words)) {
 		return 0
 	}
 
@@ -192,22 +210,120 @@ func (vm *vectorModel) AddVectorsBatch(words []string, vectors [][]float32) int
 			continue // Skip vectors with wrong dimension
 		}
 
-		// Use string interning to reduce memory usage
-		internedWord := vm.internString(words[i])
-
-		// Store a copy to prevent external modification
-		vectorCopy := make([]float32, len(vectors[i]))
-		copy(vectorCopy, vectors[i])
-		vm.vectors[internedWord] = vectorCopy
-
-		// Update memory usage estimate
-		vm.updateMemoryUsage(internedWord, vectorCopy)
+		key, stored := vm.storeVector(words[i], vectors[i])
+		if !stored {
+			continue
+		}
 		addedCount++
+
+		if ranks != nil {
+			vm.setRank(key, ranks[i])
+		}
 	}
 
 	return addedCount
 }
 
+// storeVector stores a copy of vector under the normalized form of word
+// A word whose normalized form differs from the word itself does not replace an existing
+// entry, so exact forms and earlier (usually more frequent) variants are kept.
+// Returns the key and false if the vector was not stored.
+// This method is called with the lock already held.
+func (vm *vectorModel) storeVector(word string, vector []float32) (string, bool) {
+	key := vm.normalizer.Normalize(word)
+	if key != word {
+		if _, exists := vm.vectors[key]; exists {
+			return key, false
+		}
+	}
+
+	// Fallback results may depend on any vocabulary word
+	vm.clearFallbackCache()
+
+	// Use string interning to reduce memory usage for duplicate strings
+	internedWord := vm.internString(key)
+
+	// Store a copy to prevent external modification
+	vectorCopy := make([]float32, len(vector))
+	copy(vectorCopy, vector)
+	vm.vectors[internedWord] = vectorCopy
+
+	// Update memory usage estimate
+	vm.updateMemoryUsage(internedWord, vectorCopy)
+	return internedWord, true
+}
+
+// setRank records the frequency rank of a stored key; a later file's rank replaces an earlier one
+// This method is called with the lock already held.
+func (vm *vectorModel) setRank(key string, rank int) {
+	if rank <= 0 {
+		return
+	}
+	if _, exists := vm.ranks[key]; !exists {
+		// Map entry: string header (the key data is shared with the vocabulary) + int32 + overhead
+		vm.memoryUsage += int64(unsafe.Sizeof(key)) + 4 + 48
+	}
+	vm.ranks[key] = int32(min(rank, math.MaxInt32)) //nolint:gosec // clamped to int32 range
+	vm.maxRank = max(vm.maxRank, rank)
+}
+
+// WordRank returns the 1-based frequency rank of word in the vector file it was loaded from
+// Returns false for words added without a rank, e.g. through AddVector
+func (vm *vectorModel) WordRank(word string) (int, bool) {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+
+	rank, exists := vm.ranks[vm.normalizer.Normalize(word)]
+	return int(rank), exists
+}
+
+// SetNormalizer sets the Unicode normalization applied to vocabulary keys and lookups
+// Existing keys are re-normalized; when several keys collide, keys already in normalized
+// form win, then the lexicographically smallest key. A nil normalizer disables normalization.
+func (vm *vectorModel) SetNormalizer(normalizer *Normalizer) {
+	vm.mtx.Lock()
+	defer vm.mtx.Unlock()
+
+	vm.normalizer = normalizer
+	vm.clearFallbackCache()
+	if normalizer == nil || len(vm.vectors) == 0 {
+		return
+	}
+
+	words := make([]string, 0, len(vm.vectors))
+	for word := range vm.vectors {
+		words = append(words, word)
+	}
+	slices.Sort(words)
+
+	previous := vm.vectors
+	previousRanks := vm.ranks
+	vm.vectors = make(map[string][]float32, len(previous))
+	vm.stringIntern = make(map[string]string, len(previous))
+	vm.ranks = make(map[string]int32, len(previousRanks))
+	vm.memoryUsage = 0
+
+	store := func(word string) {
+		key, stored := vm.storeVector(word, previous[word])
+		if rank, ranked := previousRanks[word]; stored && ranked {
+			vm.setRank(key, int(rank))
+		}
+	}
+
+	// Keys that are already normalized first, so they take precedence over variants
+	variants := make([]string, 0)
+	for _, word := range words {
+		if normalizer.Normalize(word) == word {
+			store(word)
+		} else {
+			variants = append(variants, word)
+		}
+	}
+	for _, word := range variants {
+		store(word)
+	}
+}
+
 // PreallocateCapacity preallocates map capacity to reduce rehashing during loading
 // This should be called before loading large vector files
 func (vm *vectorModel) PreallocateCapacity(expectedSize int) {
@@ -218,6 +334,7 @@ func (vm *vectorModel) PreallocateCapacity(expectedSize int) {
 	if len(vm.vectors) < expectedSize {
 		newVectors := make(map[string][]float32, expectedSize)
 		newStringIntern := make(map[string]string, expectedSize)
+		newRanks := make(map[string]int32, expectedSize)
 
 		// Copy existing data
 		for k, v := range vm.vectors {
@@ -226,9 +343,13 @@ func (vm *vectorModel) PreallocateCapacity(expectedSize int) {
 		for k, v := range vm.stringIntern {
 			newStringIntern[k] = v
 		}
+		for k, v := range vm.ranks {
+			newRanks[k] = v
+		}
 
 		vm.vectors = newVectors
 		vm.stringIntern = newStringIntern
+		vm.ranks = newRanks
 	}
 }
 
@@ -272,11 +393,7 @@ func (vm *vectorModel) GetOOVRate() float64 {
 	vm.mtx.RLock()
 	defer vm.mtx.RUnlock()
 
-	if vm.totalLookups == 0 {
-		return 0.0
-	}
-
-	return float64(vm.oovLookups) / float64(vm.totalLookups)
+	return vm.oovRate()
 }
 
 // GetVectorHitRate returns the rate of successful vector lookups
@@ -285,11 +402,7 @@ func (vm *vectorModel) GetVectorHitRate() float64 {
 	vm.mtx.RLock()
 	defer vm.mtx.RUnlock()
 
-	if vm.totalLookups == 0 {
-		return 0.0
-	}
-
-	return float64(vm.hitLookups) / float64(vm.totalLookups)
+	return vm.hitRate()
 }
 
 // GetLookupStats returns detailed lookup statistics
@@ -297,7 +410,7 @@ func (vm *vectorModel) GetLookupStats() (totalLookups, oovLookups, hitLookups, f
 	vm.mtx.RLock()
 	defer vm.mtx.RUnlock()
 
-	return vm.totalLookups, vm.oovLookups, vm.hitLookups, vm.fallbackAttempts, vm.fallbackSuccesses, vm.fallbackFailures
+	return vm.lookupStats()
 }
 
 // GetFallbackSuccessRate returns the success rate of character-level fallback operations
@@ -306,11 +419,7 @@ func (vm *vectorModel) GetFallbackSuccessRate() float64 {
 	vm.mtx.RLock()
 	defer vm.mtx.RUnlock()
 
-	if vm.fallbackAttempts == 0 {
-		return 0.0
-	}
-
-	return float64(vm.fallbackSuccesses) / float64(vm.fallbackAttempts)
+	return vm.fallbackSuccessRate()
 }
 
 // ResetStats resets all statistics counters
@@ -318,58 +427,122 @@ func (vm *vectorModel) ResetStats() {
 	vm.mtx.Lock()
 	defer vm.mtx.Unlock()
 
-	vm.totalLookups = 0
-	vm.oovLookups = 0
-	vm.hitLookups = 0
-	vm.fallbackAttempts = 0
-	vm.fallbackSuccesses = 0
-	vm.fallbackFailures = 0
+	vm.resetStats()
 }
 
-// characterLevelFallback attempts to generate a vector for an OOV word by splitting it into characters
-// and averaging the vectors of characters that exist in the vocabulary.
+// TopOOVWords returns the n most frequent OOV words with their lookup counts
+// Counts are approximate once more distinct OOV words than the tracker capacity are seen
+// If n <= 0, all tracked words are returned
+func (vm *vectorModel) TopOOVWords(n int) []OOVWordStat {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+
+	return vm.topOOVWords(n)
+}
+
+// SetOOVTrackerCapacity changes the number of distinct OOV words tracked
+// This resets the words tracked so far
+func (vm *vectorModel) SetOOVTrackerCapacity(capacity int) {
+	vm.mtx.Lock()
+	defer vm.mtx.Unlock()
+
+	vm.oovTracker = newOOVTracker(capacity)
+}
+
+// SetFallbackCacheSize enables an LRU cache of up to size fallback results, so repeated OOV
+// words run the fallback chain only once; zero disables the cache. The cache is emptied
+// whenever the vocabulary, the normalizer or a fallback chain changes.
+func (vm *vectorModel) SetFallbackCacheSize(size int) {
+	vm.mtx.Lock()
+	defer vm.mtx.Unlock()
+
+	vm.setFallbackCacheSize(size)
+}
+
+// GetFallbackCacheStats returns the size and hit/miss counters of the fallback cache
+func (vm *vectorModel) GetFallbackCacheStats() FallbackCacheStats {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+
+	return vm.fallbackCacheStats()
+}
+
+// SetFallbackChain sets the ordered fallback strategies used for OOV words of a language
+// ("zh" for words containing Han characters, "en" otherwise). An empty language sets the
+// chain used for languages without their own chain. An empty chain disables fallback.
+func (vm *vectorModel) SetFallbackChain(language string, chain ...FallbackStrategy) {
+	vm.mtx.Lock()
+	defer vm.mtx.Unlock()
+
+	vm.setFallbackChain(language, chain)
+}
+
+// GetFallbackStrategyStats returns attempt and success counters per fallback strategy name
+func (vm *vectorModel) GetFallbackStrategyStats() map[string]FallbackStrategyStats {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+
+	return vm.strategyStats()
+}
+
+// lookupExact returns the stored vector for the normalized word without fallback or statistics
 // This method is called with the lock already held.
-func (vm *vectorModel) characterLevelFallback(word string) ([]float32, bool) {
-	// Convert string to runes for proper Unicode character handling
-	runes := []rune(word)
+func (vm *vectorModel) lookupExact(word string) ([]float32, bool) {
+	vector, exists := vm.vectors[vm.normalizer.Normalize(word)]
+	return vector, exists
+}
 
-	// Single character words should not trigger fallback (already failed in main lookup)
-	if len(runes) <= 1 {
-		vm.fallbackFailures++
-		return nil, false
-	}
+// lookupVector returns the vocabulary vector of a word and its frequency rank (0 if unranked)
+// without fallback, statistics or copying
+func (vm *vectorModel) lookupVector(word string) ([]float32, int, bool) {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
 
-	// Collect character vectors
-	var sum []float32
-	validChars := 0
-
-	for _, r := range runes {
-		char := string(r)
-		if charVec, exists := vm.vectors[char]; exists {
-			if sum == nil {
-				// Initialize sum vector with the correct dimension
-				sum = make([]float32, vm.dimension)
-			}
-			// Add character vector to sum
-			for i, val := range charVec {
-				sum[i] += val
-			}
-			validChars++
-		}
-	}
+	word = vm.normalizer.Normalize(word)
+	vector, exists := vm.vectors[word]
+	return vector, int(vm.ranks[word]), exists
+}
 
-	// If no characters have vectors, fallback fails
-	if validChars == 0 {
-		vm.fallbackFailures++
-		return nil, false
-	}
+// keyNormalizer returns the normalizer of vocabulary keys and lookups
+func (vm *vectorModel) keyNormalizer() *Normalizer {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+	return vm.normalizer
+}
+
+// rankedWords returns the largest frequency rank recorded
+func (vm *vectorModel) rankedWords() int {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+	return vm.maxRank
+}
 
-	// Compute average by dividing sum by number of valid characters
-	result := make([]float32, vm.dimension)
-	for i := range sum {
-		result[i] = sum[i] / float32(validChars)
+// rangeVectors calls yield for every vocabulary word and its stored vector until yield
+// returns false. The model is read-locked meanwhile, so yield must not modify it.
+func (vm *vectorModel) rangeVectors(yield func(word string, vector []float32) bool) {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+	vm.rangeVectorsLocked(yield)
+}
+
+// rangeVectorsLocked is rangeVectors for callers that already hold the lock
+func (vm *vectorModel) rangeVectorsLocked(yield func(word string, vector []float32) bool) {
+	for word, vector := range vm.vectors {
+		if !yield(word, vector) {
+			return
+		}
 	}
+}
+
+// fallback runs the fallback chain configured for the word's language
+// This method is called with at least the read lock held.
+func (vm *vectorModel) fallback(word string) ([]float32, bool) {
+	return vm.fallbackFor(word, vm.dimension, vm.lookupExact)
+}
 
-	vm.fallbackSuccesses++
-	return result, true
+// characterLevelFallback attempts to generate a vector for an OOV word by splitting it into characters
+// and averaging the vectors of characters that exist in the vocabulary.
+// This method is called with the lock already held.
+func (vm *vectorModel) characterLevelFallback(word string) ([]float32, bool) {
+	return vm.runChain(word, []FallbackStrategy{CharacterFallback{}}, vm.dimension, vm.lookupExact)
 }
@@ -0,0 +1,127 @@
+package semanticmatcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Words returns an iterator over the vocabulary in file order (see WriteVec)
+// The vocabulary is snapshotted when iteration starts; words removed later are skipped.
+func (vm *vectorModel) Words() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for _, word := range vm.orderedWords() {
+			if !yield(word) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over word-vector pairs in file order (see WriteVec)
+// Vectors are copies. The model is not locked while the caller handles a pair, so the loop
+// body may call other model methods.
+func (vm *vectorModel) All() iter.Seq2[string, []float32] {
+	return func(yield func(string, []float32) bool) {
+		for _, word := range vm.orderedWords() {
+			vm.mtx.RLock()
+			vector, exists := vm.vectors[word]
+			vm.mtx.RUnlock()
+			if !exists {
+				continue
+			}
+			if !yield(word, copyVector(vector)) {
+				return
+			}
+		}
+	}
+}
+
+// WriteVec writes the model in fastText .vec text format: a "count dimension" header and one
+// "word v1 ... vN" row per word. Words with a frequency rank come first in rank order, so
+// loading the file again keeps the ranks; the rest follow in lexicographic order. Values are
+// written in the shortest form that parses back to the same float32.
+// Returns the number of bytes written.
+func (vm *vectorModel) WriteVec(w io.Writer) (int64, error) {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+
+	words := vm.orderedWordsLocked()
+	return writeVec(w, vm.dimension, words, func(word string) []float32 { return vm.vectors[word] })
+}
+
+// writeVec writes words and their vectors in .vec text format
+// Nothing is written if a word cannot be a .vec row.
+func writeVec(w io.Writer, dimension int, words []string, vectorOf func(word string) []float32) (int64, error) {
+	for _, word := range words {
+		if word == "" || strings.ContainsFunc(word, unicode.IsSpace) {
+			return 0, fmt.Errorf("%w: word %q cannot be written as a .vec row", ErrInvalidVectorFormat, word)
+		}
+	}
+
+	writer := bufio.NewWriter(w)
+	var written int64
+
+	n, err := fmt.Fprintf(writer, "%d %d\n", len(words), dimension)
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	row := make([]byte, 0, 64)
+	for _, word := range words {
+		row = append(row[:0], word...)
+		for _, val := range vectorOf(word) {
+			row = append(row, ' ')
+			row = strconv.AppendFloat(row, float64(val), 'g', -1, 32)
+		}
+		row = append(row, '\n')
+
+		n, err := writer.Write(row)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, writer.Flush()
+}
+
+// orderedWords returns a snapshot of the vocabulary in file order
+func (vm *vectorModel) orderedWords() []string {
+	vm.mtx.RLock()
+	defer vm.mtx.RUnlock()
+	return vm.orderedWordsLocked()
+}
+
+// orderedWordsLocked returns the vocabulary sorted by frequency rank, then unranked words
+// in lexicographic order
+// This method is called with the lock already held.
+func (vm *vectorModel) orderedWordsLocked() []string {
+	words := make([]string, 0, len(vm.vectors))
+	for word := range vm.vectors {
+		words = append(words, word)
+	}
+
+	slices.SortFunc(words, func(a, b string) int {
+		rankA, rankedA := vm.ranks[a]
+		rankB, rankedB := vm.ranks[b]
+		switch {
+		case rankedA && rankedB && rankA != rankB:
+			return int(rankA - rankB)
+		case rankedA != rankedB:
+			if rankedA {
+				return -1
+			}
+			return 1
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+	return words
+}
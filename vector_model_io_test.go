@@ -0,0 +1,122 @@
+package semanticmatcher
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorModel_WriteVecRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	loaded := loadSIFTestModel(t)
+
+	// Add unranked words with values that need full float32 precision
+	loaded.AddVector("zeta", []float32{rng.Float32(), -rng.Float32() * 1e-20, math.MaxFloat32})
+	loaded.AddVector("alpha", []float32{math.SmallestNonzeroFloat32, float32(math.Pi), -0})
+
+	var buf bytes.Buffer
+	written, err := loaded.WriteVec(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), written)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 8)
+	assert.Equal(t, "7 3", lines[0])
+	assert.Equal(t, "common 1 1 0", lines[1])
+	assert.True(t, strings.HasPrefix(lines[6], "alpha "), "unranked words follow in lexicographic order")
+	assert.True(t, strings.HasPrefix(lines[7], "zeta "))
+
+	reloaded, err := NewEmbeddingLoader(DiscardLogger{}).LoadFromReader(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, loaded.VocabularySize(), reloaded.VocabularySize())
+
+	for word, vector := range loaded.All() {
+		reloadedVector, ok := reloaded.GetVector(word)
+		require.True(t, ok, word)
+		for i := range vector {
+			assert.Equal(t, math.Float32bits(vector[i]), math.Float32bits(reloadedVector[i]), "%s[%d]", word, i)
+		}
+	}
+
+	// Ranks survive the round trip
+	for _, word := range []string{"common", "rain"} {
+		originalRank, _ := loaded.WordRank(word)
+		reloadedRank, ok := reloaded.WordRank(word)
+		require.True(t, ok)
+		assert.Equal(t, originalRank, reloadedRank, word)
+	}
+}
+
+func TestVectorModel_WriteVecRandomVectors(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	model := NewVectorModel(16).(*vectorModel)
+	for i := range 200 {
+		vector := make([]float32, 16)
+		for j := range vector {
+			vector[j] = float32(rng.NormFloat64() * math.Pow(10, float64(rng.Intn(20)-10)))
+		}
+		model.AddVector("w"+strings.Repeat("x", i%5)+string(rune('a'+i%26))+string(rune('0'+i/26)), vector)
+	}
+
+	var buf bytes.Buffer
+	_, err := model.WriteVec(&buf)
+	require.NoError(t, err)
+	reloaded, err := NewEmbeddingLoader(DiscardLogger{}).LoadFromReader(&buf)
+	require.NoError(t, err)
+
+	for word, vector := range model.All() {
+		reloadedVector, ok := reloaded.GetVector(word)
+		require.True(t, ok, word)
+		assert.Equal(t, vector, reloadedVector, word)
+	}
+}
+
+func TestVectorModel_WriteVecInvalidWord(t *testing.T) {
+	model := NewVectorModel(2).(*vectorModel)
+	model.AddVector("two words", []float32{1, 0})
+
+	var buf bytes.Buffer
+	_, err := model.WriteVec(&buf)
+	assert.ErrorIs(t, err, ErrInvalidVectorFormat)
+	assert.Zero(t, buf.Len())
+}
+
+func TestVectorModel_WordsAndAll(t *testing.T) {
+	model := loadSIFTestModel(t)
+	model.AddVector("apple", []float32{1, 2, 3})
+
+	words := slices.Collect(model.Words())
+	assert.Equal(t, []string{"common", "market", "stock", "weather", "rain", "apple"}, words)
+
+	// Vectors are copies
+	for word, vector := range model.All() {
+		vector[0] = 42
+		stored, _ := model.GetVector(word)
+		assert.NotEqual(t, float32(42), stored[0], word)
+	}
+
+	// Early exit and calling back into the model from the loop body
+	count := 0
+	for word := range model.All() {
+		_, ok := model.GetVector(word)
+		assert.True(t, ok)
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 2, count)
+
+	empty := NewVectorModel(3)
+	assert.Empty(t, slices.Collect(empty.Words()))
+	var buf bytes.Buffer
+	_, err := empty.WriteVec(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "0 3\n", buf.String())
+}
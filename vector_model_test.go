@@ -210,6 +210,46 @@ func TestVectorModel_ConcurrentAccess(t *testing.T) {
 	}
 }
 
+func TestVectorModel_ConcurrentLookupStats(t *testing.T) {
+	vm := NewVectorModel(2).(*vectorModel)
+	vm.AddVector("a", []float32{1, 0})
+	vm.AddVector("b", []float32{0, 1})
+	vm.SetFallbackChain("", CharacterFallback{})
+	vm.SetFallbackCacheSize(4)
+
+	var wg sync.WaitGroup
+	numGoroutines, iterations := 8, 200
+
+	// Lookups run under the read lock and count hits, OOV words and fallbacks concurrently
+	for range numGoroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range iterations {
+				vm.GetVector("a")
+				vm.GetAverageVector([]string{"b", "ab", "zz"})
+				vm.TopOOVWords(1)
+				vm.GetFallbackCacheStats()
+			}
+		}()
+	}
+	wg.Wait()
+
+	total, oov, hits, attempts, successes, failures := vm.GetLookupStats()
+	lookups := int64(numGoroutines * iterations)
+	assert.Equal(t, 4*lookups, total)
+	assert.Equal(t, 2*lookups, hits)
+	assert.Equal(t, 2*lookups, oov)
+	assert.Equal(t, oov, attempts)
+	assert.Equal(t, lookups, successes)
+	assert.Equal(t, lookups, failures)
+
+	top := vm.TopOOVWords(0)
+	require.Len(t, top, 2)
+	assert.Equal(t, lookups, top[0].Count)
+	assert.Equal(t, lookups, top[1].Count)
+}
+
 // Benchmark tests for vector operations
 
 func BenchmarkVectorModel_GetVector(b *testing.B) {
@@ -3278,8 +3318,8 @@ func TestUnicodeHandling_MixedChineseEnglish(t *testing.T) {
 	assert.True(t, ok, "Should succeed via character-level fallback")
 	assert.NotNil(t, result, "Result should not be nil")
 
-	// Verify the result is the average of all character vectors
-	expected := []float32{5.5, 6.5, 7.5} // (1+4+7+10)/4, (2+5+8+11)/4, (3+6+9+12)/4
+	// The default chain averages the CJK characters only
+	expected := []float32{8.5, 9.5, 10.5} // (7+10)/2, (8+11)/2, (9+12)/2
 	for i := range expected {
 		assert.InDelta(t, expected[i], result[i], 1e-6, "Vector component %d should match expected average", i)
 	}
@@ -3306,6 +3346,9 @@ func TestUnicodeHandling_SpecialUnicodeCharacters(t *testing.T) {
 	vm.AddVector("А", []float32{13.0, 14.0, 15.0})
 	vm.AddVector("Б", []float32{16.0, 17.0, 18.0})
 
+	// The default chain averages CJK words only; average characters of any script
+	vm.SetFallbackChain("", CharacterFallback{})
+
 	testCases := []struct {
 		name     string
 		word     string
@@ -3373,8 +3416,8 @@ func TestUnicodeHandling_ComplexMixedScript(t *testing.T) {
 	assert.True(t, ok, "Should succeed via character-level fallback")
 	assert.NotNil(t, result, "Result should not be nil")
 
-	// Verify the result is the average of all character vectors
-	expected := []float32{7.0, 8.0, 9.0} // (1+4+7+10+13)/5, (2+5+8+11+14)/5, (3+6+9+12+15)/5
+	// The default chain averages the CJK characters only: Chinese and Japanese
+	expected := []float32{4.0, 5.0, 6.0} // (1+7)/2, (2+8)/2, (3+9)/2
 	for i := range expected {
 		assert.InDelta(t, expected[i], result[i], 1e-6, "Vector component %d should match expected average", i)
 	}
@@ -3399,6 +3442,9 @@ func TestUnicodeHandling_SurrogatePairs(t *testing.T) {
 	vm.AddVector("🀀", []float32{7.0, 8.0, 9.0})    // Mahjong Tile East Wind (U+1F000)
 	vm.AddVector("🀁", []float32{10.0, 11.0, 12.0}) // Mahjong Tile South Wind (U+1F001)
 
+	// The default chain averages CJK words only; average characters of any script
+	vm.SetFallbackChain("", CharacterFallback{})
+
 	// Reset stats
 	vm.ResetStats()
 
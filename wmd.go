@@ -0,0 +1,386 @@
+package semanticmatcher
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"slices"
+)
+
+// WMDMetric is the Word Mover's Distance (Kusner et al., "From Word Embeddings To Document
+// Distances") between the words of two texts, converted to the similarity 1 - d/2 in [0, 1]
+// Each text is a bag of unit word vectors with equal weight per token; d is the minimum total
+// Euclidean distance needed to move the words of one text onto the words of the other.
+// Unlike mean pooling, every word must be matched by similar words of the other text.
+type WMDMetric struct{}
+
+// Name returns the metric name
+func (WMDMetric) Name() string { return MetricWMD }
+
+// Range returns [0, 1]
+func (WMDMetric) Range() (float64, float64) { return 0, 1 }
+
+// WordVectorSimilarity returns 1 - WMD/2, 0.0 if either text has no non-zero word vector
+func (WMDMetric) WordVectorSimilarity(words1, words2 [][]float32) float64 {
+	doc1, doc2 := newWordDocument(words1), newWordDocument(words2)
+	if doc1.empty() || doc2.empty() {
+		return 0.0
+	}
+	return distanceToSimilarity(doc1.wmd(doc2))
+}
+
+// RelaxedWMDMetric is the relaxed Word Mover's Distance, where each word moves to its nearest
+// word in the other text, converted to the similarity 1 - d/2 in [0, 1]
+// It is a lower bound of WMD, so its similarity is at least that of WMDMetric, and costs one
+// distance per word pair instead of solving a transport problem.
+type RelaxedWMDMetric struct{}
+
+// Name returns the metric name
+func (RelaxedWMDMetric) Name() string { return MetricRWMD }
+
+// Range returns [0, 1]
+func (RelaxedWMDMetric) Range() (float64, float64) { return 0, 1 }
+
+// WordVectorSimilarity returns 1 - RWMD/2, 0.0 if either text has no non-zero word vector
+func (RelaxedWMDMetric) WordVectorSimilarity(words1, words2 [][]float32) float64 {
+	doc1, doc2 := newWordDocument(words1), newWordDocument(words2)
+	if doc1.empty() || doc2.empty() {
+		return 0.0
+	}
+	return distanceToSimilarity(doc1.relaxedWMD(doc2.distances(doc1)))
+}
+
+// distanceToSimilarity maps a distance between unit vectors, in [0, 2], to [0, 1]
+func distanceToSimilarity(distance float64) float64 {
+	return math.Min(math.Max(1-distance/2, 0), 1)
+}
+
+// wordDocument is a text as a bag of unit word vectors, one per token with equal weights
+type wordDocument struct {
+	vectors  [][]float32
+	centroid []float32 // Mean of vectors
+}
+
+// newWordDocument normalizes the word vectors, skipping zero vectors
+func newWordDocument(words [][]float32) wordDocument {
+	doc := wordDocument{vectors: make([][]float32, 0, len(words))}
+	for _, word := range words {
+		norm := math.Sqrt(float64(squaredNormFloat32(word)))
+		if norm == 0 {
+			continue
+		}
+		unit := make([]float32, len(word))
+		for i, val := range word {
+			unit[i] = float32(float64(val) / norm)
+		}
+		doc.vectors = append(doc.vectors, unit)
+	}
+	if len(doc.vectors) > 0 {
+		doc.centroid = MeanVector(doc.vectors)
+	}
+	return doc
+}
+
+// empty reports whether the document has no word vectors
+func (d wordDocument) empty() bool {
+	return len(d.vectors) == 0
+}
+
+// distances returns the Euclidean distance of each word of other (rows) to each word of d
+func (d wordDocument) distances(other wordDocument) [][]float64 {
+	distances := newDenseMatrix(len(other.vectors), len(d.vectors))
+	for i, u := range other.vectors {
+		for j, v := range d.vectors {
+			if len(u) != len(v) {
+				distances[i][j] = 2
+				continue
+			}
+			// ||u - v||² = 2 - 2 u·v for unit vectors
+			distances[i][j] = math.Sqrt(math.Max(0, 2-2*float64(dotFloat32(u, v))))
+		}
+	}
+	return distances
+}
+
+// centroidDistance returns the distance of the word centroids, a lower bound of WMD
+func (d wordDocument) centroidDistance(other wordDocument) float64 {
+	if len(d.centroid) != len(other.centroid) {
+		return 0
+	}
+	var sum float64
+	for i := range d.centroid {
+		diff := float64(d.centroid[i]) - float64(other.centroid[i])
+		sum += diff * diff
+	}
+	return math.Sqrt(sum)
+}
+
+// relaxedWMD returns the relaxed WMD for distances from the words of one document (rows) to
+// the words of the other (columns): the larger of the mean distance of each word to its
+// nearest word in the other document, in either direction
+func (wordDocument) relaxedWMD(distances [][]float64) float64 {
+	if len(distances) == 0 || len(distances[0]) == 0 {
+		return 0
+	}
+
+	nearestColumn := make([]float64, len(distances[0]))
+	for j := range nearestColumn {
+		nearestColumn[j] = math.Inf(1)
+	}
+	var rows float64
+	for _, row := range distances {
+		rows += slices.Min(row)
+		for j, distance := range row {
+			nearestColumn[j] = math.Min(nearestColumn[j], distance)
+		}
+	}
+	var columns float64
+	for _, distance := range nearestColumn {
+		columns += distance
+	}
+	return math.Max(rows/float64(len(distances)), columns/float64(len(nearestColumn)))
+}
+
+// wmd returns the Word Mover's Distance from other to d
+func (d wordDocument) wmd(other wordDocument) float64 {
+	return transportCost(other.distances(d))
+}
+
+// transportCost solves the transport problem that moves equal masses from the rows to equal
+// masses of the columns at the given distances, returning the minimum mean distance moved
+// Masses are scaled to integers and the problem is solved as a minimum-cost flow with
+// successive shortest paths (Dijkstra with potentials) on the dense bipartite graph.
+func transportCost(distances [][]float64) float64 {
+	rows := len(distances)
+	if rows == 0 || len(distances[0]) == 0 {
+		return 0
+	}
+	columns := len(distances[0])
+
+	// Each row holds total/rows units and each column takes total/columns units
+	total := int64(rows / gcd(rows, columns) * columns)
+	supply := make([]int64, rows)
+	demand := make([]int64, columns)
+	for i := range supply {
+		supply[i] = total / int64(rows)
+	}
+	for j := range demand {
+		demand[j] = total / int64(columns)
+	}
+
+	flow := make([][]int64, rows)
+	for i := range flow {
+		flow[i] = make([]int64, columns)
+	}
+
+	// Potentials keep reduced edge costs non-negative; the source has potential 0
+	rowPotential := make([]float64, rows)
+	columnPotential := make([]float64, columns)
+	rowDistance := make([]float64, rows)
+	columnDistance := make([]float64, columns)
+	rowFrom := make([]int, rows)       // Column whose backward edge reached the row, -1 from the source
+	columnFrom := make([]int, columns) // Row whose forward edge reached the column
+	rowDone := make([]bool, rows)
+	columnDone := make([]bool, columns)
+
+	var cost float64
+	for remaining := total; remaining > 0; {
+		for i := range rows {
+			rowDistance[i], rowFrom[i], rowDone[i] = math.Inf(1), -1, false
+			if supply[i] > 0 {
+				rowDistance[i] = 0
+			}
+		}
+		for j := range columns {
+			columnDistance[j], columnFrom[j], columnDone[j] = math.Inf(1), -1, false
+		}
+
+		// Dense Dijkstra over rows and columns
+		for {
+			best, bestIsRow, bestDistance := -1, false, math.Inf(1)
+			for i := range rows {
+				if !rowDone[i] && rowDistance[i] < bestDistance {
+					best, bestIsRow, bestDistance = i, true, rowDistance[i]
+				}
+			}
+			for j := range columns {
+				if !columnDone[j] && columnDistance[j] < bestDistance {
+					best, bestIsRow, bestDistance = j, false, columnDistance[j]
+				}
+			}
+			if best < 0 {
+				break
+			}
+
+			if bestIsRow {
+				rowDone[best] = true
+				for j := range columns {
+					reduced := math.Max(0, distances[best][j]+rowPotential[best]-columnPotential[j])
+					if !columnDone[j] && bestDistance+reduced < columnDistance[j] {
+						columnDistance[j], columnFrom[j] = bestDistance+reduced, best
+					}
+				}
+				continue
+			}
+
+			columnDone[best] = true
+			for i := range rows {
+				if rowDone[i] || flow[i][best] == 0 {
+					continue
+				}
+				reduced := math.Max(0, -distances[i][best]+columnPotential[best]-rowPotential[i])
+				if bestDistance+reduced < rowDistance[i] {
+					rowDistance[i], rowFrom[i] = bestDistance+reduced, best
+				}
+			}
+		}
+
+		// The sink is reached through the column with remaining demand at the least real distance
+		sink, sinkDistance := -1, math.Inf(1)
+		for j := range columns {
+			if demand[j] > 0 && columnDone[j] && columnDistance[j]+columnPotential[j] < sinkDistance {
+				sink, sinkDistance = j, columnDistance[j]+columnPotential[j]
+			}
+		}
+		if sink < 0 {
+			break
+		}
+
+		// Find the bottleneck along the path, then augment
+		amount := demand[sink]
+		for j := sink; ; {
+			i := columnFrom[j]
+			if rowFrom[i] < 0 {
+				amount = min(amount, supply[i])
+				break
+			}
+			j = rowFrom[i]
+			amount = min(amount, flow[i][j])
+		}
+		demand[sink] -= amount
+		for j := sink; ; {
+			i := columnFrom[j]
+			flow[i][j] += amount
+			if rowFrom[i] < 0 {
+				supply[i] -= amount
+				break
+			}
+			j = rowFrom[i]
+			flow[i][j] -= amount
+		}
+		remaining -= amount
+		cost += float64(amount) * sinkDistance
+
+		for i := range rows {
+			if rowDone[i] {
+				rowPotential[i] += rowDistance[i]
+			}
+		}
+		for j := range columns {
+			if columnDone[j] {
+				columnPotential[j] += columnDistance[j]
+			}
+		}
+	}
+
+	return cost / float64(total)
+}
+
+// gcd returns the greatest common divisor of two positive integers
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// topWMDSimilarities returns the WMD similarity of query to each candidate, computing exact
+// WMD only for candidates that can be among the k most similar
+// Candidates are visited by descending word centroid similarity, and skipped (pruned) once
+// their centroid or relaxed WMD similarity, both upper bounds of the WMD similarity, is below
+// the k-th best similarity so far. Pruned candidates are not among the k most similar.
+// Once ctx is done, the remaining candidates are marked pruned and ctx.Err() is returned.
+func topWMDSimilarities(
+	ctx context.Context,
+	query [][]float32,
+	candidates [][][]float32,
+	k int,
+) ([]float64, []bool, error) {
+	scores := make([]float64, len(candidates))
+	pruned := make([]bool, len(candidates))
+
+	queryDoc := newWordDocument(query)
+	if queryDoc.empty() {
+		return scores, pruned, nil
+	}
+
+	docs := make([]wordDocument, len(candidates))
+	bounds := make([]float64, len(candidates))
+	order := make([]int, 0, len(candidates))
+	for idx, candidate := range candidates {
+		docs[idx] = newWordDocument(candidate)
+		if docs[idx].empty() {
+			continue
+		}
+		bounds[idx] = distanceToSimilarity(queryDoc.centroidDistance(docs[idx]))
+		order = append(order, idx)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case bounds[a] > bounds[b]:
+			return -1
+		case bounds[a] < bounds[b]:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	best := &scoreHeap{}
+	for position, idx := range order {
+		if position%keywordBlockSize == 0 && ctx.Err() != nil {
+			for _, remaining := range order[position:] {
+				pruned[remaining] = true
+			}
+			return scores, pruned, ctx.Err()
+		}
+
+		full := k > 0 && best.Len() >= k
+		if full && bounds[idx] < (*best)[0] {
+			pruned[idx] = true
+			continue
+		}
+
+		distances := docs[idx].distances(queryDoc)
+		if full && distanceToSimilarity(queryDoc.relaxedWMD(distances)) < (*best)[0] {
+			pruned[idx] = true
+			continue
+		}
+
+		scores[idx] = distanceToSimilarity(transportCost(distances))
+		if k <= 0 {
+			continue
+		}
+		heap.Push(best, scores[idx])
+		if best.Len() > k {
+			heap.Pop(best)
+		}
+	}
+
+	return scores, pruned, nil
+}
+
+// scoreHeap is a min-heap of the best scores so far
+type scoreHeap []float64
+
+func (h scoreHeap) Len() int           { return len(h) }
+func (h scoreHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h scoreHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *scoreHeap) Push(x any)        { *h = append(*h, x.(float64)) } //nolint:errcheck,forcetypeassert
+
+func (h *scoreHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,194 @@
+package semanticmatcher
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bruteForceAssignment returns the minimum mean distance of a one-to-one assignment of rows
+// to columns, the optimal transport for equal numbers of rows and columns
+func bruteForceAssignment(distances [][]float64) float64 {
+	n := len(distances)
+	used := make([]bool, n)
+	best := math.Inf(1)
+	var search func(row int, cost float64)
+	search = func(row int, cost float64) {
+		if row == n {
+			best = math.Min(best, cost)
+			return
+		}
+		for j := range n {
+			if !used[j] {
+				used[j] = true
+				search(row+1, cost+distances[row][j])
+				used[j] = false
+			}
+		}
+	}
+	search(0, 0)
+	return best / float64(n)
+}
+
+func randomDistances(rng *rand.Rand, rows, columns int) [][]float64 {
+	distances := newDenseMatrix(rows, columns)
+	for i := range distances {
+		for j := range distances[i] {
+			distances[i][j] = rng.Float64() * 2
+		}
+	}
+	return distances
+}
+
+func TestTransportCost_MatchesAssignment(t *testing.T) {
+	rng := rand.New(rand.NewSource(3)) //nolint:gosec
+	for n := 1; n <= 6; n++ {
+		for range 20 {
+			distances := randomDistances(rng, n, n)
+			assert.InDelta(t, bruteForceAssignment(distances), transportCost(distances), 1e-9, "n=%d", n)
+		}
+	}
+}
+
+func TestTransportCost_UnequalSizes(t *testing.T) {
+	// One row splits its mass over two columns
+	assert.InDelta(t, 1.5, transportCost([][]float64{{1, 2}}), 1e-9)
+
+	// Two rows, three columns: each column takes 1/3; rows hold 1/2 each
+	distances := [][]float64{
+		{0, 1, 2},
+		{2, 1, 0},
+	}
+	// Row 0 sends 1/3 to column 0 and 1/6 to column 1, row 1 mirrors it
+	assert.InDelta(t, 1.0/3.0, transportCost(distances), 1e-9)
+
+	// Splitting rows into equal parts gives the same cost as an assignment of the parts
+	rng := rand.New(rand.NewSource(5)) //nolint:gosec
+	for range 20 {
+		distances := randomDistances(rng, 2, 4)
+		doubled := [][]float64{distances[0], distances[0], distances[1], distances[1]}
+		assert.InDelta(t, bruteForceAssignment(doubled), transportCost(distances), 1e-9)
+	}
+}
+
+func TestWMD_Bounds(t *testing.T) {
+	rng := rand.New(rand.NewSource(11)) //nolint:gosec
+	for range 50 {
+		doc1 := newWordDocument([][]float32{randomVector(rng, 20), randomVector(rng, 20), randomVector(rng, 20)})
+		doc2 := newWordDocument([][]float32{randomVector(rng, 20), randomVector(rng, 20)})
+
+		wmd := doc1.wmd(doc2)
+		relaxed := doc1.relaxedWMD(doc2.distances(doc1))
+		centroid := doc1.centroidDistance(doc2)
+		assert.LessOrEqual(t, relaxed, wmd+1e-9)
+		assert.LessOrEqual(t, centroid, wmd+1e-6)
+		assert.InDelta(t, wmd, doc2.wmd(doc1), 1e-9, "WMD is symmetric")
+	}
+}
+
+func TestWMDMetrics(t *testing.T) {
+	apple := []float32{1, 0, 0}
+	phone := []float32{0, 1, 0}
+	fruit := []float32{0, 0, 1}
+
+	for _, metric := range []WordVectorSimilarityMetric{WMDMetric{}, RelaxedWMDMetric{}} {
+		lower, upper := metric.Range()
+		assert.Equal(t, 0.0, lower)
+		assert.Equal(t, 1.0, upper)
+
+		// Word order and vector length do not matter
+		assert.InDelta(t, 1.0, metric.WordVectorSimilarity(
+			[][]float32{apple, phone}, [][]float32{{0, 2, 0}, apple}), 1e-6, metric.Name())
+
+		phoneScore := metric.WordVectorSimilarity([][]float32{apple, phone}, [][]float32{apple, phone, phone})
+		fruitScore := metric.WordVectorSimilarity([][]float32{apple, phone}, [][]float32{apple, fruit})
+		assert.Greater(t, phoneScore, fruitScore, metric.Name())
+
+		assert.Equal(t, 0.0, metric.WordVectorSimilarity(nil, [][]float32{apple}), metric.Name())
+		assert.Equal(t, 0.0, metric.WordVectorSimilarity([][]float32{{0, 0, 0}}, [][]float32{apple}), metric.Name())
+	}
+
+	// Orthogonal unit vectors are √2 apart
+	assert.InDelta(t, 1-math.Sqrt2/2, WMDMetric{}.WordVectorSimilarity([][]float32{apple}, [][]float32{phone}), 1e-6)
+}
+
+func TestTopWMDSimilarities_Pruning(t *testing.T) {
+	rng := rand.New(rand.NewSource(17)) //nolint:gosec
+	query := [][]float32{randomVector(rng, 10), randomVector(rng, 10), randomVector(rng, 10)}
+	candidates := make([][][]float32, 300)
+	for i := range candidates {
+		for range 1 + i%4 {
+			candidates[i] = append(candidates[i], randomVector(rng, 10))
+		}
+	}
+	candidates[7] = nil // No word vectors
+
+	all, none, err := topWMDSimilarities(context.Background(), query, candidates, 0)
+	require.NoError(t, err)
+	for i, candidate := range candidates {
+		assert.False(t, none[i])
+		assert.InDelta(t, WMDMetric{}.WordVectorSimilarity(query, candidate), all[i], 1e-9)
+	}
+
+	const k = 5
+	scores, pruned, err := topWMDSimilarities(context.Background(), query, candidates, k)
+	require.NoError(t, err)
+	kept, prunedCount := make([]float64, 0, len(candidates)), 0
+	for i := range candidates {
+		if pruned[i] {
+			prunedCount++
+			continue
+		}
+		assert.Equal(t, all[i], scores[i])
+		kept = append(kept, scores[i])
+	}
+	assert.Positive(t, prunedCount, "bounds should prune some candidates")
+
+	// The k best scores survive pruning
+	sortedAll := slices.Clone(all)
+	sortDescending(sortedAll)
+	sortDescending(kept)
+	require.GreaterOrEqual(t, len(kept), k)
+	assert.Equal(t, sortedAll[:k], kept[:k])
+}
+
+func sortDescending(values []float64) {
+	slices.Sort(values)
+	slices.Reverse(values)
+}
+
+func TestSemanticMatcher_WMD(t *testing.T) {
+	model := NewVectorModel(3).(*vectorModel) //nolint:errcheck,forcetypeassert
+	model.AddVector("苹果", []float32{1, 0, 0})
+	model.AddVector("apple", []float32{1, 0.1, 0})
+	model.AddVector("手机", []float32{0, 1, 0})
+	model.AddVector("phone", []float32{0.1, 1, 0})
+	model.AddVector("fruit", []float32{0, 0, 1})
+
+	matcher := NewSemanticMatcher(NewTextProcessor(), model, NewSimilarityCalculatorWithMetric(WMDMetric{}))
+	phone := matcher.ComputeSimilarity("苹果 手机", "apple phone")
+	fruit := matcher.ComputeSimilarity("苹果 手机", "apple fruit")
+	assert.Greater(t, phone, fruit)
+	assert.InDelta(t, 1.0, matcher.ComputeSimilarity("苹果 手机", "手机 苹果"), 1e-6)
+
+	matches := matcher.FindTopKeywords("苹果 手机", []string{"apple fruit", "apple phone", "fruit"}, 1)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "apple phone", matches[0].Keyword)
+	assert.InDelta(t, phone, matches[0].Score, 1e-9)
+
+	all := matcher.FindTopKeywords("苹果 手机", []string{"apple fruit", "apple phone", "fruit"}, 0)
+	require.Len(t, all, 3)
+	assert.Equal(t, "fruit", all[2].Keyword)
+
+	relaxed := NewSemanticMatcher(NewTextProcessor(), model, NewSimilarityCalculatorWithMetric(RelaxedWMDMetric{}))
+	assert.GreaterOrEqual(t, relaxed.ComputeSimilarity("苹果 手机", "apple fruit"), fruit-1e-9)
+
+	matrix := matcher.SimilarityMatrix([]string{"苹果 手机"}, []string{"apple phone", "apple fruit"})
+	assert.InDelta(t, phone, matrix.Scores[0][0], 1e-9)
+	assert.InDelta(t, fruit, matrix.Scores[0][1], 1e-9)
+}